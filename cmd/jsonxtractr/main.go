@@ -0,0 +1,180 @@
+// Command jsonxtractr prints the values selected from a JSON document.
+//
+// Usage:
+//
+//	jsonxtractr [-file path] [-json] [-ignore-missing] selector...
+//
+// The document is read from stdin unless -file is given. Each selector's value
+// is printed on a line of its own, in the order the selectors are given, with
+// strings printed as-is and other values as compact JSON. A selector that
+// isn't found prints an empty line. With -json the found values are instead
+// printed as a single JSON object keyed by selector.
+//
+// The exit status is 1 when the document can't be read or parsed, or when any
+// selector isn't found unless -ignore-missing is given, and 2 for invalid
+// arguments. Errors are reported on stderr with their full context.
+package main
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// errUsage marks errors in the command line itself.
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, "jsonxtractr:", err)
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "jsonxtractr:", err)
+		os.Exit(1)
+	}
+}
+
+// run extracts the selectors named in args from the JSON read from stdin, or
+// from the -file flag, and writes their values to stdout. Selectors that
+// aren't found are reported together in the returned error.
+func run(args []string, stdin io.Reader, stdout io.Writer) (err error) {
+	var path string
+	var asJSON, ignoreMissing bool
+	var jsonBytes []byte
+	var doc *jsonxtractr.Document
+	var values []any
+	var found []bool
+	var missing []error
+
+	flags := flag.NewFlagSet("jsonxtractr", flag.ContinueOnError)
+	flags.StringVar(&path, "file", "", "read the JSON document from `path` instead of stdin")
+	flags.BoolVar(&asJSON, "json", false, "print the values as a JSON object keyed by selector")
+	flags.BoolVar(&ignoreMissing, "ignore-missing", false, "exit successfully even when a selector isn't found")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: jsonxtractr [-file path] [-json] [-ignore-missing] selector...")
+		flags.PrintDefaults()
+	}
+
+	err = flags.Parse(args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			err = fmt.Errorf("%w: %w", errUsage, err)
+		}
+		goto end
+	}
+	if flags.NArg() == 0 {
+		err = fmt.Errorf("%w: at least one selector is required", errUsage)
+		goto end
+	}
+
+	if path != "" {
+		jsonBytes, err = os.ReadFile(path)
+	} else {
+		jsonBytes, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		goto end
+	}
+
+	// Indexing the document once validates it and serves every selector
+	doc, err = jsonxtractr.NewDocument(jsonBytes)
+	if err != nil {
+		goto end
+	}
+
+	values = make([]any, flags.NArg())
+	found = make([]bool, flags.NArg())
+	for i, arg := range flags.Args() {
+		var value any
+		value, err = doc.Value(jsonxtractr.Selector(arg))
+		if jsonxtractr.IsAbsent(err) {
+			missing = append(missing, err)
+			continue
+		}
+		if err != nil {
+			goto end
+		}
+		values[i], found[i] = value, true
+	}
+
+	if asJSON {
+		err = writeObject(stdout, flags.Args(), values, found)
+	} else {
+		err = writeLines(stdout, values, found)
+	}
+	if err != nil {
+		goto end
+	}
+
+	if len(missing) > 0 && !ignoreMissing {
+		err = errors.Join(missing...)
+	}
+
+end:
+	return err
+}
+
+// writeLines writes each value on a line of its own, with strings written
+// as-is, other values as compact JSON and values not found as empty lines.
+func writeLines(w io.Writer, values []any, found []bool) (err error) {
+	var line []byte
+
+	for i, value := range values {
+		line = line[:0]
+		switch s, isString := value.(string); {
+		case !found[i]:
+		case isString:
+			line = append(line, s...)
+		default:
+			line, err = jsonv2.Marshal(value)
+			if err != nil {
+				goto end
+			}
+		}
+		_, err = fmt.Fprintf(w, "%s\n", line)
+		if err != nil {
+			goto end
+		}
+	}
+
+end:
+	return err
+}
+
+// writeObject writes the found values as a JSON object whose members are
+// named by their selectors, in the order the selectors were given.
+func writeObject(w io.Writer, selectors []string, values []any, found []bool) (err error) {
+	encoder := jsontext.NewEncoder(w)
+
+	err = encoder.WriteToken(jsontext.BeginObject)
+	if err != nil {
+		goto end
+	}
+	for i, selector := range selectors {
+		if !found[i] {
+			continue
+		}
+		err = encoder.WriteToken(jsontext.String(selector))
+		if err != nil {
+			goto end
+		}
+		err = jsonv2.MarshalEncode(encoder, values[i])
+		if err != nil {
+			goto end
+		}
+	}
+	err = encoder.WriteToken(jsontext.EndObject)
+
+end:
+	return err
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+const sampleJSON = `{
+	"user": {"name": "Alice", "age": 30, "tags": ["a", "b"], "manager": null},
+	"active": true
+}`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "one value per line",
+			args: []string{"user.name", "user.age", "user.tags", "active", "user.manager"},
+			want: "Alice\n30\n[\"a\",\"b\"]\ntrue\nnull\n",
+		},
+		{
+			name: "json object",
+			args: []string{"-json", "user.age", "user.name"},
+			want: `{"user.age":30,"user.name":"Alice"}` + "\n",
+		},
+		{
+			name:    "missing selector",
+			args:    []string{"user.name", "user.email"},
+			want:    "Alice\n\n",
+			wantErr: jsonxtractr.ErrJSONPathSegmentNotFound,
+		},
+		{
+			name: "ignore missing",
+			args: []string{"--ignore-missing", "user.email", "user.name"},
+			want: "\nAlice\n",
+		},
+		{
+			name: "json omits missing",
+			args: []string{"--json", "--ignore-missing", "user.email", "active"},
+			want: `{"active":true}` + "\n",
+		},
+		{
+			name:    "no selectors",
+			args:    []string{"-json"},
+			wantErr: errUsage,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-bogus", "a"},
+			wantErr: errUsage,
+		},
+		{
+			name:    "invalid selector",
+			args:    []string{"--ignore-missing", `user."name`},
+			wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, strings.NewReader(sampleJSON), &stdout)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("run() error = %v, want %v", err, tt.wantErr)
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("run() output = %q, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_ErrorContext(t *testing.T) {
+	var stdout bytes.Buffer
+	err := run([]string{"user.email"}, strings.NewReader(sampleJSON), &stdout)
+	if err == nil {
+		t.Fatal("run() error = nil, want the missing selector reported")
+	}
+	for _, want := range []string{"user.email", "email", "available_keys"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("run() error = %q, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestRun_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.json")
+	err := os.WriteFile(path, []byte(sampleJSON), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	err = run([]string{"-file", path, "user.tags.-1"}, strings.NewReader("not read"), &stdout)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if stdout.String() != "b\n" {
+		t.Errorf("run() output = %q, want %q", stdout.String(), "b\n")
+	}
+
+	err = run([]string{"-file", filepath.Join(t.TempDir(), "missing.json"), "a"}, nil, &stdout)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("run() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestRun_MalformedJSON(t *testing.T) {
+	var stdout bytes.Buffer
+	err := run([]string{"--ignore-missing", "a"}, strings.NewReader(`{"a": [1, }`), &stdout)
+	if !errors.Is(err, jsonxtractr.ErrJSONStreamingParseFailed) {
+		t.Errorf("run() error = %v, want %v", err, jsonxtractr.ErrJSONStreamingParseFailed)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("run() output = %q, want nothing", stdout.String())
+	}
+}
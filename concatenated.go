@@ -0,0 +1,75 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"errors"
+	"io"
+)
+
+// ExtractFromDocuments applies selector to each of a stream of back-to-back
+// JSON values, such as `{"a":1}{"a":2}` or one value per line, calling fn with
+// each document's zero-based index and either its selected value or the error
+// selecting it. An error for one document doesn't stop the stream, so fn can
+// skip documents that lack the selector.
+//
+// Top-level scalars are documents like any other: RootSelector selects them,
+// while any other selector reports ErrJSONPathExpectedObjectAtSegment or
+// ErrJSONPathExpectedArrayAtSegment for them.
+//
+// A malformed document or a failure to read the stream ends it, since where
+// the next document would begin can't be known; fn receives that error as its
+// last call. An invalid selector is reported the same way for document 0.
+func ExtractFromDocuments(reader io.Reader, selector Selector, fn func(docIndex int, value any, err error)) {
+	var decoder *jsontext.Decoder
+	var segments []segment
+	var err error
+
+	if reader == nil {
+		fn(0, nil, NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		))
+		goto end
+	}
+
+	// Check the selector once rather than failing it for every document
+	segments, err = selector.parseMatching()
+	if err == nil {
+		err = newExtractState(nil, string(selector), segments, nil).rejectWildcards()
+	}
+	if err != nil {
+		fn(0, nil, err)
+		goto end
+	}
+
+	decoder = options{}.newDecoder(reader)
+	for docIndex := 0; ; docIndex++ {
+		var value any
+
+		doc, readErr := decoder.ReadValue()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			fn(docIndex, nil, NewErr(
+				ErrJSONStreamingParseFailed,
+				ErrJSONTokenReadFailed,
+				"document_index", docIndex,
+				"byte_offset", decoder.InputOffset(),
+				readErr,
+			))
+			break
+		}
+
+		value, err = extractSingleValue(bytes.NewReader(doc), selector, doc, options{})
+		if err != nil {
+			err = WithErr(err, "document_index", docIndex)
+		}
+		fn(docIndex, value, err)
+	}
+
+end:
+	return
+}
@@ -0,0 +1,95 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+)
+
+// Count returns the number of elements in the array, or members in the object,
+// selected from JSON bytes. Elements are skipped rather than decoded, so
+// counting a huge array is cheap. Selecting a scalar returns ErrJSONTypeMismatch.
+func Count(jsonBytes []byte, selector Selector) (count int, err error) {
+	var state *extractState
+	var kind jsontext.Kind
+	var closing jsontext.Kind
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	kind = state.decoder.PeekKind()
+	switch kind {
+	case '[':
+		closing = ']'
+	case '{':
+		closing = '}'
+	default:
+		err = state.enrichError(
+			ErrJSONTypeMismatch,
+			"expected_type", "array or object",
+			"actual_type", kindOf(kind).String(),
+		)
+		goto end
+	}
+
+	// Read container start token
+	_, err = state.decoder.ReadToken()
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "container_start",
+			err,
+		)
+		goto end
+	}
+
+	for state.decoder.PeekKind() != closing {
+		if kind == '{' {
+			// Skip the member name; its value is skipped below
+			_, err = state.decoder.ReadToken()
+			if err != nil {
+				err = state.enrichError(
+					ErrJSONPathTraversalFailed,
+					ErrJSONTokenReadFailed,
+					"reading", "object_key",
+					err,
+				)
+				goto end
+			}
+		}
+		err = state.decoder.SkipValue()
+		if err != nil {
+			err = state.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONTokenReadFailed,
+				"skip_index", count,
+				err,
+			)
+			goto end
+		}
+		count++
+	}
+
+end:
+	if err != nil {
+		count = 0
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return count, err
+}
@@ -0,0 +1,47 @@
+package jsonxtractr
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+)
+
+// Decoder reads the JSON a selector is navigated over, one token or value at
+// a time. Tokens, values and their kinds are those of encoding/json/jsontext,
+// so an alternative JSON engine is adapted by converting its own to them.
+// The default Decoder is a jsontext.Decoder; see WithDecoderFactory.
+type Decoder interface {
+	// PeekKind returns the kind of the next token without consuming it, or
+	// 0 when there is none, as at the end of the input or on an error.
+	PeekKind() jsontext.Kind
+
+	// ReadToken reads the next token.
+	ReadToken() (jsontext.Token, error)
+
+	// ReadValue reads the next value whole. The value need only remain
+	// valid until the next call.
+	ReadValue() (jsontext.Value, error)
+
+	// SkipValue reads the next value, discarding it.
+	SkipValue() error
+
+	// UnmarshalInto decodes the next value into v, a non-nil pointer, with
+	// the given json/v2 options.
+	UnmarshalInto(v any, opts ...jsonv2.Options) error
+
+	// InputOffset returns the offset within the input just past the last
+	// token or value read.
+	InputOffset() int64
+
+	// StackDepth returns the number of objects and arrays the decoder is
+	// within.
+	StackDepth() int
+}
+
+// jsontextDecoder is the default Decoder.
+type jsontextDecoder struct {
+	*jsontext.Decoder
+}
+
+func (d jsontextDecoder) UnmarshalInto(v any, opts ...jsonv2.Options) error {
+	return jsonv2.UnmarshalDecode(d.Decoder, v, opts...)
+}
@@ -0,0 +1,449 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"strconv"
+)
+
+// Document holds JSON that has been parsed and indexed once so that many
+// selectors can be answered without re-reading or re-decoding the input.
+// A Document is immutable after construction and safe for concurrent use.
+type Document struct {
+	rawBytes []byte
+	root     *docNode
+}
+
+// docNode records where a value lives in the raw bytes along with, for
+// objects and arrays, its members in document order.
+type docNode struct {
+	kind      jsontext.Kind
+	start     int64
+	end       int64
+	keys      []string   // object member names, parallel to children
+	children  []*docNode // object member values or array elements
+	selection bool       // an index list's new array, not a value in the raw bytes
+}
+
+// NewDocument parses and validates jsonBytes once, indexing every value so
+// later lookups only decode the values they select. jsonBytes must hold a
+// single JSON value, and is copied so later changes to it can't affect the
+// Document.
+func NewDocument(jsonBytes []byte) (doc *Document, err error) {
+	var decoder *jsontext.Decoder
+	var root *docNode
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+		)
+		goto end
+	}
+
+	decoder = options{}.newDecoder(bytes.NewReader(jsonBytes))
+	root, err = indexValue(decoder)
+	if err != nil {
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			"condensed_json", newExtractState(nil, "", nil, jsonBytes).condensedJSON(),
+			err,
+		)
+		goto end
+	}
+
+	// Anything after the value would go unread by every lookup
+	err = checkAtEOF(decoder, jsonBytes)
+	if err != nil {
+		goto end
+	}
+
+	doc = &Document{
+		rawBytes: bytes.Clone(jsonBytes),
+		root:     root,
+	}
+
+end:
+	return doc, err
+}
+
+// Value extracts a single value from the document
+func (d *Document) Value(selector Selector) (value any, err error) {
+	var node *docNode
+
+	node, err = d.lookup(selector)
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONDocument,
+			"selector", selector,
+			err,
+		)
+		goto end
+	}
+
+	value, err = d.decode(selector, node)
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONDocument,
+			"selector", selector,
+			err,
+		)
+	}
+
+end:
+	return value, err
+}
+
+// Values extracts multiple values from the document. Returns values for found
+// selectors, list of selectors that were not found, and any errors. When
+// selectors fail, the error is a *MultiError giving the reason for each,
+// including those present but undecodable, which aren't returned as not found.
+func (d *Document) Values(selectors []Selector) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var failed []Selector
+	var errs []error
+
+	if len(selectors) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONValueSelectorCannotBeEmpty,
+		)
+		goto end
+	}
+
+	// Each selector is resolved and reported once, however often it's passed
+	selectors = Selectors(selectors).Unique()
+
+	valuesMap = make(ValuesMap, len(selectors))
+
+	for _, selector := range selectors {
+		var value any
+		var node *docNode
+		var selectorErr error
+
+		node, selectorErr = d.lookup(selector)
+		if selectorErr == nil {
+			value, selectorErr = d.decode(selector, node)
+		}
+		if selectorErr != nil {
+			errs = append(errs, selectorErr)
+			failed = append(failed, selector)
+			continue
+		}
+		valuesMap[selector] = value
+	}
+
+	// Each selector that failed is reported along with why
+	notFound = absentSelectors(failed, errs)
+	err = newMultiError(failed, errs)
+
+end:
+	return valuesMap, notFound, err
+}
+
+// lookup navigates the index to the node addressed by selector, reporting
+// failures with the same sentinels and context as streaming extraction.
+func (d *Document) lookup(selector Selector) (node *docNode, err error) {
+	var state *extractState
+	var segments []segment
+
+	if len(selector) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONValueSelectorCannotBeEmpty,
+		)
+		goto end
+	}
+
+	segments, err = parseSelector(string(selector))
+	if err != nil {
+		goto end
+	}
+
+	state = newExtractState(nil, string(selector), segments, d.rawBytes)
+	err = state.rejectWildcards()
+	if err != nil {
+		goto end
+	}
+
+	node = d.root
+	for i, seg := range state.segments {
+		state.position = i
+		if seg.isEmpty() {
+			err = state.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONPathContainsEmptySegment,
+			)
+			goto end
+		}
+
+		node, err = node.child(state, seg)
+		if err != nil {
+			goto end
+		}
+		state.pathProgress = append(state.pathProgress, seg.text)
+	}
+
+end:
+	return node, err
+}
+
+// decode unmarshals the raw bytes indexed by node.
+func (d *Document) decode(selector Selector, node *docNode) (value any, err error) {
+	if node.selection {
+		value, err = d.decodeSelection(selector, node)
+		goto end
+	}
+
+	err = jsonv2.Unmarshal(d.rawBytes[node.start:node.end], &value, options{}.unmarshalOptions())
+	if err != nil {
+		// The selector parsed during lookup, so it can't fail to parse here
+		segments, _ := parseSelector(string(selector))
+		state := newExtractState(nil, string(selector), segments, d.rawBytes)
+		state.position = len(state.segments) - 1
+		state.pathProgress = segmentTexts(state.segments)
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONUnmarshalFailed,
+			err,
+		)
+	}
+
+end:
+	return value, err
+}
+
+// child returns the member of n addressed by segment.
+func (n *docNode) child(state *extractState, seg segment) (child *docNode, err error) {
+	var idx int
+	var parseErr error
+
+	// Quoted segments are always object keys
+	if seg.kind == keySegment {
+		child, err = n.member(state, seg.text)
+		goto end
+	}
+
+	if seg.kind == filterSegment {
+		child, err = n.filtered(state, seg.filter)
+		goto end
+	}
+
+	if seg.kind == ordinalSegment {
+		child, err = n.ordinal(state, seg.ordinal)
+		goto end
+	}
+
+	if seg.kind == indexListSegment {
+		child, err = n.selected(state, seg.indexes)
+		goto end
+	}
+
+	// The data decides: the segment is an index into an array and a key of
+	// an object, even a numeric one
+	idx, parseErr = strconv.Atoi(seg.text)
+	switch {
+	case n.kind == '[' && parseErr != nil:
+		err = state.enrichErrorAt(n.start,
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedObjectAtSegment,
+			"expected_type", "object",
+			"actual_type", kindOf(n.kind).String(),
+			"actual_value_preview", state.valuePreview(state.rawBytes[n.start:n.end]),
+			"reason", indexExpected(seg.text),
+		)
+	case parseErr == nil && n.kind != '{':
+		child, err = n.element(state, idx)
+	default:
+		child, err = n.member(state, seg.text)
+	}
+end:
+	return child, err
+}
+
+// element returns the array element at targetIdx, where negative indexes
+// count back from the end of the array.
+func (n *docNode) element(state *extractState, targetIdx int) (child *docNode, err error) {
+	idx := targetIdx
+
+	if n.kind != '[' {
+		err = state.enrichErrorAt(n.start,
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedArrayAtSegment,
+			"expected_type", "array",
+			"actual_type", kindOf(n.kind).String(),
+			"actual_value_preview", state.valuePreview(state.rawBytes[n.start:n.end]),
+		)
+		goto end
+	}
+
+	// Negative indexes count back from the end of the array
+	if idx < 0 {
+		idx += len(n.children)
+	}
+
+	if idx < 0 || idx >= len(n.children) {
+		err = state.enrichErrorAt(n.start,
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_index", targetIdx,
+			"array_length", len(n.children),
+		)
+		goto end
+	}
+
+	child = n.children[idx]
+end:
+	return child, err
+}
+
+// filtered returns the first array element matching filter.
+func (n *docNode) filtered(state *extractState, filter *elementFilter) (child *docNode, err error) {
+	if n.kind != '[' {
+		err = state.enrichErrorAt(n.start,
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedArrayAtSegment,
+			"expected_type", "array",
+			"actual_type", kindOf(n.kind).String(),
+			"actual_value_preview", state.valuePreview(state.rawBytes[n.start:n.end]),
+		)
+		goto end
+	}
+
+	for _, element := range n.children {
+		if filter.matches(state.rawBytes[element.start:element.end]) {
+			child = element
+			goto end
+		}
+	}
+
+	err = state.enrichErrorAt(n.start,
+		ErrJSONPathTraversalFailed,
+		ErrJSONSelectorNotFound,
+		"array_length", len(n.children),
+	)
+end:
+	return child, err
+}
+
+// member returns the value of the first object member named targetKey.
+func (n *docNode) member(state *extractState, targetKey string) (child *docNode, err error) {
+	if n.kind != '{' {
+		err = state.enrichErrorAt(n.start,
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedObjectAtSegment,
+			"expected_type", "object",
+			"actual_type", kindOf(n.kind).String(),
+			"actual_value_preview", state.valuePreview(state.rawBytes[n.start:n.end]),
+		)
+		goto end
+	}
+
+	for i, key := range n.keys {
+		if key == targetKey {
+			child = n.children[i]
+			goto end
+		}
+	}
+
+	// Located at the object's closing brace
+	err = state.enrichErrorAt(n.end-1,
+		ErrJSONPathTraversalFailed,
+		ErrJSONPathSegmentNotFound,
+		"missing_key", targetKey,
+		"available_keys", n.keys,
+	)
+end:
+	return child, err
+}
+
+// ordinal returns the value of the object member at zero-based position
+// ordinal in document order.
+func (n *docNode) ordinal(state *extractState, ordinal int) (child *docNode, err error) {
+	if n.kind != '{' {
+		err = state.enrichErrorAt(n.start,
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedObjectAtSegment,
+			"expected_type", "object",
+			"actual_type", kindOf(n.kind).String(),
+			"actual_value_preview", state.valuePreview(state.rawBytes[n.start:n.end]),
+		)
+		goto end
+	}
+
+	if ordinal >= len(n.children) {
+		// Located at the object's closing brace
+		err = state.enrichErrorAt(n.end-1,
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_ordinal", ordinal,
+			"object_length", len(n.children),
+			"available_keys", n.keys,
+		)
+		goto end
+	}
+
+	child = n.children[ordinal]
+end:
+	return child, err
+}
+
+// indexValue reads the value the decoder is positioned at, recording the byte
+// span of it and of every value nested within it.
+func indexValue(decoder *jsontext.Decoder) (node *docNode, err error) {
+	var value jsontext.Value
+	var keyToken jsontext.Token
+	var child *docNode
+	var closing jsontext.Kind
+
+	node = &docNode{kind: decoder.PeekKind()}
+
+	switch node.kind {
+	case '{', '[':
+		// Read container start token
+		_, err = decoder.ReadToken()
+		if err != nil {
+			goto end
+		}
+		node.start = decoder.InputOffset() - 1
+		closing = ']'
+		if node.kind == '{' {
+			closing = '}'
+			node.keys = make([]string, 0)
+		}
+		node.children = make([]*docNode, 0)
+		for decoder.PeekKind() != closing {
+			if node.kind == '{' {
+				keyToken, err = decoder.ReadToken()
+				if err != nil {
+					goto end
+				}
+				node.keys = append(node.keys, keyToken.String())
+			}
+			child, err = indexValue(decoder)
+			if err != nil {
+				goto end
+			}
+			node.children = append(node.children, child)
+		}
+		// Read container end token
+		_, err = decoder.ReadToken()
+		if err != nil {
+			goto end
+		}
+		node.end = decoder.InputOffset()
+	default:
+		value, err = decoder.ReadValue()
+		if err != nil {
+			goto end
+		}
+		node.end = decoder.InputOffset()
+		node.start = node.end - int64(len(value))
+	}
+
+end:
+	return node, err
+}
@@ -6,21 +6,64 @@ import (
 
 // Sentinel errors for various jsonxtractr operations.
 var (
+	ErrJSONAmbiguousKey                = errors.New("JSON path segment matches multiple keys")
 	ErrJSONBodyCannotBeEmpty           = errors.New("JSON body cannot be empty")
+	ErrJSONDuplicateKey                = errors.New("JSON object has duplicate key")
+	ErrJSONDestinationInvalid          = errors.New("JSON destination must be a non-nil pointer")
+	ErrJSONExtractionCanceled          = errors.New("JSON extraction canceled")
+	ErrJSONFileReadFailed              = errors.New("JSON file read failed")
+	ErrJSONGzipReadFailed              = errors.New("JSON gzip read failed")
+	ErrJSONHTTPStatus                  = errors.New("JSON HTTP response has non-success status")
+	ErrJSONInputTooLarge               = errors.New("JSON input too large")
 	ErrJSONIndexOutOfRange             = errors.New("JSON index out of range")
+	ErrJSONInvalidUTF8                 = errors.New("JSON contains invalid UTF-8")
+	ErrJSONMarshalFailed               = errors.New("JSON marshal failed")
+	ErrJSONMaxDepthExceeded            = errors.New("JSON nesting exceeds maximum depth")
+	ErrJSONTooManyTokens               = errors.New("JSON input exceeds maximum tokens")
+	ErrJSONNDJSONLineFailed            = errors.New("NDJSON line failed")
 	ErrJSONPathContainsEmptySegment    = errors.New("JSON path contains empty segment")
 	ErrJSONPathExpectedArrayAtSegment  = errors.New("JSON path expected array at segment")
 	ErrJSONPathExpectedObjectAtSegment = errors.New("JSON path expected object at segment")
 	ErrJSONPathSegmentNotFound         = errors.New("JSON path segment not found")
 	ErrJSONPathTraversalFailed         = errors.New("JSON path traversal failed")
+	ErrJSONPointerInvalid              = errors.New("JSON pointer is invalid")
 	ErrJSONReadFailed                  = errors.New("JSON read failed")
+	ErrJSONSkipFailed                  = errors.New("JSON became unparseable while skipping to the target")
 	ErrJSONStreamingParseFailed        = errors.New("JSON streaming parse failed")
 	ErrJSONTokenReadFailed             = errors.New("JSON token read failed")
+	ErrJSONTypeMismatch                = errors.New("JSON type mismatch")
 	ErrJSONUnmarshalFailed             = errors.New("JSON unmarshal failed")
 	ErrJSONValueSelectorCannotBeEmpty  = errors.New("JSON value selector cannot be empty")
+	ErrJSONWriteFailed                 = errors.New("JSON write failed")
 	ErrJSONSelectorNotFound            = errors.New("JSON selector not found")
+	ErrJSONSelectorMultiMatch          = errors.New("JSON selector can match multiple values")
+	ErrJSONSelectorInvalid             = errors.New("JSON selector is invalid")
+	ErrJSONSelectorUnbalancedQuote     = errors.New("JSON selector has unbalanced quote")
+	ErrJSONSelectorDanglingEscape      = errors.New("JSON selector ends with dangling escape")
+	ErrJSONSelectorRegexInvalid        = errors.New("JSON selector has invalid regular expression")
+	ErrJSONSelectorFilterInvalid       = errors.New("JSON selector has invalid filter")
+	ErrJSONSelectorIndexListInvalid    = errors.New("JSON selector has invalid index list")
+	ErrJSONSelectorBracketInvalid      = errors.New("JSON selector has invalid bracket")
+	ErrJSONSelectorConflict            = errors.New("JSON selector conflicts with another selector")
 	ErrExtractingFromJSONByReader      = errors.New("extracting from JSON by reader")
 	ErrExtractingFromJSONBytes         = errors.New("extracting from JSON bytes")
+	ErrExtractingFromJSONDocument      = errors.New("extracting from JSON document")
+	ErrExtractingFromJSONPointer       = errors.New("extracting from JSON pointer")
 	ErrExtractingJSONBodyValues        = errors.New("extracting JSON body values")
 	ErrFailedToExtractValueFromJSON    = errors.New("failed to extract value from JSON")
+	ErrSettingInJSONBytes              = errors.New("setting in JSON bytes")
+	ErrFailedToSetValueInJSON          = errors.New("failed to set value in JSON")
 )
+
+// isNotFound reports whether err means a selector's path is merely absent
+// from the document, either because a key or index is missing or because a
+// segment met a value of the wrong type, as opposed to the input or the
+// selector being broken. Every "use a default when missing" decision should
+// go through it.
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrJSONSelectorNotFound) ||
+		errors.Is(err, ErrJSONPathSegmentNotFound) ||
+		errors.Is(err, ErrJSONIndexOutOfRange) ||
+		errors.Is(err, ErrJSONPathExpectedObjectAtSegment) ||
+		errors.Is(err, ErrJSONPathExpectedArrayAtSegment)
+}
@@ -0,0 +1,86 @@
+package jsonxtractr
+
+import (
+	"bytes"
+)
+
+// Exists reports whether selector resolves to a value in JSON bytes without
+// decoding that value. A key present with a JSON null value exists. A path
+// that is simply absent, including one that expects an object or array where
+// the document has something else, returns false with a nil error. A non-nil
+// error means the input or the selector is broken, e.g. malformed JSON or an
+// empty selector segment.
+func Exists(jsonBytes []byte, selector Selector) (exists bool, err error) {
+	var state *extractState
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if isNotFound(err) {
+		err = nil
+		goto end
+	}
+	if err != nil {
+		goto end
+	}
+
+	// Skipping still validates the selected value without decoding it
+	err = state.decoder.SkipValue()
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+		goto end
+	}
+	exists = true
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return exists, err
+}
+
+// ExistsNonNull reports whether selector resolves to a value other than JSON
+// null. Together with Exists it tells an absent path, a present null and a
+// present value apart. Like TypeOf it reads only the first byte of the
+// selected value, and like Exists it returns false with a nil error for a
+// path that is simply absent.
+func ExistsNonNull(jsonBytes []byte, selector Selector) (exists bool, err error) {
+	var kind Kind
+
+	kind, err = TypeOf(jsonBytes, selector)
+	if IsAbsent(err) {
+		err = nil
+		goto end
+	}
+	if err != nil {
+		goto end
+	}
+	exists = kind != KindNull
+
+end:
+	return exists, err
+}
+
+// IsAbsent reports whether err from an extraction means the document has no
+// value at the selected path, as opposed to the input or the selector being
+// broken. Together with a nil error it tells a key set to JSON null, which
+// extracts as a nil value with a nil error, apart from a key that is missing.
+func IsAbsent(err error) bool {
+	return isNotFound(err)
+}
@@ -0,0 +1,432 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+)
+
+// ExtractAs extracts a single value from JSON bytes and converts it to T.
+//
+// Numbers convert to any numeric T as long as they fit without overflow or
+// truncation, so 3 extracts as an int but 3.5 does not. A struct T receives
+// the selected subtree as if it had been unmarshaled directly. Values that
+// don't convert cleanly, such as a JSON string extracted as a bool, return
+// ErrJSONTypeMismatch rather than a guess.
+func ExtractAs[T any](jsonBytes []byte, selector Selector) (T, error) {
+	return extractAs[T](jsonBytes, selector)
+}
+
+// ExtractString extracts a JSON string from JSON bytes.
+func ExtractString(jsonBytes []byte, selector Selector) (string, error) {
+	return extractAs[string](jsonBytes, selector, '"')
+}
+
+// ExtractInt extracts a JSON number from JSON bytes as an int. Numbers with a
+// fractional part or outside the range of int return ErrJSONTypeMismatch
+// rather than being truncated.
+func ExtractInt(jsonBytes []byte, selector Selector) (int, error) {
+	return extractAs[int](jsonBytes, selector, '0')
+}
+
+// ExtractInt64 extracts a JSON number from JSON bytes as an int64, with the
+// same checks as ExtractInt.
+func ExtractInt64(jsonBytes []byte, selector Selector) (int64, error) {
+	return extractAs[int64](jsonBytes, selector, '0')
+}
+
+// ExtractFloat64 extracts a JSON number from JSON bytes as a float64.
+func ExtractFloat64(jsonBytes []byte, selector Selector) (float64, error) {
+	return extractAs[float64](jsonBytes, selector, '0')
+}
+
+// ExtractBool extracts a JSON true or false from JSON bytes.
+func ExtractBool(jsonBytes []byte, selector Selector) (bool, error) {
+	return extractAs[bool](jsonBytes, selector, 't', 'f')
+}
+
+// AsInt converts a number returned by extraction to an int: a float64, as
+// numbers are extracted by default, a json.Number, as WithNumbersAsString and
+// WithSmartNumbers may return, or an integer type. Numbers with a fractional
+// part or outside the range of int return ErrJSONTypeMismatch rather than
+// being truncated, as do values that aren't numbers, such as strings and
+// bools.
+func AsInt(value any) (n int, err error) {
+	var n64 int64
+
+	n64, err = asInt64(value, "int")
+	if err != nil {
+		goto end
+	}
+	n = int(n64)
+	if int64(n) != n64 {
+		err = NewErr(
+			ErrJSONTypeMismatch,
+			"target_type", "int",
+			"value", value,
+			"reason", "out of range",
+		)
+		n = 0
+	}
+
+end:
+	return n, err
+}
+
+// AsInt64 converts a number returned by extraction to an int64, with the same
+// checks as AsInt.
+func AsInt64(value any) (int64, error) {
+	return asInt64(value, "int64")
+}
+
+// asInt64 implements AsInt64, reporting failures for targetType.
+func asInt64(value any, targetType string) (n int64, err error) {
+	var reason string
+
+	switch v := value.(type) {
+	case float64:
+		switch {
+		case v != math.Trunc(v):
+			// Also true of NaN, while infinities are out of range
+			reason = "not an integer"
+		case v < math.MinInt64 || v >= math.MaxInt64:
+			// MaxInt64 rounds up to 2^63 as a float64, which is out of range
+			reason = "out of range"
+		default:
+			n = int64(v)
+		}
+	case json.Number:
+		if !jsontext.Value(v).IsValid() || jsontext.Value(v).Kind() != '0' {
+			reason = "not a number"
+			break
+		}
+		err = unmarshalAs(jsontext.Value(v), &n, options{})
+		if err != nil {
+			reason = err.Error()
+			err = nil
+		}
+	case int:
+		n = int64(v)
+	case int8:
+		n = int64(v)
+	case int16:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint:
+		n = int64(v)
+		if v > math.MaxInt64 {
+			reason = "out of range"
+		}
+	case uint8:
+		n = int64(v)
+	case uint16:
+		n = int64(v)
+	case uint32:
+		n = int64(v)
+	case uint64:
+		n = int64(v)
+		if v > math.MaxInt64 {
+			reason = "out of range"
+		}
+	default:
+		reason = "not a number"
+	}
+
+	if reason != "" {
+		n = 0
+		err = NewErr(
+			ErrJSONTypeMismatch,
+			"target_type", targetType,
+			"value_type", fmt.Sprintf("%T", value),
+			"value", value,
+			"reason", reason,
+		)
+	}
+	return n, err
+}
+
+// extractAs implements ExtractAs. When kinds are given, the selected value
+// must be one of those kinds, which keeps JSON null from silently extracting
+// as the zero value of a scalar type.
+func extractAs[T any](jsonBytes []byte, selector Selector, kinds ...jsontext.Kind) (result T, err error) {
+	var state *extractState
+	var value jsontext.Value
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	value, err = state.decoder.ReadValue()
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+		goto end
+	}
+
+	if len(kinds) > 0 && !slices.Contains(kinds, value.Kind()) {
+		err = state.enrichError(
+			ErrJSONTypeMismatch,
+			"target_type", reflect.TypeFor[T]().String(),
+			"json_kind", value.Kind().String(),
+		)
+		goto end
+	}
+
+	err = unmarshalAs(value, &result, options{})
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONTypeMismatch,
+			"target_type", reflect.TypeFor[T]().String(),
+			"json_kind", value.Kind().String(),
+			err,
+		)
+	}
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return result, err
+}
+
+// unmarshalAs unmarshals value into dst. Numbers destined for an integer are
+// converted whenever they are integral and in range, so 3.0 and 1e2 convert
+// while 1.5 does not, rather than requiring integer syntax.
+func unmarshalAs(value jsontext.Value, dst any, opts options) (err error) {
+	var f *big.Float
+	var n *big.Int
+	var ok bool
+
+	rv := reflect.ValueOf(dst).Elem()
+	if value.Kind() != '0' {
+		err = jsonv2.Unmarshal(value, dst, opts.unmarshalOptions())
+		goto end
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		err = jsonv2.Unmarshal(value, dst, opts.unmarshalOptions())
+		goto end
+	}
+
+	// Enough precision that a fractional part can't be rounded away
+	f, ok = new(big.Float).SetPrec(uint(len(value))*4 + 64).SetString(string(value))
+	if !ok || !f.IsInt() {
+		err = fmt.Errorf("JSON number %s is not an integer", value)
+		goto end
+	}
+
+	// Reject a magnitude of 2^bits or more before Int spells out every digit,
+	// e.g. of 1e640000000
+	if f.MantExp(nil) > rv.Type().Bits() {
+		err = fmt.Errorf("JSON number %s overflows %s", value, rv.Type())
+		goto end
+	}
+	n, _ = f.Int(nil)
+
+	switch {
+	case rv.CanInt() && n.IsInt64() && !rv.OverflowInt(n.Int64()):
+		rv.SetInt(n.Int64())
+	case rv.CanUint() && n.IsUint64() && !rv.OverflowUint(n.Uint64()):
+		rv.SetUint(n.Uint64())
+	default:
+		err = fmt.Errorf("JSON number %s overflows %s", value, rv.Type())
+	}
+
+end:
+	return err
+}
+
+// ExtractInto decodes the value selected from JSON bytes directly into dst,
+// which must be a non-nil pointer, honoring any `json:"..."` struct tags. The
+// selected subtree is decoded once, straight from the input, rather than
+// round-tripping through any.
+//
+// A selector whose path doesn't exist returns ErrJSONSelectorNotFound.
+func ExtractInto(jsonBytes []byte, selector Selector, dst any) error {
+	return ExtractIntoOpts(jsonBytes, selector, dst)
+}
+
+// ExtractIntoOpts is ExtractInto with options, which also apply to decoding
+// the selected value into dst, e.g. WithRejectUnknownMembers.
+func ExtractIntoOpts(jsonBytes []byte, selector Selector, dst any, opts ...Option) (err error) {
+	var state *extractState
+	var rv reflect.Value
+	var o options
+
+	rv = reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		err = NewErr(
+			ErrJSONDestinationInvalid,
+			"destination_type", reflect.TypeOf(dst),
+		)
+		goto end
+	}
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	o = newOptions(opts)
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, o)
+	if err != nil {
+		if isNotFound(err) {
+			err = NewErr(ErrJSONSelectorNotFound, err)
+		}
+		goto end
+	}
+
+	err = state.decoder.UnmarshalInto(dst, o.unmarshalOptions())
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONUnmarshalFailed,
+			"destination_type", rv.Type(),
+			err,
+		)
+	}
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return err
+}
+
+// ExtractMapAs extracts the object selected from JSON bytes as a map from each
+// of its keys to its value converted to V, with the same conversions as
+// ExtractAs, e.g. for a map[string]int of settings. When a key appears more
+// than once the first wins. A selected value other than an object returns
+// ErrJSONPathExpectedObjectAtSegment, and a member value that doesn't convert
+// to V returns ErrJSONTypeMismatch naming the member as "key".
+func ExtractMapAs[V any](jsonBytes []byte, selector Selector) (result map[string]V, err error) {
+	var state *extractState
+	var kind jsontext.Kind
+	var token jsontext.Token
+	var value jsontext.Value
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	kind = jsontext.Kind(state.decoder.PeekKind())
+	if kind != '{' {
+		err = state.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedObjectAtSegment,
+			"expected_type", "object",
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", state.valuePreview(state.unreadInput()),
+		)
+		goto end
+	}
+
+	// Read object start token '{'
+	_, err = state.decoder.ReadToken()
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+		goto end
+	}
+
+	result = make(map[string]V)
+	for state.decoder.PeekKind() != '}' {
+		var v V
+		var key string
+
+		// The key must be copied out before the value is read
+		token, err = state.decoder.ReadToken()
+		if err == nil {
+			key = token.String()
+			value, err = state.decoder.ReadValue()
+		}
+		if err != nil {
+			err = state.enrichError(
+				ErrJSONStreamingParseFailed,
+				ErrJSONTokenReadFailed,
+				err,
+			)
+			goto end
+		}
+
+		if _, ok := result[key]; ok {
+			continue
+		}
+		err = unmarshalAs(value, &v, options{})
+		if err != nil {
+			err = state.enrichErrorAt(state.inputOffset()-int64(len(value)),
+				ErrJSONTypeMismatch,
+				"target_type", reflect.TypeFor[V]().String(),
+				"key", key,
+				"json_kind", value.Kind().String(),
+				err,
+			)
+			goto end
+		}
+		result[key] = v
+	}
+
+end:
+	if err != nil {
+		result = nil
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return result, err
+}
@@ -0,0 +1,102 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/json/jsontext"
+	"io"
+	"slices"
+)
+
+// ExtractRaw extracts the raw JSON of a single value from JSON bytes without
+// decoding it. The returned bytes are the selected subtree exactly as it
+// appears in the input, including any braces or brackets, so key order,
+// number formatting and string escapes are preserved.
+func ExtractRaw(jsonBytes []byte, selector Selector) (raw json.RawMessage, err error) {
+	var value jsontext.Value
+
+	value, err = readRawValue(jsonBytes, selector)
+	if err != nil {
+		goto end
+	}
+
+	// The decoder reuses its buffer, so the value must not escape uncopied
+	raw = slices.Clone(value)
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return raw, err
+}
+
+// WriteValueTo writes the raw JSON of a single value from JSON bytes to w,
+// returning the number of bytes written. The bytes are written verbatim, as
+// ExtractRaw returns them, and nothing is written if the value can't be
+// found. A failed write returns an error wrapping ErrJSONWriteFailed.
+func WriteValueTo(w io.Writer, jsonBytes []byte, selector Selector) (n int, err error) {
+	var value jsontext.Value
+
+	value, err = readRawValue(jsonBytes, selector)
+	if err != nil {
+		goto end
+	}
+
+	n, err = w.Write(value)
+	if err != nil {
+		err = NewErr(
+			ErrJSONWriteFailed,
+			"bytes_written", n,
+			err,
+		)
+	}
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return n, err
+}
+
+// readRawValue navigates to the value addressed by selector and reads it
+// without decoding. The value aliases the decoder's buffer, so it must be
+// copied if it is to outlive the caller.
+func readRawValue(jsonBytes []byte, selector Selector) (value jsontext.Value, err error) {
+	var state *extractState
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	value, err = state.decoder.ReadValue()
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+	}
+
+end:
+	return value, err
+}
@@ -1,69 +1,204 @@
 package jsonxtractr
 
 import (
+	"bytes"
 	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 type extractState struct {
-	decoder      *jsontext.Decoder
+	decoder      Decoder
 	selector     string
-	segments     []string
+	segments     []segment
 	pathProgress []string
 	position     int
 	rawBytes     []byte
+	consumed     *bytes.Buffer // input read so far when streaming without rawBytes
+	baseOffset   int64         // offset of the decoder's input within rawBytes
+	baseDepth    int           // nesting depth of the decoder's input within rawBytes
+	elementIndex int           // index of the element the last filter or negative index matched
+	ordinalKey   string        // key of the member the last ordinal segment matched
+	matchedKey   string        // key of the member the last key segment matched, as written
+	numericKey   bool          // whether the last numeric segment was taken as an object key
+	resolved     []segment     // concrete segments navigated so far, when recorded
+	tokens       *int64        // tokens stepped over, shared with states spawned from this one
+	opts         options
 }
 
-func newExtractState(decoder *jsontext.Decoder, selector string, rawBytes []byte) *extractState {
+func newExtractState(decoder Decoder, selector string, segments []segment, rawBytes []byte) *extractState {
 	return &extractState{
 		decoder:      decoder,
 		selector:     selector,
-		segments:     strings.Split(selector, "."),
+		segments:     segments,
 		pathProgress: make([]string, 0),
 		position:     0,
 		rawBytes:     rawBytes,
+		tokens:       new(int64),
 	}
 }
 
+// countToken records that navigation stepped over another object member or
+// array element, failing with ErrJSONTooManyTokens once past the limit.
+func (s *extractState) countToken() (err error) {
+	*s.tokens++
+	if *s.tokens > s.opts.tokenLimit() {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTooManyTokens,
+			"max_tokens", s.opts.tokenLimit(),
+		)
+	}
+	return err
+}
+
+// rejectWildcards reports the first segment that could match more than the
+// single value the caller expects, if any, as those Selector.HasWildcard
+// counts.
+func (s *extractState) rejectWildcards() (err error) {
+	for i, seg := range s.segments {
+		if seg.isEmpty() && !isDescent(s.segments, i) {
+			// Navigation reaches it first, reporting the empty segment
+			break
+		}
+		if !seg.isMultiMatch() && !isDescent(s.segments, i) {
+			continue
+		}
+		s.position = i
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONSelectorMultiMatch,
+		)
+		break
+	}
+	return err
+}
+
 // navigateToSegment handles navigation to a specific segment in the JSON path
-func (s *extractState) navigateToSegment(segment string) (err error) {
+func (s *extractState) navigateToSegment(seg segment) (err error) {
+	var idx int
+	var parseErr error
+	var kind jsontext.Kind
 
-	// Check if this is a numeric index (array access)
-	idx, parseErr := strconv.Atoi(segment)
-	if parseErr == nil {
-		err = s.navigateArrayIndex(idx)
+	// Quoted segments are always object keys
+	if seg.kind == keySegment {
+		err = s.navigateObjectKey(seg.text)
+		goto end
+	}
+
+	if seg.kind == filterSegment {
+		err = s.navigateFilter(seg.filter)
 		goto end
 	}
 
-	// Handle object key access
-	err = s.navigateObjectKey(segment)
+	if seg.kind == ordinalSegment {
+		err = s.navigateObjectOrdinal(seg.ordinal)
+		goto end
+	}
+
+	if seg.kind == indexListSegment {
+		err = s.navigateIndexList(seg.indexes)
+		goto end
+	}
+
+	if seg.kind == pointerSegment {
+		err = s.navigatePointerToken(seg.text)
+		goto end
+	}
+
+	// The data decides: the segment is an index into an array and a key of
+	// an object, even a numeric one
+	idx, parseErr = strconv.Atoi(seg.text)
+	kind = jsontext.Kind(s.decoder.PeekKind())
+	s.numericKey = parseErr == nil && kind == '{'
+	switch {
+	case kind == '[' && parseErr != nil:
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedObjectAtSegment,
+			"expected_type", "object",
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", s.valuePreview(s.unreadInput()),
+			"reason", indexExpected(seg.text),
+		)
+	case parseErr == nil && kind != '{':
+		err = s.navigateArrayIndex(idx)
+	default:
+		err = s.navigateObjectKey(seg.text)
+	}
 end:
 	return err
 }
 
+// skipFailed returns the error for cause, the decoder failing to step over a
+// sibling of the target, such as the member or element parts name with
+// "skipping_key" or "skip_index". It wraps ErrJSONSkipFailed and is located
+// where the input became unparseable, so that JSON broken ahead of the target
+// isn't mistaken for a path that doesn't match.
+func (s *extractState) skipFailed(cause error, parts ...any) error {
+	var syntaxErr *jsontext.SyntacticError
+
+	offset := s.inputOffset()
+	if offset >= 0 && errors.As(cause, &syntaxErr) {
+		offset = s.baseOffset + syntaxErr.ByteOffset
+	}
+	parts = append([]any{
+		ErrJSONPathTraversalFailed,
+		ErrJSONTokenReadFailed,
+		ErrJSONSkipFailed,
+	}, parts...)
+	return s.enrichErrorAt(offset, append(parts, cause)...)
+}
+
+// indexExpected returns the reason a segment that isn't an array index fails
+// on an array.
+func indexExpected(text string) string {
+	return fmt.Sprintf("expected index but got %q", text)
+}
+
+// resolvedSegment returns the concrete segment seg was just navigated along:
+// the index of the element a filter or negative index matched, or the key of
+// the member an ordinal or key matched, as written in the document, which
+// differs under case-insensitive matching.
+func (s *extractState) resolvedSegment(seg segment) segment {
+	switch seg.kind {
+	case filterSegment:
+		seg = segment{kind: nameSegment, text: strconv.Itoa(s.elementIndex)}
+	case ordinalSegment:
+		seg = segment{kind: keySegment, text: s.ordinalKey}
+	case keySegment:
+		seg = segment{kind: keySegment, text: s.matchedKey}
+	case nameSegment:
+		idx, isIndex := seg.arrayIndex()
+		switch {
+		case !isIndex, s.numericKey:
+			seg = segment{kind: keySegment, text: s.matchedKey}
+		case idx < 0:
+			seg = segment{kind: nameSegment, text: strconv.Itoa(s.elementIndex)}
+		}
+	}
+	return seg
+}
+
 // navigateArrayIndex handles array index navigation
 func (s *extractState) navigateArrayIndex(targetIdx int) (err error) {
 	var currentIdx int
+	var arrayStart int64
 
 	kind := jsontext.Kind(s.decoder.PeekKind())
 
-	// Check for negative index
-	if targetIdx < 0 {
-		err = s.enrichError(
-			ErrJSONPathTraversalFailed,
-			ErrJSONIndexOutOfRange,
-			"target_index", targetIdx,
-		)
-		goto end
-	}
-
 	if kind != '[' {
 		err = s.enrichError(
 			ErrJSONPathTraversalFailed,
 			ErrJSONPathExpectedArrayAtSegment,
 			"expected_type", "array",
-			"actual_type", kind.String(),
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", s.valuePreview(s.unreadInput()),
 		)
 		goto end
 	}
@@ -79,12 +214,33 @@ func (s *extractState) navigateArrayIndex(targetIdx int) (err error) {
 		)
 		goto end
 	}
+	arrayStart = s.inputOffset() - 1
+
+	// Negative indexes count back from the end of the array
+	if targetIdx < 0 {
+		err = s.navigateFromEnd(targetIdx, arrayStart)
+		goto end
+	}
+
+	// Each element ahead of the target is parsed in full to skip it, so the
+	// cost grows with the size of those elements rather than their number
+	if s.opts.fastSkip {
+		var ok bool
+		ok, err = s.navigateFastSkip(targetIdx, arrayStart)
+		if ok {
+			goto end
+		}
+	}
 
 	// Skip elements until we reach the target index
 	currentIdx = 0
 	for currentIdx < targetIdx {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
 		if s.decoder.PeekKind() == ']' {
-			err = s.enrichError(
+			err = s.enrichErrorAt(arrayStart,
 				ErrJSONPathTraversalFailed,
 				ErrJSONIndexOutOfRange,
 				"target_index", targetIdx,
@@ -94,12 +250,7 @@ func (s *extractState) navigateArrayIndex(targetIdx int) (err error) {
 		}
 		err = s.decoder.SkipValue()
 		if err != nil {
-			err = s.enrichError(
-				ErrJSONPathTraversalFailed,
-				ErrJSONTokenReadFailed,
-				"skip_index", currentIdx,
-				err,
-			)
+			err = s.skipFailed(err, "skip_index", currentIdx)
 			goto end
 		}
 		currentIdx++
@@ -107,7 +258,7 @@ func (s *extractState) navigateArrayIndex(targetIdx int) (err error) {
 
 	// Check if we're at the end of array before target index
 	if s.decoder.PeekKind() == ']' {
-		err = s.enrichError(
+		err = s.enrichErrorAt(arrayStart,
 			ErrJSONPathTraversalFailed,
 			ErrJSONIndexOutOfRange,
 			"target_index", targetIdx,
@@ -119,9 +270,171 @@ end:
 	return err
 }
 
+// navigateFilter positions the decoder at the first array element matching
+// filter. Each element is read on its own so it can be tested, and the decoder
+// is replaced by one reading the match.
+func (s *extractState) navigateFilter(filter *elementFilter) (err error) {
+	var value jsontext.Value
+	var arrayStart int64
+	var idx int
+
+	kind := s.decoder.PeekKind()
+	if kind != '[' {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedArrayAtSegment,
+			"expected_type", "array",
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", s.valuePreview(s.unreadInput()),
+		)
+		goto end
+	}
+
+	// Read array start token '['
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "array_start",
+			err,
+		)
+		goto end
+	}
+	arrayStart = s.inputOffset() - 1
+
+	for ; s.decoder.PeekKind() != ']'; idx++ {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
+		value, err = s.decoder.ReadValue()
+		if err != nil {
+			err = s.skipFailed(err, "skip_index", idx)
+			goto end
+		}
+		if filter.matches(value) {
+			s.elementIndex = idx
+			s.replaceDecoder(value, s.inputOffset()-int64(len(value)))
+			goto end
+		}
+	}
+
+	err = s.enrichErrorAt(arrayStart,
+		ErrJSONPathTraversalFailed,
+		ErrJSONSelectorNotFound,
+		"array_length", idx,
+	)
+end:
+	return err
+}
+
+// navigateFromEnd handles negative array indexes, where -1 is the last element.
+// The array length isn't known until its end is reached, so only the trailing
+// elements are buffered and the decoder is replaced by one reading the target.
+func (s *extractState) navigateFromEnd(targetIdx int, arrayStart int64) (err error) {
+	var value jsontext.Value
+	var offset int64
+	var length int
+
+	trailing := newTrailingValues(trailingCount(targetIdx))
+	for s.decoder.PeekKind() != ']' {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
+		value, err = s.decoder.ReadValue()
+		if err != nil {
+			err = s.skipFailed(err, "skip_index", length)
+			goto end
+		}
+		trailing.add(value, s.inputOffset()-int64(len(value)))
+		length++
+	}
+
+	value, offset, err = trailing.fromEnd(targetIdx)
+	if err != nil {
+		err = s.enrichErrorAt(arrayStart,
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_index", targetIdx,
+			"array_length", length,
+		)
+		goto end
+	}
+
+	s.elementIndex = length + targetIdx
+	s.replaceDecoder(value, offset)
+end:
+	return err
+}
+
 // navigateObjectKey handles object key navigation
 func (s *extractState) navigateObjectKey(targetKey string) (err error) {
+	var key string
 	var availableKeys []string
+	var found bool
+
+	key, availableKeys, found, err = s.navigateMember(func(_ int, key string) bool {
+		return s.opts.keyMatches(key, targetKey)
+	})
+	if err != nil {
+		goto end
+	}
+
+	if !found {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathSegmentNotFound,
+			"missing_key", targetKey,
+			"available_keys", availableKeys,
+		)
+		goto end
+	}
+
+	s.matchedKey = key
+
+	if s.opts.scansWholeObjects() {
+		err = s.navigateUniqueKey(key, targetKey)
+	}
+end:
+	return err
+}
+
+// navigateObjectOrdinal positions the decoder at the value of the object
+// member at zero-based position ordinal in document order.
+func (s *extractState) navigateObjectOrdinal(ordinal int) (err error) {
+	var key string
+	var availableKeys []string
+	var found bool
+
+	key, availableKeys, found, err = s.navigateMember(func(position int, _ string) bool {
+		return position == ordinal
+	})
+	if err != nil {
+		goto end
+	}
+
+	if !found {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_ordinal", ordinal,
+			"object_length", len(availableKeys),
+			"available_keys", availableKeys,
+		)
+		goto end
+	}
+	s.ordinalKey = key
+end:
+	return err
+}
+
+// navigateMember reads the object the decoder is positioned at up to the
+// value of the first member for which matches reports true, given the
+// member's position and key, and returns that key along with the keys read.
+// When no member matches the whole object is read and found is false.
+func (s *extractState) navigateMember(matches func(position int, key string) bool) (key string, availableKeys []string, found bool, err error) {
 	var keyToken jsontext.Token
 
 	kind := jsontext.Kind(s.decoder.PeekKind())
@@ -131,7 +444,8 @@ func (s *extractState) navigateObjectKey(targetKey string) (err error) {
 			ErrJSONPathTraversalFailed,
 			ErrJSONPathExpectedObjectAtSegment,
 			"expected_type", "object",
-			"actual_type", kind.String(),
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", s.valuePreview(s.unreadInput()),
 		)
 		goto end
 	}
@@ -151,8 +465,13 @@ func (s *extractState) navigateObjectKey(targetKey string) (err error) {
 	// Collect available keys for error context
 	availableKeys = make([]string, 0)
 
-	// Search for the target key
+	// Search for the target member
 	for s.decoder.PeekKind() != '}' {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
+
 		// Read the key
 		keyToken, err = s.decoder.ReadToken()
 		if err != nil {
@@ -165,57 +484,184 @@ func (s *extractState) navigateObjectKey(targetKey string) (err error) {
 			goto end
 		}
 
-		key := keyToken.String()
-		// Remove quotes from key
-		if len(key) >= 2 && key[0] == '"' && key[len(key)-1] == '"' {
-			key = key[1 : len(key)-1]
+		key = keyToken.String()
+		if matches(len(availableKeys), key) {
+			// Found the target member, the value is next
+			found = true
+			goto end
 		}
 		availableKeys = append(availableKeys, key)
 
-		if key == targetKey {
-			// Found the target key, the value is next
+		// Skip the value for this key
+		err = s.decoder.SkipValue()
+		if err != nil {
+			err = s.skipFailed(err, "skipping_key", key)
 			goto end
 		}
+	}
+	key = ""
+end:
+	return key, availableKeys, found, err
+}
 
-		// Skip the value for this key
-		err = s.decoder.SkipValue()
+// navigateUniqueKey is called with the decoder positioned at the value of key,
+// the first key matching targetKey. It reads the rest of the object to ensure
+// no other key also matches, which also lets the decoder detect a duplicate of
+// any key, then replaces the decoder with one reading the value of key.
+func (s *extractState) navigateUniqueKey(key, targetKey string) (err error) {
+	var value jsontext.Value
+	var keyToken jsontext.Token
+	var offset int64
+
+	value, err = s.decoder.ReadValue()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"reading", "member_value",
+			"member", key,
+			err,
+		)
+		goto end
+	}
+	value = value.Clone()
+	offset = s.inputOffset() - int64(len(value))
+
+	for s.decoder.PeekKind() != '}' {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
+		keyToken, err = s.decoder.ReadToken()
 		if err != nil {
 			err = s.enrichError(
 				ErrJSONPathTraversalFailed,
 				ErrJSONTokenReadFailed,
-				"skipping_key", key,
+				"reading", "object_key",
 				err,
 			)
 			goto end
 		}
+
+		other := keyToken.String()
+		if s.opts.keyMatches(other, targetKey) {
+			err = s.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONAmbiguousKey,
+				"matching_keys", []string{key, other},
+			)
+			goto end
+		}
+
+		err = s.decoder.SkipValue()
+		if err != nil {
+			err = s.skipFailed(err, "skipping_key", other)
+			goto end
+		}
 	}
 
-	// Key not found
-	err = s.enrichError(
-		ErrJSONPathTraversalFailed,
-		ErrJSONPathSegmentNotFound,
-		"missing_key", targetKey,
-		"available_keys", availableKeys,
-	)
+	s.replaceDecoder(value, offset)
 end:
 	return err
 }
 
+// replaceDecoder replaces the decoder with one reading value, a value found at
+// offset within rawBytes while at the current nesting depth.
+func (s *extractState) replaceDecoder(value jsontext.Value, offset int64) {
+	s.baseDepth = s.depth()
+	s.decoder = s.opts.newNavigationDecoder(bytes.NewReader(value))
+	s.baseOffset = offset
+}
+
+// depth returns the number of objects and arrays the decoder is within,
+// counted from the document root.
+func (s *extractState) depth() int {
+	return s.baseDepth + s.decoder.StackDepth()
+}
+
+// checkDepth reports whether navigation has gone deeper than WithMaxDepth
+// allows.
+func (s *extractState) checkDepth() (err error) {
+	if s.opts.maxDepth > 0 && s.depth() > s.opts.maxDepth {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONMaxDepthExceeded,
+			"max_depth", s.opts.maxDepth,
+			"depth", s.depth(),
+		)
+	}
+	return err
+}
+
+// checkValueDepth reports whether value, read at the current nesting depth,
+// nests deeper than WithMaxDepth allows. The value must be well-formed.
+func (s *extractState) checkValueDepth(value jsontext.Value) (err error) {
+	var depth int
+
+	base := s.depth()
+	start := s.inputOffset() - int64(len(value))
+	decoder := jsontext.NewDecoder(bytes.NewReader(value))
+	for {
+		_, err = decoder.ReadToken()
+		if err != nil {
+			// The value is well-formed, so this is its end
+			err = nil
+			goto end
+		}
+		depth = base + decoder.StackDepth()
+		if depth > s.opts.maxDepth {
+			err = s.enrichErrorAt(start+decoder.InputOffset()-1,
+				ErrJSONStreamingParseFailed,
+				ErrJSONMaxDepthExceeded,
+				"max_depth", s.opts.maxDepth,
+				"depth", depth,
+			)
+			goto end
+		}
+	}
+
+end:
+	return err
+}
+
+// raw returns the JSON available for error context: rawBytes when the whole
+// input is held in memory, otherwise whatever a streaming read has consumed.
+func (s *extractState) raw() []byte {
+	if s.rawBytes == nil && s.consumed != nil {
+		return s.consumed.Bytes()
+	}
+	return s.rawBytes
+}
+
+// defaultErrorJSONMaxLen bounds condensedJSON unless WithErrorJSONMaxLen is used.
+const defaultErrorJSONMaxLen = 200
+
+// truncatedMarker ends condensed JSON that has been cut short.
+const truncatedMarker = "...[more]"
+
 // condensedJSON formats JSON in an easily comprehensible way
 // that helps developers quickly locate and fix API configuration errors
 func (s *extractState) condensedJSON() string {
 	var formatted string
 	var jsonStr string
+	var raw []byte
+
+	maxLen := s.opts.errorJSONLimit()
 
-	if len(s.rawBytes) == 0 {
+	raw = s.raw()
+	if len(raw) == 0 {
 		formatted = "JSON not available"
 		goto end
 	}
 
-	jsonStr = string(s.rawBytes)
+	// Secrets are removed before anything else so no later step can leak them
+	if len(s.opts.errorJSONRedactKeys) > 0 {
+		raw = redactJSON(raw, s.opts.errorJSONRedactKeys)
+	}
+	jsonStr = string(raw)
 
 	// For empty or very short JSON, return as-is
-	if len(jsonStr) <= 100 {
+	if len(jsonStr) <= min(100, maxLen) {
 		formatted = jsonStr
 		goto end
 	}
@@ -230,14 +676,132 @@ func (s *extractState) condensedJSON() string {
 	}
 
 	// If still too long, intelligently truncate at JSON boundaries
-	if len(formatted) > 200 {
-		formatted = s.truncateAtJSONBoundary(formatted, 200)
+	if len(formatted) > maxLen {
+		formatted = s.truncateAtJSONBoundary(formatted, maxLen)
 	}
 
 end:
 	return formatted
 }
 
+// errorWindow returns the raw JSON around offset, spanning at most half the
+// error JSON limit with the failure near its middle, along with the position of
+// offset within it. Tabs and line breaks become spaces so the window stays on
+// one line without shifting any byte. The window is empty when no raw JSON is
+// at hand.
+func (s *extractState) errorWindow(offset int64) (window string, pos int) {
+	var raw []byte
+	var start, end int
+
+	raw = s.raw()
+	if offset < 0 || len(raw) == 0 {
+		goto end
+	}
+	pos = int(min(offset, int64(len(raw))))
+
+	// Secrets are removed before anything else so no later step can leak them
+	if len(s.opts.errorJSONRedactKeys) > 0 {
+		raw, pos = redactJSONAt(raw, s.opts.errorJSONRedactKeys, pos)
+	}
+
+	start = max(pos-s.opts.errorJSONLimit()/4, 0)
+	end = min(start+max(s.opts.errorJSONLimit()/2, 1), len(raw))
+	for start < pos && !utf8.RuneStart(raw[start]) {
+		start++
+	}
+	for end > pos && end < len(raw) && !utf8.RuneStart(raw[end]) {
+		end--
+	}
+	window = strings.Map(func(r rune) rune {
+		switch r {
+		case '\n', '\r', '\t':
+			r = ' '
+		}
+		return r
+	}, string(raw[start:end]))
+	pos -= start
+
+end:
+	return window, pos
+}
+
+// valuePreviewLen bounds the preview of a value of an unexpected type.
+const valuePreviewLen = 60
+
+// unreadInput returns the input from the decoder's position onward, as far as
+// it has been read or is held in memory. Without the input at hand only a
+// Decoder that buffers it, as jsontext.Decoder does, can say.
+func (s *extractState) unreadInput() (unread []byte) {
+	raw := s.raw()
+	offset := s.inputOffset()
+	if offset >= 0 && offset < int64(len(raw)) {
+		unread = raw[offset:]
+		goto end
+	}
+	if buffered, ok := s.decoder.(interface{ UnreadBuffer() []byte }); ok {
+		unread = buffered.UnreadBuffer()
+	}
+
+end:
+	return unread
+}
+
+// valuePreview returns previewValue for the value starting raw, which was
+// reached by the path navigated so far.
+func (s *extractState) valuePreview(raw []byte) string {
+	var key string
+	if len(s.pathProgress) > 0 {
+		key = s.pathProgress[len(s.pathProgress)-1]
+	}
+	return previewValue(raw, key, s.opts)
+}
+
+// previewValue returns up to about valuePreviewLen bytes of the JSON value
+// starting raw, after any whitespace and a leftover ':' or ',', to show what
+// was found where a value of another type was expected. key names the member
+// holding the value, so that a value WithErrorJSONRedactKeys covers is shown
+// redacted, as are members nested within it. When streaming, raw may hold
+// only the start of the value, or none of it, in which case the preview is
+// likewise cut short or empty.
+func previewValue(raw []byte, key string, opts options) (preview string) {
+	var start, end int
+
+	start = skipJSONSpace(raw, 0)
+	if start < len(raw) && (raw[start] == ':' || raw[start] == ',') {
+		start = skipJSONSpace(raw, start+1)
+	}
+	if start >= len(raw) {
+		goto end
+	}
+	if slices.Contains(opts.errorJSONRedactKeys, key) {
+		preview = redactedValue
+		goto end
+	}
+
+	end = skipJSONValue(raw, start)
+	raw = raw[start:end]
+	if len(opts.errorJSONRedactKeys) > 0 {
+		raw = redactJSON(raw, opts.errorJSONRedactKeys)
+	}
+	if len(raw) > valuePreviewLen {
+		end = valuePreviewLen
+		for end > 0 && !utf8.RuneStart(raw[end]) {
+			end--
+		}
+		raw = append(raw[:end:end], truncatedMarker...)
+	}
+	preview = strings.Map(func(r rune) rune {
+		switch r {
+		case '\n', '\r', '\t':
+			r = ' '
+		}
+		return r
+	}, string(raw))
+
+end:
+	return preview
+}
+
 // truncateAtJSONBoundary truncates at logical JSON structure points
 func (s *extractState) truncateAtJSONBoundary(jsonStr string, maxLen int) string {
 	var result string
@@ -249,6 +813,12 @@ func (s *extractState) truncateAtJSONBoundary(jsonStr string, maxLen int) string
 		goto end
 	}
 
+	// Too short to hold the marker along with any of the JSON
+	if maxLen <= len(truncatedMarker) {
+		result = jsonStr[:maxLen]
+		goto end
+	}
+
 	// Try to truncate at object or array boundaries for readability
 	truncated = jsonStr[:maxLen-10] // Leave room for "...[more]"
 
@@ -266,24 +836,49 @@ func (s *extractState) truncateAtJSONBoundary(jsonStr string, maxLen int) string
 	}
 
 	if cutPoint > 50 { // Ensure we don't cut too early
-		result = jsonStr[:cutPoint] + "...[more]"
+		result = jsonStr[:cutPoint] + truncatedMarker
 		goto end
 	}
 
 	// Fallback to simple truncation
-	result = jsonStr[:maxLen-10] + "...[more]"
+	result = jsonStr[:maxLen-10] + truncatedMarker
 
 end:
 	return result
 }
 
+// inputOffset returns the offset within rawBytes just past the most recently
+// read token or value, or -1 when the state has no decoder.
+func (s *extractState) inputOffset() int64 {
+	if s.decoder == nil {
+		return -1
+	}
+	return s.baseOffset + s.decoder.InputOffset()
+}
+
+// lineColumn returns the 1-based line and byte column of offset in rawBytes.
+func (s *extractState) lineColumn(offset int64) (line, column int) {
+	raw := s.raw()
+	prefix := raw[:min(offset, int64(len(raw)))]
+	line = bytes.Count(prefix, []byte{'\n'}) + 1
+	column = len(prefix) - bytes.LastIndexByte(prefix, '\n')
+	return line, column
+}
+
 // enrichError takes sentinel errors and/or key-value pairs, adds state-specific
-// context metadata, and optionally joins with a trailing cause error.
+// context metadata, and optionally joins with a trailing cause error. The error
+// is located at the decoder's current input offset.
 // Usage patterns:
 //   - s.enrichError(ErrSentinel1, ErrSentinel2, "key", value)
 //   - s.enrichError(ErrSentinel, "key", value, causeErr)
 //   - s.enrichError(nil, ErrSentinel1, ErrSentinel2, "key", value)
 func (s *extractState) enrichError(parts ...any) error {
+	return s.enrichErrorAt(s.inputOffset(), parts...)
+}
+
+// enrichErrorAt is enrichError for a failure located at byte offset within
+// rawBytes, or at no known location when offset is negative.
+func (s *extractState) enrichErrorAt(offset int64, parts ...any) error {
 	// Build a parts list: sentinels, then state context KVs, then remaining parts
 	var allParts []any
 
@@ -297,17 +892,23 @@ func (s *extractState) enrichError(parts ...any) error {
 		}
 	}
 
-	// Start with the sentinels
+	// Start with the sentinels, followed by the typed path error
 	allParts = append(allParts, parts[:sentinelCount]...)
+	duplicate, isDuplicate := duplicateKey(parts[sentinelCount:])
+	if isDuplicate {
+		allParts = append(allParts, ErrJSONDuplicateKey)
+	}
+	pathErr := s.pathError(offset, parts[sentinelCount:])
+	allParts = append(allParts, pathErr)
 
 	// Add state-specific context metadata
 	allParts = append(allParts,
 		"json_path", s.selector,
 	)
 
-	if s.position < len(s.segments) {
+	if s.position >= 0 && s.position < len(s.segments) {
 		allParts = append(allParts,
-			"segment", s.segments[s.position],
+			"segment", s.segments[s.position].text,
 			"segment_position", s.position,
 		)
 	}
@@ -316,6 +917,34 @@ func (s *extractState) enrichError(parts ...any) error {
 		allParts = append(allParts, "path_progress", s.pathProgress)
 	}
 
+	if offset >= 0 {
+		allParts = append(allParts, "byte_offset", offset)
+	}
+
+	// Lines and columns can only be counted when the raw JSON is at hand
+	if offset >= 0 && len(s.raw()) > 0 {
+		line, column := s.lineColumn(offset)
+		allParts = append(allParts,
+			"line", line,
+			"column", column,
+		)
+	}
+
+	if pathErr.Window != "" {
+		allParts = append(allParts,
+			"json_window", pathErr.Window,
+			"json_window_pos", pathErr.WindowPos,
+		)
+	}
+
+	if isDuplicate {
+		allParts = append(allParts, "duplicate_key", duplicate)
+	}
+
+	if pathErr.Suggestion != "" {
+		allParts = append(allParts, "suggestion", pathErr.Suggestion)
+	}
+
 	// Include readable JSON context for debugging
 	allParts = append(allParts, "condensed_json", s.condensedJSON())
 
@@ -324,3 +953,74 @@ func (s *extractState) enrichError(parts ...any) error {
 
 	return NewErr(allParts...)
 }
+
+// duplicateKey returns the duplicated key when the trailing cause in parts is
+// the decoder rejecting a duplicate object key.
+func duplicateKey(parts []any) (key string, ok bool) {
+	var cause error
+	var syntaxErr *jsontext.SyntacticError
+
+	if len(parts) == 0 || len(parts)%2 == 0 {
+		goto end
+	}
+	cause, ok = parts[len(parts)-1].(error)
+	if !ok {
+		goto end
+	}
+	ok = errors.As(cause, &syntaxErr) && errors.Is(syntaxErr.Err, jsontext.ErrDuplicateName)
+	if ok {
+		key = syntaxErr.JSONPointer.LastToken()
+	}
+end:
+	return key, ok
+}
+
+// trailingValues retains copies of the last size values added to it, which is
+// all that is needed to resolve a negative index once an array has ended.
+type trailingValues struct {
+	values  []jsontext.Value
+	offsets []int64
+	size    int
+	count   int
+}
+
+func newTrailingValues(size int) *trailingValues {
+	return &trailingValues{size: size}
+}
+
+// trailingCount returns how many trailing elements must be buffered to reach
+// negative index idx. math.MinInt has no positive counterpart, so it is capped
+// at math.MaxInt, which no array reaches either.
+func trailingCount(idx int) int {
+	if idx == math.MinInt {
+		return math.MaxInt
+	}
+	return -idx
+}
+
+// add retains a copy of value, read from the given input offset, evicting the
+// oldest value once full.
+func (tv *trailingValues) add(value jsontext.Value, offset int64) {
+	if len(tv.values) < tv.size {
+		tv.values = append(tv.values, value.Clone())
+		tv.offsets = append(tv.offsets, offset)
+	} else {
+		tv.values[tv.count%tv.size] = value.Clone()
+		tv.offsets[tv.count%tv.size] = offset
+	}
+	tv.count++
+}
+
+// fromEnd returns the value at negative index idx, where -1 is the most
+// recently added value, along with the input offset it was read from.
+func (tv *trailingValues) fromEnd(idx int) (value jsontext.Value, offset int64, err error) {
+	pos := tv.count + idx
+	if idx >= 0 || idx < -tv.size || pos < 0 {
+		err = ErrJSONIndexOutOfRange
+		goto end
+	}
+	value = tv.values[pos%tv.size]
+	offset = tv.offsets[pos%tv.size]
+end:
+	return value, offset, err
+}
@@ -0,0 +1,46 @@
+package jsonxtractr
+
+import (
+	"io"
+	"slices"
+)
+
+// Extractor applies the options it was built with to every extraction, for
+// code that uses the same configuration throughout, e.g. a service that has
+// one injected. Its methods behave as the package functions with an Opts
+// suffix. An Extractor is immutable once built, so it's safe for concurrent
+// use. The zero value applies no options.
+type Extractor struct {
+	opts []Option
+}
+
+// NewExtractor returns an Extractor that applies opts to every extraction.
+func NewExtractor(opts ...Option) *Extractor {
+	return &Extractor{opts: slices.Clone(opts)}
+}
+
+// Value is ExtractValueFromBytesOpts with the Extractor's options.
+func (x *Extractor) Value(jsonBytes []byte, selector Selector) (any, error) {
+	return ExtractValueFromBytesOpts(jsonBytes, selector, x.opts...)
+}
+
+// Values is ExtractValuesFromBytesOpts with the Extractor's options.
+func (x *Extractor) Values(jsonBytes []byte, selectors []Selector) (ValuesMap, []Selector, error) {
+	return ExtractValuesFromBytesOpts(jsonBytes, selectors, x.opts...)
+}
+
+// ValueFromReader is ExtractValueFromReaderOpts with the Extractor's options.
+func (x *Extractor) ValueFromReader(reader io.Reader, selector Selector) (any, error) {
+	return ExtractValueFromReaderOpts(reader, selector, x.opts...)
+}
+
+// ValuesFromReader is ExtractValuesFromReaderOpts with the Extractor's
+// options.
+func (x *Extractor) ValuesFromReader(reader io.Reader, selectors []Selector) (ValuesMap, []Selector, error) {
+	return ExtractValuesFromReaderOpts(reader, selectors, x.opts...)
+}
+
+// Matches is ExtractMatchesOpts with the Extractor's options.
+func (x *Extractor) Matches(jsonBytes []byte, selector Selector) ([]Match, error) {
+	return ExtractMatchesOpts(jsonBytes, selector, x.opts...)
+}
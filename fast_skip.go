@@ -0,0 +1,134 @@
+package jsonxtractr
+
+// navigateFastSkip positions the decoder at the element targetIdx of the array
+// whose '[' was just read, finding it by scanning rawBytes for brackets,
+// strings and commas instead of decoding the elements ahead of it. It reports
+// ok as false, leaving the decoder untouched, when rawBytes isn't held or the
+// scan meets something it can't make sense of, so the caller can fall back to
+// skipping with the decoder, which reports the error properly.
+func (s *extractState) navigateFastSkip(targetIdx int, arrayStart int64) (ok bool, err error) {
+	var pos, end, skipped int
+
+	if s.rawBytes == nil {
+		goto end
+	}
+
+	pos = int(s.inputOffset())
+	for skipped < targetIdx {
+		pos = skipJSONSpace(s.rawBytes, pos)
+		if pos < len(s.rawBytes) && s.rawBytes[pos] == ']' {
+			break
+		}
+		end = scanElementEnd(s.rawBytes, pos)
+		if end <= pos {
+			// Unterminated, mismatched or empty, as in "[1,,2]"
+			goto end
+		}
+		pos = end
+		skipped++
+		if s.rawBytes[pos] == ',' {
+			pos++
+		}
+	}
+
+	pos = skipJSONSpace(s.rawBytes, pos)
+	if pos >= len(s.rawBytes) {
+		goto end
+	}
+	ok = true
+	if s.rawBytes[pos] == ']' {
+		err = s.enrichErrorAt(arrayStart,
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_index", targetIdx,
+			"array_length", skipped,
+		)
+		goto end
+	}
+
+	end = scanElementEnd(s.rawBytes, pos)
+	if end <= pos {
+		// The target itself is malformed, which the decoder reports
+		ok = false
+		goto end
+	}
+	end = trimJSONSpaceRight(s.rawBytes, pos, end)
+	s.replaceDecoder(s.rawBytes[pos:end], int64(pos))
+
+end:
+	return ok, err
+}
+
+// scanElementEnd returns the offset of the ',' or ']' ending the array element
+// that starts at pos in data, matching brackets and skipping strings but
+// otherwise not validating the element. It returns -1 if data ends first or
+// brackets don't match.
+func scanElementEnd(data []byte, pos int) (end int) {
+	var stack []byte
+	var inString bool
+
+	end = -1
+	for ; pos < len(data); pos++ {
+		c := data[pos]
+		if !scanSignificant[c] {
+			continue
+		}
+		if inString {
+			switch c {
+			case '\\':
+				pos++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) == 0 {
+				if c == ']' {
+					end = pos
+				}
+				goto end
+			}
+			if stack[len(stack)-1] != c {
+				goto end
+			}
+			stack = stack[:len(stack)-1]
+		case ',':
+			if len(stack) == 0 {
+				end = pos
+				goto end
+			}
+		}
+	}
+
+end:
+	return end
+}
+
+// scanSignificant marks the bytes scanElementEnd must look at, so every other
+// byte is passed over with a single lookup.
+var scanSignificant = [256]bool{
+	'"': true, '\\': true, '{': true, '}': true, '[': true, ']': true, ',': true,
+}
+
+// trimJSONSpaceRight returns end moved back over any JSON whitespace, but not
+// before start.
+func trimJSONSpaceRight(data []byte, start, end int) int {
+	for end > start {
+		switch data[end-1] {
+		case ' ', '\t', '\r', '\n':
+			end--
+		default:
+			return end
+		}
+	}
+	return end
+}
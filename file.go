@@ -0,0 +1,56 @@
+package jsonxtractr
+
+import (
+	"os"
+)
+
+// ExtractValueFromFile extracts a single value from the JSON file at path,
+// streaming it through ExtractValueFromReader rather than reading it whole.
+// Failing to open the file returns an error wrapping ErrJSONFileReadFailed.
+func ExtractValueFromFile(path string, selector Selector) (value any, err error) {
+	var file *os.File
+
+	file, err = openJSONFile(path)
+	if err != nil {
+		goto end
+	}
+	value, err = ExtractValueFromReaderOpts(file, selector, WithCloseReader())
+	if err != nil {
+		err = WithErr(err, "path", path)
+	}
+
+end:
+	return value, err
+}
+
+// ExtractValuesFromFile extracts multiple values from the JSON file at path as
+// ExtractValuesFromReader does. Failing to open the file returns an error
+// wrapping ErrJSONFileReadFailed.
+func ExtractValuesFromFile(path string, selectors []Selector) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var file *os.File
+
+	file, err = openJSONFile(path)
+	if err != nil {
+		goto end
+	}
+	valuesMap, notFound, err = ExtractValuesFromReaderOpts(file, selectors, WithCloseReader())
+	if err != nil {
+		err = WithErr(err, "path", path)
+	}
+
+end:
+	return valuesMap, notFound, err
+}
+
+// openJSONFile opens the file at path for reading.
+func openJSONFile(path string) (file *os.File, err error) {
+	file, err = os.Open(path)
+	if err != nil {
+		err = NewErr(
+			ErrJSONFileReadFailed,
+			"path", path,
+			err,
+		)
+	}
+	return file, err
+}
@@ -0,0 +1,240 @@
+package jsonxtractr
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// filterPrefix begins a filter segment, e.g. `#(role=="admin")`.
+const filterPrefix = "#("
+
+// filterOperators lists the comparison operators a filter accepts.
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// elementFilter is the parsed condition of a filter segment, comparing the
+// member named key of each array element against a string or number literal.
+type elementFilter struct {
+	key    string
+	op     string
+	text   string  // string literal, when isText
+	number float64 // number literal, when not isText
+	isText bool
+}
+
+// filterAllSuffix follows a filter that selects every matching element
+// rather than the first, e.g. `#(role=="admin")#`.
+const filterAllSuffix = '#'
+
+// parseFilterSegment parses a filter segment such as `#(age>=21)` or
+// `#(age>=21)#` whose "#(" is at byte offset pos. String literals are JSON
+// strings and may contain ')' or '.', which don't end the segment.
+func parseFilterSegment(selector string, pos int) (seg segment, next int, err error) {
+	var filter *elementFilter
+	var inString bool
+
+	for next = pos + len(filterPrefix); next < len(selector); next++ {
+		c := selector[next]
+		if inString && c == '\\' {
+			next++
+			continue
+		}
+		if c == '"' {
+			inString = !inString
+			continue
+		}
+		if c == ')' && !inString {
+			break
+		}
+	}
+
+	if next >= len(selector) {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorFilterInvalid,
+			"selector", selector,
+			"filter_offset", pos,
+			"reason", "missing closing ')'",
+		)
+		goto end
+	}
+
+	filter, err = parseElementFilter(selector[pos+len(filterPrefix) : next])
+	if err != nil {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorFilterInvalid,
+			"selector", selector,
+			"filter_offset", pos,
+			err,
+		)
+		goto end
+	}
+
+	// Step past the closing parenthesis and any '#', which must end the segment
+	seg = segment{kind: filterSegment, filter: filter}
+	next++
+	if next < len(selector) && selector[next] == filterAllSuffix {
+		seg.kind = filterAllSegment
+		next++
+	}
+	if next < len(selector) && selector[next] != '.' {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorFilterInvalid,
+			"selector", selector,
+			"offset", next,
+			"reason", "expected '.' after filter",
+		)
+		seg = segment{}
+		goto end
+	}
+	seg.text = selector[pos:next]
+
+end:
+	return seg, next, err
+}
+
+// parseElementFilter parses a condition of the form <key><op><literal>, where
+// the literal is a JSON string or a finite number.
+func parseElementFilter(condition string) (filter *elementFilter, err error) {
+	var literal []byte
+	var opPos int
+
+	filter = &elementFilter{}
+	opPos = strings.IndexAny(condition, "=!<>")
+	if opPos < 0 {
+		err = NewErr(
+			ErrJSONSelectorFilterInvalid,
+			"reason", "missing comparison operator",
+		)
+		goto end
+	}
+	for _, op := range filterOperators {
+		if strings.HasPrefix(condition[opPos:], op) {
+			filter.op = op
+			break
+		}
+	}
+	if filter.op == "" {
+		err = NewErr(
+			ErrJSONSelectorFilterInvalid,
+			"reason", "unknown comparison operator",
+			"operator", condition[opPos:min(opPos+2, len(condition))],
+		)
+		goto end
+	}
+
+	filter.key = strings.TrimSpace(condition[:opPos])
+	if filter.key == "" {
+		err = NewErr(
+			ErrJSONSelectorFilterInvalid,
+			"reason", "missing key",
+		)
+		goto end
+	}
+
+	literal = []byte(strings.TrimSpace(condition[opPos+len(filter.op):]))
+	if len(literal) > 0 && literal[0] == '"' {
+		literal, err = jsontext.AppendUnquote(nil, literal)
+		if err != nil {
+			err = NewErr(
+				ErrJSONSelectorFilterInvalid,
+				"reason", "invalid string literal",
+				err,
+			)
+			goto end
+		}
+		filter.text = string(literal)
+		filter.isText = true
+		goto end
+	}
+
+	filter.number, err = strconv.ParseFloat(string(literal), 64)
+	if err != nil || math.IsInf(filter.number, 0) || math.IsNaN(filter.number) {
+		err = NewErr(
+			ErrJSONSelectorFilterInvalid,
+			"reason", "literal must be a JSON string or number",
+			"literal", string(literal),
+		)
+		goto end
+	}
+
+end:
+	if err != nil {
+		filter = nil
+	}
+	return filter, err
+}
+
+// matches reports whether element, the raw JSON of one array element, is an
+// object whose member named key compares true against the literal. Elements
+// that aren't objects, lack the key, or hold a value of the other type don't
+// match.
+func (f *elementFilter) matches(element jsontext.Value) (matches bool) {
+	var members map[string]jsontext.Value
+	var member jsontext.Value
+	var text string
+	var number float64
+	var ok bool
+	var cmp int
+
+	if element.Kind() != '{' {
+		goto end
+	}
+	if jsonv2.Unmarshal(element, &members) != nil {
+		goto end
+	}
+	member, ok = members[f.key]
+	if !ok {
+		goto end
+	}
+
+	switch {
+	case f.isText && member.Kind() == '"':
+		if jsonv2.Unmarshal(member, &text) != nil {
+			goto end
+		}
+		cmp = strings.Compare(text, f.text)
+	case !f.isText && member.Kind() == '0':
+		if jsonv2.Unmarshal(member, &number) != nil {
+			goto end
+		}
+		cmp = compareFloats(number, f.number)
+	default:
+		// Comparing across types never matches
+		goto end
+	}
+
+	switch f.op {
+	case "==":
+		matches = cmp == 0
+	case "!=":
+		matches = cmp != 0
+	case "<":
+		matches = cmp < 0
+	case "<=":
+		matches = cmp <= 0
+	case ">":
+		matches = cmp > 0
+	case ">=":
+		matches = cmp >= 0
+	}
+
+end:
+	return matches
+}
+
+// compareFloats returns -1, 0 or +1 as a is less than, equal to or greater
+// than b.
+func compareFloats(a, b float64) (cmp int) {
+	switch {
+	case a < b:
+		cmp = -1
+	case a > b:
+		cmp = 1
+	}
+	return cmp
+}
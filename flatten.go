@@ -0,0 +1,126 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"strconv"
+)
+
+// Flatten returns every scalar leaf of the value selector resolves to in JSON
+// bytes, keyed by its full path from the document root. Array elements use
+// their index as a segment, e.g. "items.0.name", and keys are quoted where
+// needed, so each key selects its leaf again via ExtractValueFromBytes. Empty
+// objects and arrays have no leaves and are omitted. RootSelector flattens
+// the whole document.
+func Flatten(jsonBytes []byte, selector Selector) (leaves map[Selector]any, err error) {
+	var state *extractState
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	leaves = make(map[Selector]any)
+	err = state.flattenValue(state.segments[:len(state.segments):len(state.segments)], leaves)
+
+end:
+	if err != nil {
+		leaves = nil
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return leaves, err
+}
+
+// flattenValue adds every scalar leaf of the value the decoder is positioned
+// at to leaves, keyed by path extended with the leaf's own segments.
+func (s *extractState) flattenValue(path []segment, leaves map[Selector]any) (err error) {
+	var keyToken jsontext.Token
+	var value any
+	var closing jsontext.Kind
+	var idx int
+
+	kind := s.decoder.PeekKind()
+	switch kind {
+	case '{':
+		closing = '}'
+	case '[':
+		closing = ']'
+	default:
+		err = s.decoder.UnmarshalInto(&value, s.opts.unmarshalOptions())
+		if err != nil {
+			err = s.enrichError(
+				ErrJSONStreamingParseFailed,
+				ErrJSONUnmarshalFailed,
+				"leaf", formatSelector(path),
+				err,
+			)
+			goto end
+		}
+		leaves[formatSelector(path)] = value
+		goto end
+	}
+
+	// Read container start token
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "container_start",
+			err,
+		)
+		goto end
+	}
+
+	for s.decoder.PeekKind() != closing {
+		member := segment{kind: nameSegment, text: strconv.Itoa(idx)}
+		if kind == '{' {
+			keyToken, err = s.decoder.ReadToken()
+			if err != nil {
+				err = s.enrichError(
+					ErrJSONPathTraversalFailed,
+					ErrJSONTokenReadFailed,
+					"reading", "object_key",
+					err,
+				)
+				goto end
+			}
+			member = segment{kind: keySegment, text: keyToken.String()}
+		}
+
+		// The three-index slice keeps siblings from sharing a backing array
+		err = s.flattenValue(append(path[:len(path):len(path)], member), leaves)
+		if err != nil {
+			goto end
+		}
+		idx++
+	}
+
+	// Read container end token
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "container_end",
+			err,
+		)
+	}
+
+end:
+	return err
+}
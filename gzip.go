@@ -0,0 +1,126 @@
+package jsonxtractr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+)
+
+// ExtractValuesFromGzipReader extracts multiple values from gzip-compressed
+// JSON read from reader, decompressing it as it streams through
+// ExtractValuesFromReader. Input that isn't gzip-compressed, or whose
+// compressed stream is corrupt or truncated, returns an error wrapping
+// ErrJSONGzipReadFailed. Extraction stops decoding once every value is found,
+// but the rest of the stream is still decompressed so its checksum is
+// verified.
+func ExtractValuesFromGzipReader(reader io.Reader, selectors []Selector) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var gz *gzipReader
+	var drainErr error
+
+	if reader == nil {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selectors", selectors,
+		)
+		goto end
+	}
+
+	gz, err = newGzipReader(reader)
+	if err != nil {
+		goto end
+	}
+	defer gz.close()
+
+	valuesMap, notFound, err = ExtractValuesFromReader(gz, selectors)
+	if errors.Is(err, ErrJSONGzipReadFailed) {
+		goto end
+	}
+
+	// A corrupt stream is only detected at its end
+	_, drainErr = io.Copy(io.Discard, gz)
+	if drainErr != nil {
+		valuesMap = nil
+		notFound = nil
+		err = drainErr
+	}
+
+end:
+	return valuesMap, notFound, err
+}
+
+// gzipReader decompresses a gzip stream, reporting any failure of the stream
+// itself as ErrJSONGzipReadFailed so it can be told apart from malformed JSON.
+type gzipReader struct {
+	reader *gzip.Reader
+}
+
+// gzipMagic is the two bytes every gzip stream begins with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// newGzipReader reads the gzip header from reader, checking for the gzip magic
+// bytes first so that uncompressed input fails with a clear reason rather than
+// whatever the header parse trips over.
+func newGzipReader(reader io.Reader) (gz *gzipReader, err error) {
+	var zr *gzip.Reader
+	var magic []byte
+
+	buffered := bufio.NewReader(reader)
+	magic, err = buffered.Peek(len(gzipMagic))
+	if len(magic) == 0 && errors.Is(err, io.EOF) {
+		err = NewErr(
+			ErrJSONGzipReadFailed,
+			ErrJSONBodyCannotBeEmpty,
+			err,
+		)
+		goto end
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = NewErr(
+			ErrJSONGzipReadFailed,
+			ErrJSONReadFailed,
+			err,
+		)
+		goto end
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		err = NewErr(
+			ErrJSONGzipReadFailed,
+			"reason", "input is not gzip-compressed",
+			gzip.ErrHeader,
+		)
+		goto end
+	}
+
+	zr, err = gzip.NewReader(buffered)
+	if err != nil {
+		err = NewErr(
+			ErrJSONGzipReadFailed,
+			err,
+		)
+		goto end
+	}
+	gz = &gzipReader{reader: zr}
+
+end:
+	return gz, err
+}
+
+func (r *gzipReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = NewErr(
+			ErrJSONGzipReadFailed,
+			err,
+		)
+	}
+	return n, err
+}
+
+// close releases the decompressor. Everything needed has been read by then,
+// and gzip.Reader's Close never fails, so its error is of no consequence.
+func (r *gzipReader) close() {
+	_ = r.reader.Close()
+}
@@ -0,0 +1,203 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// indexListPrefix and indexListSuffix enclose an index list segment, e.g.
+// "[0,2,-1]", and likewise a bracketed index or key such as "items[0]".
+const (
+	indexListPrefix = '['
+	indexListSuffix = ']'
+)
+
+// parseIndexListSegment parses an index list segment such as "[0,2,-1]" whose
+// '[' is at byte offset pos. The list selects a new array of the listed
+// elements, so it must end the selector.
+func parseIndexListSegment(selector string, pos int) (seg segment, next int, err error) {
+	var indexes []int
+	var idx int
+	var reason string
+
+	next = strings.IndexByte(selector[pos:], indexListSuffix)
+	if next < 0 {
+		reason = "missing closing ']'"
+		goto end
+	}
+	next += pos
+
+	for entry := range strings.SplitSeq(selector[pos+1:next], ",") {
+		idx, err = strconv.Atoi(strings.TrimSpace(entry))
+		if err != nil {
+			reason = "expected a comma-separated list of integers"
+			goto end
+		}
+		indexes = append(indexes, idx)
+	}
+
+	// Step past the closing bracket, which must end the selector
+	next++
+	if next < len(selector) {
+		reason = "an index list must be the last segment"
+		goto end
+	}
+	seg = segment{kind: indexListSegment, text: formatIndexList(indexes), indexes: indexes}
+
+end:
+	if reason != "" {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorIndexListInvalid,
+			"selector", selector,
+			"index_list_offset", pos,
+			"reason", reason,
+		)
+	}
+	return seg, next, err
+}
+
+// formatIndexList writes indexes as an index list segment.
+func formatIndexList(indexes []int) string {
+	var sb strings.Builder
+
+	sb.WriteByte(indexListPrefix)
+	for i, idx := range indexes {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.Itoa(idx))
+	}
+	sb.WriteByte(indexListSuffix)
+	return sb.String()
+}
+
+// navigateIndexList reads the array the decoder is positioned at in a single
+// pass and replaces the decoder with one reading a new array of the elements
+// at indexes, in the order listed and repeated as often as listed. The array
+// is only read to its end when a negative index needs its length.
+func (s *extractState) navigateIndexList(indexes []int) (err error) {
+	var value jsontext.Value
+	var arrayStart int64
+	var length, lastWanted, fromEnd, depth int
+	var wanted map[int]jsontext.Value
+	var trailing *trailingValues
+	var selection bytes.Buffer
+
+	kind := s.decoder.PeekKind()
+	if kind != '[' {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedArrayAtSegment,
+			"expected_type", "array",
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", s.valuePreview(s.unreadInput()),
+		)
+		goto end
+	}
+
+	// Read array start token '['
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "array_start",
+			err,
+		)
+		goto end
+	}
+	arrayStart = s.inputOffset() - 1
+
+	lastWanted = slices.Max(indexes)
+	fromEnd = trailingCount(min(slices.Min(indexes), 0))
+	wanted = make(map[int]jsontext.Value, len(indexes))
+	trailing = newTrailingValues(max(fromEnd, 1))
+
+	for s.decoder.PeekKind() != ']' && (fromEnd > 0 || length <= lastWanted) {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
+		value, err = s.decoder.ReadValue()
+		if err != nil {
+			err = s.skipFailed(err, "skip_index", length)
+			goto end
+		}
+		if slices.Contains(indexes, length) {
+			wanted[length] = value.Clone()
+		}
+		if fromEnd > 0 {
+			trailing.add(value, 0)
+		}
+		length++
+	}
+
+	selection.WriteByte('[')
+	for i, idx := range indexes {
+		value = wanted[idx]
+		if idx < 0 {
+			value, _, _ = trailing.fromEnd(idx)
+		}
+		if value == nil {
+			err = s.enrichErrorAt(arrayStart,
+				ErrJSONPathTraversalFailed,
+				ErrJSONIndexOutOfRange,
+				"target_index", idx,
+				"array_length", length,
+			)
+			goto end
+		}
+		if i > 0 {
+			selection.WriteByte(',')
+		}
+		selection.Write(value)
+	}
+	selection.WriteByte(']')
+
+	// The selection stands in for the array read, so it adds no nesting
+	depth = s.depth() - 1
+	s.replaceDecoder(selection.Bytes(), arrayStart)
+	s.baseDepth = depth
+
+end:
+	return err
+}
+
+// selected returns a node standing for a new array of the elements of n at
+// indexes, in the order listed.
+func (n *docNode) selected(state *extractState, indexes []int) (selection *docNode, err error) {
+	var child *docNode
+
+	selection = &docNode{kind: '[', start: n.start, end: n.end, selection: true}
+	for _, idx := range indexes {
+		child, err = n.element(state, idx)
+		if err != nil {
+			selection = nil
+			goto end
+		}
+		selection.children = append(selection.children, child)
+	}
+
+end:
+	return selection, err
+}
+
+// decodeSelection unmarshals each element of an index list's selection into a
+// new []any.
+func (d *Document) decodeSelection(selector Selector, selection *docNode) (value any, err error) {
+	values := make([]any, len(selection.children))
+	for i, child := range selection.children {
+		values[i], err = d.decode(selector, child)
+		if err != nil {
+			goto end
+		}
+	}
+	value = values
+
+end:
+	return value, err
+}
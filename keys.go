@@ -0,0 +1,135 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+)
+
+// Keys returns the member names of the object selected from JSON bytes, in
+// document order, without decoding any member values. RootSelector lists the
+// top-level keys. Selecting anything other than an object returns
+// ErrJSONPathExpectedObjectAtSegment.
+func Keys(jsonBytes []byte, selector Selector) (keys []string, err error) {
+	var state *extractState
+	var keyToken jsontext.Token
+	var kind jsontext.Kind
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	kind = state.decoder.PeekKind()
+	if kind != '{' {
+		err = state.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedObjectAtSegment,
+			"expected_type", "object",
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", state.valuePreview(state.unreadInput()),
+		)
+		goto end
+	}
+
+	// Read object start token '{'
+	_, err = state.decoder.ReadToken()
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "object_start",
+			err,
+		)
+		goto end
+	}
+
+	keys = make([]string, 0)
+	for state.decoder.PeekKind() != '}' {
+		keyToken, err = state.decoder.ReadToken()
+		if err != nil {
+			err = state.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONTokenReadFailed,
+				"reading", "object_key",
+				err,
+			)
+			goto end
+		}
+		key := keyToken.String()
+		keys = append(keys, key)
+
+		err = state.decoder.SkipValue()
+		if err != nil {
+			err = state.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONTokenReadFailed,
+				"skipping_key", key,
+				err,
+			)
+			goto end
+		}
+	}
+
+end:
+	if err != nil {
+		keys = nil
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return keys, err
+}
+
+// KeyAt returns the name of the member at zero-based position ordinal, in
+// document order, of the object selected from JSON bytes, without decoding
+// any member values. It pairs with an ordinal segment such as "#1", which
+// selects that member's value. An ordinal past the last member returns
+// ErrJSONIndexOutOfRange, and selecting anything other than an object returns
+// ErrJSONPathExpectedObjectAtSegment.
+func KeyAt(jsonBytes []byte, selector Selector, ordinal int) (key string, err error) {
+	var state *extractState
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	err = state.navigateObjectOrdinal(ordinal)
+	if err != nil {
+		goto end
+	}
+	key = state.ordinalKey
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			"ordinal", ordinal,
+			err,
+		)
+	}
+	return key, err
+}
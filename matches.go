@@ -0,0 +1,448 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"io"
+	"strconv"
+)
+
+// Match is a single value selected by a selector that may contain wildcards,
+// along with the concrete path that resolved to it.
+type Match struct {
+	Path  Selector
+	Value any
+}
+
+// ExtractMatches extracts every value matched by a selector that may contain
+// wildcard segments, e.g. "users.*.name". A "*" segment matches every member
+// of an object or every element of an array, and nested wildcards produce the
+// cross-product of their matches. A segment containing '*' or '?', such as
+// "metrics.cpu_*", is a glob matched against each object key in full, where
+// '*' matches any run of characters and '?' any single one. A segment wrapped
+// in slashes, such as "items./^id_\d+$/.value", is a regular expression matched
+// against each object key, anchored only where it says so. Globs and regular
+// expressions match no array elements. A filter segment, such as
+// `users.#(role=="admin")#.name`, matches each array element that is an object
+// whose named member compares true against a string or number literal using
+// ==, !=, <, >, <= or >=; elements holding the other type are skipped. Without
+// the trailing '#' a filter matches only the first such element. An empty
+// segment, as in "users..email", descends recursively: the segment after it is
+// matched against the keys of every object at any depth below, so
+// "users..email" matches each "email" member nested anywhere within "users".
+// A selector such as "..email" or "$..email" descends from the document root.
+// Only object keys are matched, never strings found as values.
+//
+// Matches are returned in document order: object members in the order their
+// keys appear and array elements in index order, with nested wildcards
+// expanded depth-first, so every match below one member precedes those below
+// the next. The order is the same for the same input and may be relied on,
+// e.g. for snapshot tests.
+//
+// The path leading to the first wildcard must exist, otherwise the usual
+// traversal errors are returned. Below a wildcard, members that don't match
+// the remainder of the selector are simply omitted from the result. A wildcard
+// reaching an empty array or object likewise contributes nothing, so matching
+// nothing at all returns an empty result rather than an error, unless
+// WithEmptyAsNotFound is given to ExtractMatchesOpts.
+func ExtractMatches(jsonBytes []byte, selector Selector) (matches []Match, err error) {
+	return ExtractMatchesOpts(jsonBytes, selector)
+}
+
+// ExtractMatchesOpts is ExtractMatches with options.
+func ExtractMatchesOpts(jsonBytes []byte, selector Selector, opts ...Option) (matches []Match, err error) {
+	var o options
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	o = newOptions(opts)
+	matches = make([]Match, 0)
+	err = walkMatches(bytes.NewReader(jsonBytes), selector, jsonBytes, o, func(path []segment, value any) error {
+		matches = append(matches, Match{
+			Path:  formatSelector(path),
+			Value: value,
+		})
+		return nil
+	})
+	if err == nil && len(matches) == 0 && o.emptyAsNotFound {
+		err = NewErr(
+			ErrJSONSelectorNotFound,
+			"selector", selector,
+			"reason", "no values matched",
+		)
+	}
+
+end:
+	return matches, err
+}
+
+// ForEachMatch invokes fn for every value matched by a selector that may
+// contain wildcard segments, as each match is found during a single pass over
+// reader, so only the subtree currently being matched is held in memory. A
+// selector without wildcards invokes fn at most once. Matches are reported in
+// the order ExtractMatches returns them.
+//
+// If fn returns an error the walk stops and ForEachMatch returns that error
+// unchanged. Paths are reported as selectors, as in Match.
+func ForEachMatch(reader io.Reader, selector Selector, fn func(path string, value any) error) (err error) {
+	if reader == nil {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	err = walkMatches(reader, selector, nil, options{}, func(path []segment, value any) error {
+		return fn(string(formatSelector(path)), value)
+	})
+
+end:
+	return err
+}
+
+// matchWalk carries the callback shared by every level of a wildcard walk and
+// remembers when the callback itself asked the walk to stop.
+type matchWalk struct {
+	fn      func(path []segment, value any) error
+	stopErr error
+}
+
+// walkMatches invokes fn for every value matched by selector, stopping early
+// and returning the callback's error if it returns one.
+func walkMatches(reader io.Reader, selector Selector, rawBytes []byte, opts options, fn func(path []segment, value any) error) (err error) {
+	var state *extractState
+	var segments []segment
+	var rootDescent bool
+
+	if len(selector) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONValueSelectorCannotBeEmpty,
+		)
+		goto end
+	}
+
+	segments, err = opts.parseSelector(selector)
+	if err != nil {
+		goto end
+	}
+
+	// A descent from the root is written "..key", or "$..key" as in
+	// JSONPath, either of which leaves one segment ahead of the descent
+	rootDescent = len(segments) > 1 && segments[1].isEmpty() &&
+		(segments[0].isEmpty() || segments[0].kind == nameSegment && segments[0].text == string(RootSelector))
+	if rootDescent {
+		segments = segments[1:]
+	}
+
+	state = newExtractState(opts.newNavigationDecoder(reader), string(selector), segments, rawBytes)
+	state.opts = opts
+
+	// Reject empty segments that don't begin a descent up front, since they
+	// could otherwise hide below a wildcard that happens to match nothing
+	for i, seg := range state.segments {
+		if !seg.isEmpty() || (i > 0 || rootDescent) && state.beginsDescent(i) {
+			continue
+		}
+		state.position = i
+		err = state.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathContainsEmptySegment,
+		)
+		goto end
+	}
+
+	err = state.walkMatches(&matchWalk{fn: fn}, 0, nil)
+
+end:
+	return err
+}
+
+// walkMatches navigates from segment position start, invoking the walk's
+// callback for each value reached with the concrete path resolved so far.
+func (s *extractState) walkMatches(walk *matchWalk, start int, resolved []segment) (err error) {
+	var value any
+
+	for i := start; i < len(s.segments); i++ {
+		s.position = i
+		seg := s.segments[i]
+		if seg.isMultiMatch() {
+			err = s.walkWildcard(walk, i, resolved)
+			goto end
+		}
+		if seg.isEmpty() {
+			err = s.walkDescent(walk, i, resolved)
+			goto end
+		}
+
+		err = s.navigateToSegment(seg)
+		if err != nil {
+			goto end
+		}
+		s.pathProgress = append(s.pathProgress, seg.text)
+		resolved = append(resolved, s.resolvedSegment(seg))
+	}
+
+	err = s.decoder.UnmarshalInto(&value, s.opts.unmarshalOptions())
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONUnmarshalFailed,
+			err,
+		)
+		goto end
+	}
+
+	err = walk.fn(resolved, value)
+	if err != nil {
+		walk.stopErr = err
+	}
+
+end:
+	return err
+}
+
+// walkWildcard expands the wildcard or glob at segment position pos over every
+// matching member of the object or array the decoder is positioned at. Each
+// member is read on its own so only the current subtree is buffered.
+func (s *extractState) walkWildcard(walk *matchWalk, pos int, resolved []segment) (err error) {
+	var keyToken jsontext.Token
+	var value jsontext.Value
+	var idx int
+	var closing jsontext.Kind
+
+	seg := s.segments[pos]
+	kind := s.decoder.PeekKind()
+	switch {
+	case kind == '{':
+		closing = '}'
+	case kind == '[' && (seg.kind == wildcardSegment || seg.kind == filterAllSegment):
+		closing = ']'
+	default:
+		// A wildcard over a scalar, a key pattern over anything but an
+		// object, or a filter over anything but an array, matches nothing
+		goto end
+	}
+
+	// Read container start token
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "container_start",
+			err,
+		)
+		goto end
+	}
+
+	for s.decoder.PeekKind() != closing {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
+		member := segment{kind: nameSegment, text: strconv.Itoa(idx)}
+		if kind == '{' {
+			keyToken, err = s.decoder.ReadToken()
+			if err != nil {
+				err = s.enrichError(
+					ErrJSONPathTraversalFailed,
+					ErrJSONTokenReadFailed,
+					"reading", "object_key",
+					err,
+				)
+				goto end
+			}
+			member = segment{kind: keySegment, text: keyToken.String()}
+			if !seg.matchesKey(member.text) {
+				err = s.decoder.SkipValue()
+				if err != nil {
+					err = s.enrichError(
+						ErrJSONPathTraversalFailed,
+						ErrJSONTokenReadFailed,
+						"reading", "member_value",
+						"member", member.text,
+						err,
+					)
+					goto end
+				}
+				continue
+			}
+		}
+
+		value, err = s.decoder.ReadValue()
+		if err != nil {
+			err = s.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONTokenReadFailed,
+				"reading", "member_value",
+				"member", member.text,
+				err,
+			)
+			goto end
+		}
+
+		if seg.kind == filterAllSegment && !seg.filter.matches(value) {
+			idx++
+			continue
+		}
+
+		err = s.member(value, pos).walkMatches(walk, pos+1, append(resolved[:len(resolved):len(resolved)], member))
+		if walk.stopErr != nil {
+			goto end
+		}
+		// Members that don't match the rest of the selector are omitted
+		err = nil
+		idx++
+	}
+
+end:
+	return err
+}
+
+// beginsDescent reports whether the empty segment at position pos begins a
+// recursive descent, which it does when followed by a segment that can be
+// matched against object keys.
+func (s *extractState) beginsDescent(pos int) bool {
+	return beginsDescent(s.segments, pos)
+}
+
+// beginsDescent reports whether the empty segment at position pos of segments
+// is followed by a segment that can be matched against object keys.
+func beginsDescent(segments []segment, pos int) bool {
+	if pos+1 >= len(segments) {
+		return false
+	}
+	switch segments[pos+1].kind {
+	case nameSegment:
+		return !segments[pos+1].isEmpty()
+	case keySegment, wildcardSegment, globSegment, regexSegment:
+		return true
+	}
+	return false
+}
+
+// isDescent reports whether the segment at position pos of a selector's
+// segments is an empty one that walkMatches expands as a recursive descent,
+// as in "a..b", or the leading empty segment of "..b", a descent from the
+// root.
+func isDescent(segments []segment, pos int) bool {
+	switch {
+	case !segments[pos].isEmpty():
+		return false
+	case pos == 0:
+		return len(segments) > 1 && segments[1].isEmpty() && beginsDescent(segments, 1)
+	}
+	return beginsDescent(segments, pos)
+}
+
+// walkDescent expands the recursive descent beginning at segment position pos
+// over every object and array nested within the value the decoder is
+// positioned at, depth-first. Each member whose key matches the segment after
+// pos continues with the rest of the selector, before the walk descends into
+// the member itself, so matches are reported in document order.
+func (s *extractState) walkDescent(walk *matchWalk, pos int, resolved []segment) (err error) {
+	var keyToken jsontext.Token
+	var value jsontext.Value
+	var idx int
+	var closing jsontext.Kind
+	var matched bool
+
+	target := s.segments[pos+1]
+	kind := s.decoder.PeekKind()
+	switch kind {
+	case '{':
+		closing = '}'
+	case '[':
+		closing = ']'
+	default:
+		// Scalars hold no keys to match
+		goto end
+	}
+
+	// Read container start token
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "container_start",
+			err,
+		)
+		goto end
+	}
+
+	for s.decoder.PeekKind() != closing {
+		err = s.countToken()
+		if err != nil {
+			goto end
+		}
+		member := segment{kind: nameSegment, text: strconv.Itoa(idx)}
+		matched = false
+		if kind == '{' {
+			keyToken, err = s.decoder.ReadToken()
+			if err != nil {
+				err = s.enrichError(
+					ErrJSONPathTraversalFailed,
+					ErrJSONTokenReadFailed,
+					"reading", "object_key",
+					err,
+				)
+				goto end
+			}
+			member = segment{kind: keySegment, text: keyToken.String()}
+			matched = target.matchesKey(member.text) ||
+				!target.isMultiMatch() && s.opts.keyMatches(member.text, target.text)
+		}
+
+		value, err = s.decoder.ReadValue()
+		if err != nil {
+			err = s.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONTokenReadFailed,
+				"reading", "member_value",
+				"member", member.text,
+				err,
+			)
+			goto end
+		}
+		path := append(resolved[:len(resolved):len(resolved)], member)
+
+		if matched {
+			err = s.member(value, pos+1).walkMatches(walk, pos+2, path)
+			if walk.stopErr != nil {
+				goto end
+			}
+			// Members that don't match the rest of the selector are omitted
+		}
+
+		err = s.member(value, pos).walkDescent(walk, pos, path)
+		if err != nil {
+			goto end
+		}
+		idx++
+	}
+
+end:
+	return err
+}
+
+// member returns a state for walking value, a member matched by the wildcard
+// at segment position pos, with its own decoder but the same selector context.
+func (s *extractState) member(value jsontext.Value, pos int) *extractState {
+	state := newExtractState(s.opts.newNavigationDecoder(bytes.NewReader(value)), s.selector, s.segments, s.rawBytes)
+	state.opts = s.opts
+	state.tokens = s.tokens
+	state.baseOffset = s.inputOffset() - int64(len(value))
+	state.pathProgress = append(s.pathProgress[:len(s.pathProgress):len(s.pathProgress)], s.segments[pos].text)
+	return state
+}
@@ -0,0 +1,92 @@
+package jsonxtractr
+
+import (
+	"strings"
+)
+
+// MultiError is the error returned by the multi-selector functions, such as
+// ExtractValuesFromBytes, when one or more selectors fail. It records why each
+// failed selector failed, in the order the selectors were given. Retrieve it
+// with errors.As; errors.Is still matches the sentinels of every failure.
+type MultiError struct {
+	selectors []Selector
+	errs      []error
+}
+
+// newMultiError returns a MultiError for the failed selectors, where errs[i]
+// is why selectors[i] failed, or nil when no selector failed.
+func newMultiError(selectors []Selector, errs []error) (err error) {
+	if len(errs) == 0 {
+		goto end
+	}
+	err = &MultiError{
+		selectors: selectors,
+		errs:      errs,
+	}
+
+end:
+	return err
+}
+
+// Failures returns the error for each selector that failed.
+func (e *MultiError) Failures() map[Selector]error {
+	failures := make(map[Selector]error, len(e.errs))
+	for i, selector := range e.selectors {
+		failures[selector] = e.errs[i]
+	}
+	return failures
+}
+
+// Failed returns the error for each selector whose path exists but whose
+// value couldn't be extracted, leaving out those IsAbsent reports as absent,
+// which the multi-selector functions return as not found.
+func (e *MultiError) Failed() map[Selector]error {
+	failed := make(map[Selector]error, len(e.errs))
+	for i, selector := range e.selectors {
+		if isNotFound(e.errs[i]) {
+			continue
+		}
+		failed[selector] = e.errs[i]
+	}
+	return failed
+}
+
+// Selectors returns the selectors that failed, in the order they were given.
+func (e *MultiError) Selectors() []Selector {
+	return append([]Selector(nil), e.selectors...)
+}
+
+func (e *MultiError) Error() string {
+	messages := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e *MultiError) Unwrap() []error {
+	return append([]error(nil), e.errs...)
+}
+
+// absentSelectors returns those of the failed selectors whose paths are
+// absent, as IsAbsent reports, where errs[i] is why selectors[i] failed.
+func absentSelectors(selectors []Selector, errs []error) []Selector {
+	absent := make([]Selector, 0, len(selectors))
+	for i, selector := range selectors {
+		if isNotFound(errs[i]) {
+			absent = append(absent, selector)
+		}
+	}
+	return absent
+}
+
+// soleFailure returns the only failure of a MultiError for one selector, so
+// the single-selector functions report it just as they would have on their
+// own, and any other error unchanged.
+func soleFailure(err error) error {
+	multi, ok := err.(*MultiError)
+	if ok && len(multi.errs) == 1 {
+		err = multi.errs[0]
+	}
+	return err
+}
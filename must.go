@@ -0,0 +1,26 @@
+package jsonxtractr
+
+// MustExtractValueFromBytes is like ExtractValueFromBytes but panics if the
+// value can't be extracted. It is intended for tests and package-level
+// initialization from trusted JSON, not for production request paths.
+//
+// The panic value is the error itself, so a recover() can inspect it with
+// errors.Is.
+func MustExtractValueFromBytes(jsonBytes []byte, selector Selector) any {
+	value, err := ExtractValueFromBytes(jsonBytes, selector)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// MustExtractAs is like ExtractAs but panics if the value can't be extracted
+// or converted to T. As with MustExtractValueFromBytes, it is intended for
+// tests and initialization code, and the panic value is the error itself.
+func MustExtractAs[T any](jsonBytes []byte, selector Selector) T {
+	value, err := ExtractAs[T](jsonBytes, selector)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
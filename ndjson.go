@@ -0,0 +1,143 @@
+package jsonxtractr
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ExtractValuesFromNDJSON applies selector to every record of a stream of
+// newline-delimited JSON, reading one line at a time. values has one entry per
+// line, indexed from zero, holding the selected value or nil for lines that
+// are blank, malformed or lack the selector; notFound holds the indexes of
+// well-formed lines that lack it.
+//
+// A line whose shape doesn't fit the selector, e.g. an array where it expects
+// an object, counts as lacking it. A malformed line, or one holding more than
+// one JSON value, doesn't stop the stream. Each is reported in err as an error
+// wrapping ErrJSONNDJSONLineFailed with its 1-based "line" number, and all such
+// errors are combined. An invalid selector or a failure to read the stream
+// does stop it.
+func ExtractValuesFromNDJSON(reader io.Reader, selector Selector) (values []any, notFound []int, err error) {
+	var errs []error
+	var buffered *bufio.Reader
+	var segments []segment
+
+	if reader == nil {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	// Check the selector once rather than failing it on every line
+	segments, err = selector.parseMatching()
+	if err == nil {
+		err = newExtractState(nil, string(selector), segments, nil).rejectWildcards()
+	}
+	if err != nil {
+		goto end
+	}
+
+	values = make([]any, 0)
+	notFound = make([]int, 0)
+	buffered = bufio.NewReader(reader)
+
+	for lineNum := 1; ; lineNum++ {
+		var value any
+		var line []byte
+		var readErr, lineErr error
+
+		line, readErr = buffered.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			err = NewErr(
+				ErrJSONStreamingParseFailed,
+				ErrJSONReadFailed,
+				"line", lineNum,
+				readErr,
+			)
+			goto end
+		}
+		if len(line) == 0 && readErr != nil {
+			// The stream ended with a newline, or was empty
+			break
+		}
+
+		line = bytes.TrimSpace(line)
+		values = append(values, nil)
+		if len(line) > 0 {
+			// Blank lines are skipped
+			lineErr = validateNDJSONLine(line)
+		}
+		if len(line) > 0 && lineErr == nil {
+			value, lineErr = extractSingleValue(bytes.NewReader(line), selector, line, options{})
+			switch {
+			case lineErr == nil:
+				values[len(values)-1] = value
+			case isNotFound(lineErr):
+				// Records vary in shape, so a mismatched one lacks the path
+				notFound = append(notFound, len(values)-1)
+				lineErr = nil
+			}
+		}
+		if lineErr != nil {
+			errs = append(errs, NewErr(
+				ErrJSONNDJSONLineFailed,
+				"line", lineNum,
+				lineErr,
+			))
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	// Join all collected errors
+	if len(errs) > 0 {
+		err = CombineErrs(errs)
+	}
+
+end:
+	return values, notFound, err
+}
+
+// validateNDJSONLine checks that line holds exactly one well-formed JSON value.
+func validateNDJSONLine(line []byte) (err error) {
+	decoder := options{}.newDecoder(bytes.NewReader(line))
+
+	_, err = decoder.ReadValue()
+	if err != nil {
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+		goto end
+	}
+
+	// Anything after the value, even another well-formed one, is an error
+	_, err = decoder.ReadToken()
+	switch {
+	case errors.Is(err, io.EOF):
+		err = nil
+	case err != nil:
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+	default:
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			"reason", "more than one JSON value on line",
+			"byte_offset", decoder.InputOffset(),
+		)
+	}
+
+end:
+	return err
+}
@@ -0,0 +1,691 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Option configures how values are extracted. Options are passed to the
+// functions with an Opts suffix, e.g. ExtractValuesFromBytesOpts.
+type Option func(*options)
+
+// options holds the extraction behavior selected by a list of Option. The zero
+// value is the default behavior of the option-less functions.
+type options struct {
+	numbersAsString      bool
+	caseInsensitiveKeys  bool
+	strictKeys           bool
+	rejectDuplicateKeys  bool
+	concurrency          int
+	maxInputBytes        int64
+	maxDepth             int
+	errorJSONMaxLen      int
+	errorJSONRedactKeys  []string
+	tolerant             bool
+	smartNumbers         bool
+	strictUTF8           bool
+	fastSkip             bool
+	trimStrings          bool
+	emptyAsNotFound      bool
+	maxTokens            int64
+	redactAsNull         bool
+	createMissing        bool
+	decoderFactory       func(io.Reader) Decoder
+	missingAsNil         bool
+	closeReader          bool
+	anySeparator         bool
+	validateRemainder    bool
+	rejectUnknownMembers bool
+	progress             func(bytesRead int64)
+	progressEvery        int64
+}
+
+// defaultMaxTokens is the token limit when WithMaxTokens isn't given. Every
+// token takes at least a byte of input, so no document short of gigabytes can
+// reach it.
+const defaultMaxTokens = 1 << 32
+
+func newOptions(opts []Option) (o options) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithNumbersAsString extracts JSON numbers as strings holding their exact
+// source text, e.g. "1.50" or "12345678901234567890", instead of as float64,
+// so no precision or formatting is lost.
+func WithNumbersAsString() Option {
+	return func(o *options) {
+		o.numbersAsString = true
+	}
+}
+
+// WithSmartNumbers extracts JSON numbers written as integers, e.g. 42 or -7,
+// as int64 instead of float64, so type switches can handle the common integer
+// case directly. Integers beyond the range of int64 are extracted as
+// json.Number holding their exact source text, while numbers written with a
+// fraction or exponent, e.g. 1.5 or 1e3, remain float64. WithNumbersAsString
+// takes precedence over it.
+func WithSmartNumbers() Option {
+	return func(o *options) {
+		o.smartNumbers = true
+	}
+}
+
+// WithCaseInsensitiveKeys matches selector segments against object keys
+// without regard to case, as with strings.EqualFold. When several keys match
+// a segment, the first in document order wins.
+func WithCaseInsensitiveKeys() Option {
+	return func(o *options) {
+		o.caseInsensitiveKeys = true
+	}
+}
+
+// WithStrictCaseInsensitiveKeys is WithCaseInsensitiveKeys, except that a
+// segment matching more than one key of the same object fails with
+// ErrJSONAmbiguousKey, listing the matching keys, rather than taking the first.
+// Every object along the path must then be read in full to rule out a later
+// match.
+func WithStrictCaseInsensitiveKeys() Option {
+	return func(o *options) {
+		o.caseInsensitiveKeys = true
+		o.strictKeys = true
+	}
+}
+
+// WithRejectDuplicateKeys fails with ErrJSONDuplicateKey, naming the key, when
+// an object read during extraction has the same key more than once. Without it
+// the first of the duplicate keys wins. Every object along the path must then
+// be read in full to rule out a later duplicate.
+func WithRejectDuplicateKeys() Option {
+	return func(o *options) {
+		o.rejectDuplicateKeys = true
+	}
+}
+
+// WithConcurrency splits the selectors passed to a multi-selector extraction
+// across up to n goroutines, each walking the document for its own share. The
+// values, errors and not-found order are the same as without it. Each share
+// reads the document up to its last selector, so this pays off for many
+// selectors on multi-core machines rather than for a few. An n below 2
+// extracts serially, which is the default.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithMaxInputBytes fails extraction with ErrJSONInputTooLarge, giving the
+// limit as "max_input_bytes", once more than n bytes of input would have to be
+// read, rather than buffering an unbounded stream. A stream whose selected
+// value lies within the first n bytes is not read past it. An n of zero or
+// less, the default, reads without limit.
+func WithMaxInputBytes(n int64) Option {
+	return func(o *options) {
+		o.maxInputBytes = n
+	}
+}
+
+// WithMaxDepth fails extraction with ErrJSONMaxDepthExceeded once the objects
+// and arrays along a selector's path, together with those nested within the
+// selected value, are more than d deep, so untrusted input can't make decoding
+// recurse without bound. The root object or array is at depth 1. Values that
+// are skipped over on the way are bounded only by the decoder's own limit. A d
+// of zero or less, the default, sets no limit.
+func WithMaxDepth(d int) Option {
+	return func(o *options) {
+		o.maxDepth = d
+	}
+}
+
+// WithMaxTokens fails extraction with ErrJSONTooManyTokens, giving the limit
+// as "max_tokens", once navigating to the selected values has stepped over
+// more than n object members and array elements, so adversarial input can
+// only ever cost a bounded amount of work. Each member or element counts as
+// one token however large it is. An n of zero or less sets the default, a
+// limit far beyond any legitimate document.
+func WithMaxTokens(n int64) Option {
+	return func(o *options) {
+		o.maxTokens = n
+	}
+}
+
+// tokenLimit returns the most tokens navigation may step over.
+func (o options) tokenLimit() int64 {
+	if o.maxTokens <= 0 {
+		return defaultMaxTokens
+	}
+	return o.maxTokens
+}
+
+// WithErrorJSONMaxLen bounds the condensed JSON included in error context to
+// about n bytes, where the default is 200. Longer JSON is cut short, at a
+// comma or closing bracket where possible, and marked "...[more]". The window
+// of raw input around a failure, given as "json_window", spans at most n/2
+// bytes.
+func WithErrorJSONMaxLen(n int) Option {
+	return func(o *options) {
+		o.errorJSONMaxLen = n
+	}
+}
+
+// errorJSONLimit returns the bound on the condensed JSON in error context.
+func (o options) errorJSONLimit() int {
+	if o.errorJSONMaxLen > 0 {
+		return o.errorJSONMaxLen
+	}
+	return defaultErrorJSONMaxLen
+}
+
+// WithErrorJSONRedactKeys replaces the value of every member named by one of
+// keys, at any depth, with "***" in the JSON included in error context, so
+// secrets such as tokens or passwords don't end up in logs. Keys match
+// exactly. It may be given more than once to add keys.
+func WithErrorJSONRedactKeys(keys ...string) Option {
+	return func(o *options) {
+		o.errorJSONRedactKeys = append(o.errorJSONRedactKeys, keys...)
+	}
+}
+
+// WithTolerant accepts hand-edited JSON containing // line comments, /* block
+// comments */ and trailing commas before a closing '}' or ']', as found in
+// many configuration files. They are blanked out before extraction, leaving
+// the contents of strings untouched, so error offsets still match the input.
+// The whole input is read before extraction, even for a single selector.
+func WithTolerant() Option {
+	return func(o *options) {
+		o.tolerant = true
+	}
+}
+
+// WithStrictUTF8 fails extraction with ErrJSONInvalidUTF8, giving the offset
+// of the first bad byte as "byte_offset", when the input holds invalid UTF-8
+// anywhere, whether in a key, a string value or a part of the document that
+// extraction would otherwise never read. Without it invalid UTF-8 is reported
+// only if it lies within what is read, as a plain syntax error. The whole input
+// is read before extraction, even for a single selector.
+func WithStrictUTF8() Option {
+	return func(o *options) {
+		o.strictUTF8 = true
+	}
+}
+
+// WithFastSkip finds a non-negative array index by scanning past the elements
+// ahead of it for brackets, strings and commas rather than decoding each one,
+// which is much faster when those elements are large. The skipped elements
+// are not validated, so malformed JSON within them, such as a bad literal or a
+// duplicate key, goes unreported where it would otherwise fail extraction.
+// Input whose brackets don't match is still reported. It applies only when the
+// whole input is held in memory, e.g. ExtractValueFromBytesOpts, and to a
+// lone selector; multi-selector extraction always decodes what it skips.
+func WithFastSkip() Option {
+	return func(o *options) {
+		o.fastSkip = true
+	}
+}
+
+// WithValidateRemainder fails extraction with ErrJSONStreamingParseFailed,
+// giving the offset of the problem as "byte_offset", unless the input is a
+// single well-formed JSON value throughout. Without it only what extraction
+// reads is checked, so trailing garbage, or a second value after the first,
+// goes unnoticed. The whole input is read, even for a single selector.
+func WithValidateRemainder() Option {
+	return func(o *options) {
+		o.validateRemainder = true
+	}
+}
+
+// WithRejectUnknownMembers fails decoding into a struct, as ExtractIntoOpts
+// does, with ErrJSONUnmarshalFailed when the selected object has a member
+// matching none of the struct's fields, for strict parsing of configuration.
+// Without it such members are ignored.
+func WithRejectUnknownMembers() Option {
+	return func(o *options) {
+		o.rejectUnknownMembers = true
+	}
+}
+
+// WithProgress calls fn with the number of bytes read so far each time
+// another every bytes have been read from the reader passed to the functions
+// extracting from an io.Reader, e.g. to drive a progress bar over a large
+// stream. An every below 1 calls it after each read. Calls are never made
+// concurrently, though they may come from a goroutine other than the caller's.
+// Reading stops once every selector is resolved, so the final call needn't
+// report the whole input.
+func WithProgress(fn func(bytesRead int64), every int) Option {
+	return func(o *options) {
+		o.progress = fn
+		o.progressEvery = int64(every)
+	}
+}
+
+// WithTrimStrings removes leading and trailing whitespace from every extracted
+// string, both a string selected directly and the strings within an object or
+// array that is decoded whole. Object keys and values of other types are left
+// as they are.
+func WithTrimStrings() Option {
+	return func(o *options) {
+		o.trimStrings = true
+	}
+}
+
+// WithEmptyAsNotFound makes ExtractMatchesOpts fail with
+// ErrJSONSelectorNotFound when a selector matches no values, as happens when
+// its wildcard reaches an empty array or object, for callers that treat no
+// results as an error. By default such a selector yields an empty result.
+func WithEmptyAsNotFound() Option {
+	return func(o *options) {
+		o.emptyAsNotFound = true
+	}
+}
+
+// WithRedactAsNull makes RedactOpts replace each selected value with null,
+// keeping its key or array position, rather than removing it.
+func WithRedactAsNull() Option {
+	return func(o *options) {
+		o.redactAsNull = true
+	}
+}
+
+// WithCreateMissing makes SetValueOpts create the object members and array
+// elements the selector passes through that the document lacks, instead of
+// failing with ErrJSONPathSegmentNotFound or ErrJSONIndexOutOfRange. An array
+// is padded with null up to a new element's index, by at most 1024 elements,
+// beyond which it fails with ErrJSONIndexOutOfRange.
+func WithCreateMissing() Option {
+	return func(o *options) {
+		o.createMissing = true
+	}
+}
+
+// WithDecoderFactory navigates selectors with the Decoders newDecoder returns,
+// one for each input read, instead of the default jsontext.Decoder, e.g. to
+// use another JSON engine or to benchmark engines against each other. Options
+// that change how JSON is decoded, such as WithRejectDuplicateKeys, are then
+// up to those Decoders, since they are applied by the default one. Several
+// selectors are navigated one by one, rather than in a shared pass.
+func WithDecoderFactory(newDecoder func(io.Reader) Decoder) Option {
+	return func(o *options) {
+		o.decoderFactory = newDecoder
+	}
+}
+
+// WithMissingAsNil makes the functions extracting several values record
+// Missing in the ValuesMap for each selector whose path doesn't exist, as
+// IsAbsent reports, including a path through a value of the wrong type. Each
+// is still listed as not found, and the error is nil when that is the only
+// problem. Selectors that fail for other reasons, such as malformed JSON, are
+// reported in the error as usual.
+func WithMissingAsNil() Option {
+	return func(o *options) {
+		o.missingAsNil = true
+	}
+}
+
+// WithCloseReader makes the functions extracting from an io.Reader close it
+// once done when it is also an io.Closer, whether or not extraction succeeds,
+// handing it over as a file or response body often is. By default the reader
+// is left open for the caller, who still owns it.
+func WithCloseReader() Option {
+	return func(o *options) {
+		o.closeReader = true
+	}
+}
+
+// WithAnySeparator accepts '/' as well as '.' between segments, so a path
+// such as "user/addresses/0/city" emitted by a tool speaking JSON Pointer
+// selects what "user.addresses.0.city" does, and the two may be mixed in one
+// selector. A '/' within a quoted segment, a bracket or a filter is kept as
+// written, as is one escaped with a backslash. Since a '/' between segments
+// can no longer begin one, regular expression segments aren't available.
+func WithAnySeparator() Option {
+	return func(o *options) {
+		o.anySeparator = true
+	}
+}
+
+// parseSelector parses selector into its segments, first treating each '/'
+// between segments as a '.' when WithAnySeparator is set.
+func (o options) parseSelector(selector Selector) ([]segment, error) {
+	if o.anySeparator {
+		selector = slashesAsDots(selector)
+	}
+	return parseSelector(string(selector))
+}
+
+// failuresError returns notFound, those of the failed selectors whose paths
+// are absent as IsAbsent reports, and the error reporting why each failed
+// selector failed with errs. With WithMissingAsNil it records Missing in
+// valuesMap for those absent and reports only the rest.
+func (o options) failuresError(valuesMap ValuesMap, failed []Selector, errs []error) (notFound []Selector, err error) {
+	var reported []Selector
+	var reportedErrs []error
+
+	notFound = absentSelectors(failed, errs)
+	if !o.missingAsNil {
+		err = newMultiError(failed, errs)
+		goto end
+	}
+	for i, selector := range failed {
+		if isNotFound(errs[i]) {
+			valuesMap[selector] = Missing
+			continue
+		}
+		reported = append(reported, selector)
+		reportedErrs = append(reportedErrs, errs[i])
+	}
+	err = newMultiError(reported, reportedErrs)
+
+end:
+	return notFound, err
+}
+
+// readsWholeInput reports whether the input must be read in full before
+// extraction, rather than only up to a lone selector's value.
+func (o options) readsWholeInput() bool {
+	return o.tolerant || o.strictUTF8 || o.validateRemainder
+}
+
+// checkUTF8 reports invalid UTF-8 anywhere in rawBytes when WithStrictUTF8 is
+// set.
+func (o options) checkUTF8(rawBytes []byte) (err error) {
+	var offset int
+
+	if !o.strictUTF8 || utf8.Valid(rawBytes) {
+		goto end
+	}
+	for offset < len(rawBytes) {
+		r, size := utf8.DecodeRune(rawBytes[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			break
+		}
+		offset += size
+	}
+	err = NewErr(
+		ErrJSONStreamingParseFailed,
+		ErrJSONInvalidUTF8,
+		"byte_offset", int64(offset),
+	)
+
+end:
+	return err
+}
+
+// checkRemainder reports the first syntax error in rawBytes, or a second
+// top-level value, when WithValidateRemainder is set.
+func (o options) checkRemainder(rawBytes []byte) (err error) {
+	var decoder *jsontext.Decoder
+
+	if !o.validateRemainder {
+		goto end
+	}
+	decoder = o.newDecoder(bytes.NewReader(rawBytes))
+	err = decoder.SkipValue()
+	if err != nil {
+		err = tokenReadFailed(decoder, err)
+		goto end
+	}
+	err = checkAtEOF(decoder, rawBytes)
+
+end:
+	return err
+}
+
+// checkAtEOF reports anything but whitespace following the top-level value
+// decoder has just read from rawBytes: a syntax error, or a second value.
+func checkAtEOF(decoder *jsontext.Decoder, rawBytes []byte) (err error) {
+	// Any further value begins after the whitespace following the first
+	rest := rawBytes[decoder.InputOffset():]
+	offset := decoder.InputOffset() + int64(len(rest)-len(bytes.TrimLeft(rest, " \t\r\n")))
+
+	_, err = decoder.ReadToken()
+	switch {
+	case err == nil:
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			"byte_offset", offset,
+			"reason", "more than one top-level value",
+		)
+	case errors.Is(err, io.EOF):
+		err = nil
+	default:
+		err = tokenReadFailed(decoder, err)
+	}
+	return err
+}
+
+// tokenReadFailed returns the error for decoder failing to read a token with
+// err, at the offset of the syntax error where known.
+func tokenReadFailed(decoder *jsontext.Decoder, err error) error {
+	var syntaxErr *jsontext.SyntacticError
+
+	// The decoder's offset is that of the last token read, short of the error
+	offset := decoder.InputOffset()
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.ByteOffset
+	}
+	return NewErr(
+		ErrJSONStreamingParseFailed,
+		ErrJSONTokenReadFailed,
+		"byte_offset", offset,
+		err,
+	)
+}
+
+// scansWholeObjects reports whether objects along a path must be read in full
+// rather than only up to the key being navigated to.
+func (o options) scansWholeObjects() bool {
+	return o.strictKeys || o.rejectDuplicateKeys
+}
+
+// limitReader returns reader guarded by the input size limit, if any.
+func (o options) limitReader(reader io.Reader) io.Reader {
+	if o.maxInputBytes <= 0 {
+		return reader
+	}
+	return &limitedReader{reader: reader, remaining: o.maxInputBytes, limit: o.maxInputBytes}
+}
+
+// progressReader returns reader reporting its progress to the WithProgress
+// callback, if any.
+func (o options) progressReader(reader io.Reader) io.Reader {
+	if o.progress == nil {
+		return reader
+	}
+	return &progressReader{reader: reader, report: o.progress, every: o.progressEvery}
+}
+
+// checkInputSize returns an error if input of size bytes exceeds the limit.
+func (o options) checkInputSize(size int64) (err error) {
+	if o.maxInputBytes > 0 && size > o.maxInputBytes {
+		err = NewErr(
+			ErrJSONInputTooLarge,
+			"max_input_bytes", o.maxInputBytes,
+			"input_bytes", size,
+		)
+	}
+	return err
+}
+
+// newDecoder returns a decoder for reader that applies the duplicate key policy.
+func (o options) newDecoder(reader io.Reader) *jsontext.Decoder {
+	return jsontext.NewDecoder(reader, jsontext.AllowDuplicateNames(!o.rejectDuplicateKeys))
+}
+
+// newNavigationDecoder returns the Decoder selectors are navigated with over
+// the JSON read from reader.
+func (o options) newNavigationDecoder(reader io.Reader) Decoder {
+	if o.decoderFactory != nil {
+		return o.decoderFactory(reader)
+	}
+	return jsontextDecoder{o.newDecoder(reader)}
+}
+
+// keyMatches reports whether the object key matches the target segment.
+func (o options) keyMatches(key, target string) bool {
+	if o.caseInsensitiveKeys {
+		return strings.EqualFold(key, target)
+	}
+	return key == target
+}
+
+// foldKey returns key in a form where two keys compare equal exactly when
+// keyMatches would match them, for use as a map key.
+func (o options) foldKey(key string) string {
+	if !o.caseInsensitiveKeys {
+		return key
+	}
+	// Map every rune to the smallest rune it case-folds to, which is
+	// the equivalence strings.EqualFold applies
+	return strings.Map(func(r rune) rune {
+		folded := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			folded = min(folded, f)
+		}
+		return folded
+	}, key)
+}
+
+// unmarshalOptions returns the json/v2 options used to decode extracted values.
+func (o options) unmarshalOptions() jsonv2.Options {
+	var unmarshalers []*jsonv2.Unmarshalers
+
+	duplicates := jsontext.AllowDuplicateNames(!o.rejectDuplicateKeys)
+	if o.numbersAsString || o.smartNumbers {
+		unmarshalers = append(unmarshalers, jsonv2.UnmarshalFromFunc(func(decoder *jsontext.Decoder, v *any) error {
+			if decoder.PeekKind() != '0' {
+				return errors.ErrUnsupported
+			}
+			token, err := decoder.ReadToken()
+			if err != nil {
+				return err
+			}
+			if o.numbersAsString {
+				*v = token.String()
+				return nil
+			}
+			*v = narrowNumber(token.String())
+			return nil
+		}))
+	}
+	if o.trimStrings {
+		unmarshalers = append(unmarshalers, jsonv2.UnmarshalFromFunc(func(decoder *jsontext.Decoder, v *any) error {
+			if decoder.PeekKind() != '"' {
+				return errors.ErrUnsupported
+			}
+			token, err := decoder.ReadToken()
+			if err != nil {
+				return err
+			}
+			*v = strings.TrimSpace(token.String())
+			return nil
+		}))
+	}
+	if len(unmarshalers) == 0 && !o.rejectUnknownMembers {
+		return duplicates
+	}
+
+	joined := []jsonv2.Options{duplicates}
+	if o.rejectUnknownMembers {
+		joined = append(joined, jsonv2.RejectUnknownMembers(true))
+	}
+	if len(unmarshalers) > 0 {
+		joined = append(joined, jsonv2.WithUnmarshalers(jsonv2.JoinUnmarshalers(unmarshalers...)))
+	}
+	return jsonv2.JoinOptions(joined...)
+}
+
+// narrowNumber returns the JSON number text as an int64 when it's written as
+// an integer that fits, as a json.Number when it's an integer that doesn't,
+// and otherwise as a float64.
+func narrowNumber(text string) (number any) {
+	var parseErr error
+
+	if strings.ContainsAny(text, ".eE") {
+		// The decoder has already validated the number's syntax
+		number, _ = strconv.ParseFloat(text, 64)
+		goto end
+	}
+
+	number, parseErr = strconv.ParseInt(text, 10, 64)
+	if parseErr != nil {
+		number = json.Number(text)
+	}
+
+end:
+	return number
+}
+
+// progressReader calls report with the bytes read through it each time
+// another every bytes have been read, or after each read when every is less
+// than one. Reads stop short at each multiple of every, so none is passed over
+// however large the caller's buffer. A mutex keeps calls from overlapping when
+// a read abandoned on cancellation finishes in the background.
+type progressReader struct {
+	mu     sync.Mutex
+	reader io.Reader
+	report func(bytesRead int64)
+	every  int64
+	read   int64
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limit := int64(len(p))
+	if r.every > 0 {
+		limit = min(limit, r.every-r.read%r.every)
+	}
+	n, err = r.reader.Read(p[:limit])
+	r.read += int64(n)
+	if n > 0 && (r.every <= 0 || r.read%r.every == 0) {
+		r.report(r.read)
+	}
+	return n, err
+}
+
+// limitedReader reads at most limit bytes, failing with ErrJSONInputTooLarge
+// rather than reporting EOF once the input proves to be longer, so a stream
+// cut short by the limit is never mistaken for a complete one.
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (r *limitedReader) Read(p []byte) (n int, err error) {
+	var probe [1]byte
+
+	if r.remaining > 0 {
+		n, err = r.reader.Read(p[:min(int64(len(p)), r.remaining)])
+		r.remaining -= int64(n)
+		goto end
+	}
+
+	// The limit is reached, so the input is too large unless it ends here
+	n, err = r.reader.Read(probe[:])
+	for n == 0 && err == nil {
+		n, err = r.reader.Read(probe[:])
+	}
+	if n > 0 {
+		n = 0
+		err = NewErr(
+			ErrJSONInputTooLarge,
+			"max_input_bytes", r.limit,
+		)
+	}
+
+end:
+	return n, err
+}
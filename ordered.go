@@ -0,0 +1,33 @@
+package jsonxtractr
+
+// Result pairs a selector with the value extracted for it.
+type Result struct {
+	Selector Selector
+	Value    any
+}
+
+// ExtractOrdered is ExtractValuesFromBytes returning the values found as
+// results in the order their selectors were passed, rather than as a map,
+// so iterating them is deterministic. A selector passed more than once gives
+// one result, at its first position. Selectors not found are returned in the
+// same order, along with the error explaining them.
+func ExtractOrdered(jsonBytes []byte, selectors []Selector) (results []Result, notFound []Selector, err error) {
+	var valuesMap ValuesMap
+
+	valuesMap, notFound, err = ExtractValuesFromBytes(jsonBytes, selectors)
+	if valuesMap == nil {
+		goto end
+	}
+
+	results = make([]Result, 0, len(valuesMap))
+	for _, selector := range Selectors(selectors).Unique() {
+		value, ok := valuesMap[selector]
+		if !ok {
+			continue
+		}
+		results = append(results, Result{Selector: selector, Value: value})
+	}
+
+end:
+	return results, notFound, err
+}
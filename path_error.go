@@ -0,0 +1,91 @@
+package jsonxtractr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// PathError describes where a selector failed, exposing the context that is
+// otherwise only rendered into the error message. Retrieve it from any
+// extraction error with errors.As; the sentinels it accompanies still match
+// with errors.Is.
+type PathError struct {
+	// Selector is the selector being navigated.
+	Selector Selector
+	// Segment is the text of the segment that failed, if any.
+	Segment string
+	// SegmentPosition is the zero-based position of Segment in the selector.
+	SegmentPosition int
+	// PathProgress holds the segments successfully navigated before Segment.
+	PathProgress []string
+	// AvailableKeys lists the keys of the object in which Segment was not
+	// found, in document order. It is nil for other failures.
+	AvailableKeys []string
+	// Suggestion is the available key closest to a missing Segment, when one
+	// is near enough to likely be what was meant, e.g. "name" for "nam". It is
+	// empty otherwise.
+	Suggestion string
+	// ArrayLength is the length of the array in which an index was out of
+	// range. It is zero for other failures.
+	ArrayLength int
+	// ByteOffset is where in the input traversal stopped, or for an index
+	// out of range, where the array started. It is -1 when unknown.
+	ByteOffset int64
+	// Line and Column are the 1-based position of ByteOffset, with Column
+	// counted in bytes. Both are zero when ByteOffset is unknown or the input
+	// was streamed rather than held in memory.
+	Line   int
+	Column int
+	// Window holds the input around ByteOffset, bounded by
+	// WithErrorJSONMaxLen and redacted by WithErrorJSONRedactKeys, with tabs
+	// and line breaks shown as spaces. WindowPos is the position of ByteOffset
+	// within it. Window is empty when Line is zero.
+	Window    string
+	WindowPos int
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("JSON path %q failed at segment %d (%q)", e.Selector, e.SegmentPosition, e.Segment)
+}
+
+// pathError builds a PathError from the state's position and the failure's
+// byte offset along with any context found among the key-value pairs in parts.
+func (s *extractState) pathError(offset int64, parts []any) *PathError {
+	pathErr := &PathError{
+		Selector:        Selector(s.selector),
+		SegmentPosition: s.position,
+		PathProgress:    slices.Clone(s.pathProgress),
+		ByteOffset:      -1,
+	}
+	if s.position >= 0 && s.position < len(s.segments) {
+		pathErr.Segment = s.segments[s.position].text
+	}
+	if offset >= 0 {
+		pathErr.ByteOffset = offset
+	}
+	if offset >= 0 && len(s.raw()) > 0 {
+		pathErr.Line, pathErr.Column = s.lineColumn(offset)
+	}
+	pathErr.Window, pathErr.WindowPos = s.errorWindow(offset)
+
+	var missingKey string
+	var isMissing bool
+	for i := 0; i+1 < len(parts); i += 2 {
+		key, ok := parts[i].(string)
+		if !ok {
+			break
+		}
+		switch key {
+		case "missing_key":
+			missingKey, isMissing = parts[i+1].(string)
+		case "available_keys":
+			pathErr.AvailableKeys, _ = parts[i+1].([]string)
+		case "array_length":
+			pathErr.ArrayLength, _ = parts[i+1].(int)
+		}
+	}
+	if isMissing {
+		pathErr.Suggestion, _ = suggestKey(missingKey, pathErr.AvailableKeys)
+	}
+	return pathErr
+}
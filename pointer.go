@@ -0,0 +1,185 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// ExtractValueByPointer extracts a single value addressed by an RFC 6901 JSON
+// Pointer such as "/user/name" or "/scores/1". Reference tokens are unescaped
+// per the RFC, so "~1" selects a literal "/" and "~0" a literal "~". The empty
+// pointer "" selects the whole document.
+//
+// Unlike dot-notation selectors, every reference token is a literal, so "*"
+// names a member called "*" and an empty token names the empty-string key.
+func ExtractValueByPointer(jsonBytes []byte, pointer string) (value any, err error) {
+	var segments []segment
+	var state *extractState
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"pointer", pointer,
+		)
+		goto end
+	}
+
+	segments, err = parsePointer(pointer)
+	if err != nil {
+		goto end
+	}
+
+	state = newExtractState(options{}.newNavigationDecoder(bytes.NewReader(jsonBytes)), pointer, segments, jsonBytes)
+
+	// Navigate through each reference token
+	for i, seg := range state.segments {
+		state.position = i
+		err = state.navigateToSegment(seg)
+		if err != nil {
+			goto end
+		}
+		state.pathProgress = append(state.pathProgress, seg.text)
+	}
+
+	// Extract the final value
+	err = state.decoder.UnmarshalInto(&value)
+	if err != nil {
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONUnmarshalFailed,
+			err,
+		)
+	}
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONPointer,
+			"pointer", pointer,
+			err,
+		)
+	}
+	return value, err
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference
+// tokens. The empty pointer yields no tokens. A token addresses a member of an
+// object or, when an array index, an element of an array, while "*" and ""
+// remain literal.
+func parsePointer(pointer string) (segments []segment, err error) {
+	var tokens []string
+
+	segments = make([]segment, 0)
+	if pointer == "" {
+		goto end
+	}
+
+	if pointer[0] != '/' {
+		err = NewErr(
+			ErrJSONPointerInvalid,
+			"pointer", pointer,
+			"reason", "must be empty or begin with '/'",
+		)
+		goto end
+	}
+
+	tokens = strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		// Every '~' must begin a "~0" or "~1" escape sequence
+		for j := 0; j < len(token); j++ {
+			if token[j] != '~' {
+				continue
+			}
+			if j+1 < len(token) && (token[j+1] == '0' || token[j+1] == '1') {
+				j++
+				continue
+			}
+			err = NewErr(
+				ErrJSONPointerInvalid,
+				"pointer", pointer,
+				"token_position", i,
+				"reason", "'~' must be followed by '0' or '1'",
+			)
+			goto end
+		}
+		// Unescape "~1" before "~0" so "~01" becomes "~1" rather than "/"
+		token = strings.ReplaceAll(token, "~1", "/")
+		token = strings.ReplaceAll(token, "~0", "~")
+		segments = append(segments, segment{kind: pointerSegment, text: token})
+	}
+
+end:
+	return segments, err
+}
+
+// navigatePointerToken navigates a JSON Pointer reference token. Within an
+// array it must be an RFC 6901 index, so "01", "+1" and "-1" fail rather than
+// resolve as selector indexes would, and "-", the element past the end, is
+// out of range.
+func (s *extractState) navigatePointerToken(token string) (err error) {
+	var idx int
+	var parseErr error
+
+	kind := s.decoder.PeekKind()
+	s.numericKey = false
+	if kind != '[' {
+		err = s.navigateObjectKey(token)
+		goto end
+	}
+
+	idx, parseErr = strconv.Atoi(token)
+	switch {
+	case token == "-":
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_index", token,
+			"reason", "'-' refers to the nonexistent element after the last",
+		)
+	case !isPointerIndex(token) && parseErr == nil:
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPointerInvalid,
+			"token", token,
+			"reason", "array index must be \"0\" or digits without a sign or leading zero",
+		)
+	case !isPointerIndex(token):
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedObjectAtSegment,
+			"expected_type", "object",
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", s.valuePreview(s.unreadInput()),
+			"reason", indexExpected(token),
+		)
+	case parseErr != nil:
+		// Too many digits for an int, so beyond any array's end
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_index", token,
+		)
+	default:
+		err = s.navigateArrayIndex(idx)
+	}
+
+end:
+	return err
+}
+
+// isPointerIndex reports whether token is an RFC 6901 array index, matching
+// "0" or "[1-9][0-9]*".
+func isPointerIndex(token string) bool {
+	if token == "" || (token[0] == '0' && len(token) > 1) {
+		return false
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize bounds the buffers returned to bufferPool so that one
+// unusually large document doesn't stay pinned in memory for the life of the
+// process.
+const maxPooledBufferSize = 4 << 20
+
+// bufferPool holds the buffers used to read whole inputs into memory. Nothing
+// returned to callers may alias a pooled buffer: decoded values, error context
+// and raw values are always copied out before the buffer is released.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// readerPool holds the readers that decoders walk over in-memory JSON with.
+var readerPool = sync.Pool{
+	New: func() any { return new(bytes.Reader) },
+}
+
+// getBuffer returns an empty buffer from bufferPool.
+func getBuffer() *bytes.Buffer {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+// putBuffer returns buffer to bufferPool. The caller must not use buffer, or
+// any slice of its contents, afterwards.
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > maxPooledBufferSize {
+		goto end
+	}
+	buffer.Reset()
+	bufferPool.Put(buffer)
+end:
+	return
+}
+
+// getReader returns a reader from readerPool positioned at the start of data.
+func getReader(data []byte) *bytes.Reader {
+	reader := readerPool.Get().(*bytes.Reader)
+	reader.Reset(data)
+	return reader
+}
+
+// putReader returns reader to readerPool, dropping its reference to the data
+// it was reading so the pool never keeps that data alive.
+func putReader(reader *bytes.Reader) {
+	reader.Reset(nil)
+	readerPool.Put(reader)
+}
@@ -0,0 +1,491 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"slices"
+	"strconv"
+)
+
+// Project returns a copy of the JSON in jsonBytes keeping only the values
+// selected by selectors, along with the objects and arrays leading to them,
+// for instance to log just an allow-list of fields. Selected values are copied
+// as written, so numbers and strings keep their exact text, and members and
+// elements stay in document order. The result is compact, without
+// insignificant whitespace.
+//
+// An array keeps only the elements selected or leading to selected values, so
+// "items.1.id" gives {"items":[{"id":...}]} and the elements are renumbered.
+// Selectors may also contain wildcards, globs, regular expressions, filters,
+// ordinals and index lists, keeping everything they match.
+//
+// Paths that aren't in the document are omitted without error, as are objects
+// and arrays left with nothing selected, so selecting nothing that exists gives
+// an empty object or array, or null for a scalar document. RootSelector keeps
+// the whole document.
+func Project(jsonBytes []byte, selectors []Selector) (projected []byte, err error) {
+	var root *pathNode
+	var reader *bytes.Reader
+	var decoder *jsontext.Decoder
+	var ok bool
+	var kind jsontext.Kind
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selectors", selectors,
+		)
+		goto end
+	}
+
+	root, err = newPathTree(selectors)
+	if err != nil {
+		goto end
+	}
+
+	reader = getReader(jsonBytes)
+	decoder = options{}.newDecoder(reader)
+	kind = decoder.PeekKind()
+	projected, ok, err = projectValue(decoder, []*pathNode{root})
+	putReader(reader)
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selectors", selectors,
+			err,
+		)
+		goto end
+	}
+
+	if !ok {
+		switch kind {
+		case '{':
+			projected = []byte("{}")
+		case '[':
+			projected = []byte("[]")
+		default:
+			projected = []byte("null")
+		}
+	}
+
+end:
+	return projected, err
+}
+
+// pathNode is a segment of the paths given to Project or Redact, shared by
+// every path passing through it.
+type pathNode struct {
+	seg      segment
+	terminal bool // a path ends here
+	children []*pathNode
+}
+
+// newPathTree parses selectors into a tree whose root stands for the document.
+func newPathTree(selectors []Selector) (root *pathNode, err error) {
+	var segments []segment
+
+	root = &pathNode{}
+	for _, selector := range selectors {
+		segments, err = selector.parse()
+		if err != nil {
+			goto end
+		}
+		node := root
+		for _, seg := range segments {
+			node = node.child(seg)
+		}
+		node.terminal = true
+	}
+
+end:
+	return root, err
+}
+
+// child returns the node's child for seg, adding it if it's new.
+func (n *pathNode) child(seg segment) *pathNode {
+	for _, c := range n.children {
+		if c.seg.kind == seg.kind && c.seg.text == seg.text {
+			return c
+		}
+	}
+	c := &pathNode{seg: seg}
+	n.children = append(n.children, c)
+	return c
+}
+
+// isTerminal reports whether a path ends at the node.
+func (n *pathNode) isTerminal() bool {
+	return n.terminal
+}
+
+// memberChildren returns the children of nodes that match the object member
+// named key at zero-based position.
+func memberChildren(nodes []*pathNode, key string, position int) (matched []*pathNode) {
+	for _, node := range nodes {
+		for _, c := range node.children {
+			switch c.seg.kind {
+			case nameSegment:
+				if !c.seg.isEmpty() && c.seg.text == key {
+					matched = append(matched, c)
+				}
+			case keySegment:
+				if c.seg.text == key {
+					matched = append(matched, c)
+				}
+			case ordinalSegment:
+				if c.seg.ordinal == position {
+					matched = append(matched, c)
+				}
+			default:
+				if c.seg.matchesKey(key) {
+					matched = append(matched, c)
+				}
+			}
+		}
+	}
+	return matched
+}
+
+// elementChildren returns, for each element of an array, the children of
+// nodes that match it.
+func elementChildren(nodes []*pathNode, elements []jsontext.Value) (matched [][]*pathNode) {
+	matched = make([][]*pathNode, len(elements))
+	for _, node := range nodes {
+		for _, c := range node.children {
+			for _, idx := range c.seg.elementIndexes(elements) {
+				matched[idx] = append(matched[idx], c)
+			}
+		}
+	}
+	return matched
+}
+
+// elementIndexes returns the indexes of the elements the segment matches.
+func (seg segment) elementIndexes(elements []jsontext.Value) (indexes []int) {
+	switch seg.kind {
+	case nameSegment:
+		idx, err := strconv.Atoi(seg.text)
+		if err != nil {
+			break
+		}
+		if idx < 0 {
+			idx += len(elements)
+		}
+		if idx >= 0 && idx < len(elements) {
+			indexes = append(indexes, idx)
+		}
+	case indexListSegment:
+		for _, idx := range seg.indexes {
+			if idx < 0 {
+				idx += len(elements)
+			}
+			if idx >= 0 && idx < len(elements) && !slices.Contains(indexes, idx) {
+				indexes = append(indexes, idx)
+			}
+		}
+	case wildcardSegment:
+		for idx := range elements {
+			indexes = append(indexes, idx)
+		}
+	case filterSegment, filterAllSegment:
+		for idx, element := range elements {
+			if !seg.filter.matches(element) {
+				continue
+			}
+			indexes = append(indexes, idx)
+			if seg.kind == filterSegment {
+				break
+			}
+		}
+	}
+	return indexes
+}
+
+// readElements reads the elements of the array the decoder is positioned at.
+func readElements(decoder *jsontext.Decoder) (elements []jsontext.Value, err error) {
+	var element jsontext.Value
+
+	_, err = decoder.ReadToken()
+	if err != nil {
+		goto end
+	}
+	for decoder.PeekKind() != ']' {
+		element, err = decoder.ReadValue()
+		if err != nil {
+			goto end
+		}
+		elements = append(elements, element.Clone())
+	}
+	_, err = decoder.ReadToken()
+
+end:
+	if err != nil {
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			"reading", "array_element",
+			err,
+		)
+	}
+	return elements, err
+}
+
+// projectValue returns the compact JSON of what nodes select from the value
+// the decoder is positioned at, with ok false when they select nothing.
+func projectValue(decoder *jsontext.Decoder, nodes []*pathNode) (projected []byte, ok bool, err error) {
+	var value jsontext.Value
+	var keyToken jsontext.Token
+	var elements []jsontext.Value
+	var reader *bytes.Reader
+	var sub []byte
+	var subOK bool
+
+	switch {
+	case slices.ContainsFunc(nodes, (*pathNode).isTerminal):
+		value, err = decoder.ReadValue()
+		if err != nil {
+			break
+		}
+		value = value.Clone()
+		err = value.Compact()
+		projected, ok = value, true
+
+	case decoder.PeekKind() == '{':
+		_, err = decoder.ReadToken()
+		projected = append(projected, '{')
+		for position := 0; err == nil && decoder.PeekKind() != '}'; position++ {
+			keyToken, err = decoder.ReadToken()
+			if err != nil {
+				break
+			}
+			key := keyToken.String()
+			matched := memberChildren(nodes, key, position)
+			if len(matched) == 0 {
+				err = decoder.SkipValue()
+				continue
+			}
+			sub, subOK, err = projectValue(decoder, matched)
+			if err != nil {
+				goto end
+			}
+			if !subOK {
+				continue
+			}
+			if ok {
+				projected = append(projected, ',')
+			}
+			projected, _ = jsontext.AppendQuote(projected, key)
+			projected = append(projected, ':')
+			projected = append(projected, sub...)
+			ok = true
+		}
+		if err == nil {
+			_, err = decoder.ReadToken()
+		}
+		projected = append(projected, '}')
+
+	case decoder.PeekKind() == '[':
+		elements, err = readElements(decoder)
+		if err != nil {
+			goto end
+		}
+		projected = append(projected, '[')
+		for idx, matched := range elementChildren(nodes, elements) {
+			if len(matched) == 0 {
+				continue
+			}
+			reader = getReader(elements[idx])
+			sub, subOK, err = projectValue(options{}.newDecoder(reader), matched)
+			putReader(reader)
+			if err != nil {
+				goto end
+			}
+			if !subOK {
+				continue
+			}
+			if ok {
+				projected = append(projected, ',')
+			}
+			projected = append(projected, sub...)
+			ok = true
+		}
+		projected = append(projected, ']')
+
+	default:
+		err = decoder.SkipValue()
+	}
+
+	if err != nil {
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+	}
+end:
+	return projected, ok, err
+}
+
+// Redact is the complement of Project, returning a copy of the JSON in
+// jsonBytes without the values selected by selectors, for instance to strip
+// secrets before logging. Use RedactOpts with WithRedactAsNull to replace them
+// with null instead. The rest of the document is copied as written, in
+// document order, and the result is compact.
+//
+// Removing an array element renumbers the elements after it, as in
+// {"items":[1,2,3]} becoming {"items":[1,3]} without "items.1". The indexes
+// in selectors always refer to the original document, so removing both
+// "items.0" and "items.1" removes the first two elements. Paths that aren't in
+// the document are ignored without error. Selectors may contain the same
+// patterns Project accepts, and RootSelector redacts the whole document to
+// null.
+func Redact(jsonBytes []byte, selectors []Selector) ([]byte, error) {
+	return RedactOpts(jsonBytes, selectors)
+}
+
+// RedactOpts is Redact with options.
+func RedactOpts(jsonBytes []byte, selectors []Selector, opts ...Option) (redacted []byte, err error) {
+	var root *pathNode
+	var reader *bytes.Reader
+	var o options
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selectors", selectors,
+		)
+		goto end
+	}
+
+	root, err = newPathTree(selectors)
+	if err != nil {
+		goto end
+	}
+
+	if root.terminal {
+		redacted = []byte("null")
+		goto end
+	}
+
+	o = newOptions(opts)
+	reader = getReader(jsonBytes)
+	redacted, err = redactValue(o.newDecoder(reader), []*pathNode{root}, o)
+	putReader(reader)
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selectors", selectors,
+			err,
+		)
+	}
+
+end:
+	return redacted, err
+}
+
+// redactValue returns the compact JSON of the value the decoder is positioned
+// at with the members and elements that nodes' children end at removed, or
+// replaced by null when opts say so.
+func redactValue(decoder *jsontext.Decoder, nodes []*pathNode, opts options) (redacted []byte, err error) {
+	var value jsontext.Value
+	var keyToken jsontext.Token
+	var elements []jsontext.Value
+	var reader *bytes.Reader
+	var sub []byte
+	var written bool
+
+	switch decoder.PeekKind() {
+	case '{':
+		_, err = decoder.ReadToken()
+		redacted = append(redacted, '{')
+		for position := 0; err == nil && decoder.PeekKind() != '}'; position++ {
+			keyToken, err = decoder.ReadToken()
+			if err != nil {
+				break
+			}
+			key := keyToken.String()
+			matched := memberChildren(nodes, key, position)
+			switch {
+			case slices.ContainsFunc(matched, (*pathNode).isTerminal):
+				err = decoder.SkipValue()
+				sub = nil
+				if opts.redactAsNull {
+					sub = []byte("null")
+				}
+			default:
+				sub, err = redactValue(decoder, matched, opts)
+				if err != nil {
+					goto end
+				}
+			}
+			if err != nil || sub == nil {
+				continue
+			}
+			if written {
+				redacted = append(redacted, ',')
+			}
+			redacted, _ = jsontext.AppendQuote(redacted, key)
+			redacted = append(redacted, ':')
+			redacted = append(redacted, sub...)
+			written = true
+		}
+		if err == nil {
+			_, err = decoder.ReadToken()
+		}
+		redacted = append(redacted, '}')
+
+	case '[':
+		elements, err = readElements(decoder)
+		if err != nil {
+			goto end
+		}
+		redacted = append(redacted, '[')
+		for idx, matched := range elementChildren(nodes, elements) {
+			switch {
+			case slices.ContainsFunc(matched, (*pathNode).isTerminal):
+				sub = nil
+				if opts.redactAsNull {
+					sub = []byte("null")
+				}
+			default:
+				reader = getReader(elements[idx])
+				sub, err = redactValue(opts.newDecoder(reader), matched, opts)
+				putReader(reader)
+				if err != nil {
+					goto end
+				}
+			}
+			if sub == nil {
+				continue
+			}
+			if written {
+				redacted = append(redacted, ',')
+			}
+			redacted = append(redacted, sub...)
+			written = true
+		}
+		redacted = append(redacted, ']')
+
+	default:
+		value, err = decoder.ReadValue()
+		if err != nil {
+			break
+		}
+		redacted = append(redacted, value...)
+		err = (*jsontext.Value)(&redacted).Compact()
+	}
+
+	if err != nil {
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+	}
+end:
+	return redacted, err
+}
@@ -0,0 +1,146 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"slices"
+)
+
+// redactedValue replaces the values of redacted members.
+const redactedValue = `"***"`
+
+// redactJSON returns a copy of raw in which the value of every object member
+// named by one of keys is replaced with redactedValue. It scans bytes rather
+// than decoding, so it also redacts JSON that is malformed or cut short, as is
+// often the case when reporting an error; a value left unterminated is
+// redacted through to the end of raw.
+func redactJSON(raw []byte, keys []string) []byte {
+	redacted, _ := redactJSONAt(raw, keys, 0)
+	return redacted
+}
+
+// redactJSONAt is redactJSON that also maps offset within raw to the matching
+// offset within the redacted copy. An offset inside a redacted value maps to
+// the start of redactedValue.
+func redactJSONAt(raw []byte, keys []string, offset int) (redacted []byte, mapped int) {
+	var out bytes.Buffer
+
+	mapped = -1
+	out.Grow(len(raw))
+	for pos := 0; pos < len(raw); {
+		if raw[pos] != '"' {
+			if pos == offset {
+				mapped = out.Len()
+			}
+			out.WriteByte(raw[pos])
+			pos++
+			continue
+		}
+
+		end := skipJSONString(raw, pos)
+		if offset >= pos && offset < end {
+			mapped = out.Len() + offset - pos
+		}
+		out.Write(raw[pos:end])
+
+		// A string followed by a colon is a member name
+		colon := skipJSONSpace(raw, end)
+		if colon >= len(raw) || raw[colon] != ':' || !slices.Contains(keys, unquoteJSONString(raw[pos:end])) {
+			pos = end
+			continue
+		}
+		valueStart := skipJSONSpace(raw, colon+1)
+		if offset >= end && offset < valueStart {
+			mapped = out.Len() + offset - end
+		}
+		out.Write(raw[end:valueStart])
+		pos = skipJSONValue(raw, valueStart)
+		if offset >= valueStart && offset < pos {
+			mapped = out.Len()
+		}
+		if valueStart < len(raw) {
+			out.WriteString(redactedValue)
+		}
+	}
+	if mapped < 0 {
+		// The offset lies at or beyond the end of raw
+		mapped = out.Len() + offset - len(raw)
+	}
+	return out.Bytes(), mapped
+}
+
+// skipJSONString returns the offset just past the string starting with the
+// quote at pos, or len(raw) if it is unterminated.
+func skipJSONString(raw []byte, pos int) int {
+	for i := pos + 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(raw)
+}
+
+// skipJSONSpace returns the offset of the first non-whitespace byte at or
+// after pos.
+func skipJSONSpace(raw []byte, pos int) int {
+	for pos < len(raw) && isJSONSpace(raw[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// isJSONSpace reports whether b is whitespace between JSON tokens.
+func isJSONSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\r', '\n':
+		return true
+	}
+	return false
+}
+
+// skipJSONValue returns the offset just past the value starting at pos, or
+// len(raw) if it is unterminated.
+func skipJSONValue(raw []byte, pos int) int {
+	var depth int
+
+	for pos < len(raw) {
+		switch raw[pos] {
+		case '"':
+			pos = skipJSONString(raw, pos)
+			if depth == 0 {
+				return pos
+			}
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				// The end of the enclosing container ends a scalar
+				return pos
+			}
+			depth--
+			if depth == 0 {
+				return pos + 1
+			}
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return pos
+			}
+		}
+		pos++
+	}
+	return pos
+}
+
+// unquoteJSONString returns the text of a JSON string, or the string as
+// written if its escapes are malformed or it is unterminated.
+func unquoteJSONString(quoted []byte) string {
+	text, err := jsontext.AppendUnquote(nil, quoted)
+	if err != nil {
+		return string(quoted)
+	}
+	return string(text)
+}
@@ -0,0 +1,78 @@
+package jsonxtractr
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxStatusSnippetLen bounds the response body included with ErrJSONHTTPStatus.
+const maxStatusSnippetLen = 200
+
+// ExtractValueFromResponse extracts a single value from the JSON body of resp,
+// closing the body once done. A non-2xx status returns an error wrapping
+// ErrJSONHTTPStatus, carrying "status_code", "status" and the start of the
+// body as "body_snippet", without parsing the body as JSON. A body with a
+// Content-Encoding of gzip is decompressed as it's read.
+func ExtractValueFromResponse(resp *http.Response, selector Selector) (value any, err error) {
+	var body io.Reader
+	var gz *gzipReader
+	var snippet []byte
+	var gzipped bool
+
+	if resp == nil || resp.Body == nil {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+	defer closeResponseBody(resp)
+
+	body = resp.Body
+	gzipped = strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip")
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// The snippet is best effort, so a body that fails to decompress or
+		// read just leaves it short or empty
+		if gzipped {
+			gz, _ = newGzipReader(body)
+		}
+		if gz != nil {
+			defer gz.close()
+			body = gz
+		}
+		snippet, _ = io.ReadAll(io.LimitReader(body, maxStatusSnippetLen))
+		err = NewErr(
+			ErrJSONHTTPStatus,
+			"status_code", resp.StatusCode,
+			"status", resp.Status,
+			"body_snippet", string(snippet),
+		)
+		goto end
+	}
+
+	if gzipped {
+		gz, err = newGzipReader(body)
+		if err != nil {
+			goto end
+		}
+		defer gz.close()
+		body = gz
+	}
+
+	value, err = ExtractValueFromReader(body, selector)
+
+end:
+	if err != nil && resp != nil && resp.Request != nil && resp.Request.URL != nil {
+		err = WithErr(err, "url", resp.Request.URL.Redacted())
+	}
+	return value, err
+}
+
+// closeResponseBody closes the body of resp. Everything needed has been read
+// by then, so a failure to close it is of no consequence.
+func closeResponseBody(resp *http.Response) {
+	_ = resp.Body.Close()
+}
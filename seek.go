@@ -0,0 +1,45 @@
+package jsonxtractr
+
+import (
+	"encoding/json/jsontext"
+	"io"
+)
+
+// SeekTo navigates selector over the JSON read from reader and returns the
+// decoder positioned at the start of the selected value, for the caller to
+// read it with, e.g. token by token to stream a large array element by
+// element. Only the input up to the value has been read. A selector whose
+// path doesn't exist fails as ExtractValueFromReader does, with
+// ErrJSONPathSegmentNotFound or ErrJSONIndexOutOfRange, and one that can
+// match several values fails with ErrJSONSelectorMultiMatch.
+func SeekTo(reader io.Reader, selector Selector) (decoder *jsontext.Decoder, err error) {
+	var state *extractState
+
+	if reader == nil {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(reader, selector, nil, options{})
+	if err != nil {
+		goto end
+	}
+
+	// Without a decoder factory the decoder is always a jsontext.Decoder,
+	// even once replaced to read a value retained from earlier input
+	decoder = state.decoder.(jsontextDecoder).Decoder
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONByReader,
+			"selector", selector,
+			err,
+		)
+	}
+	return decoder, err
+}
@@ -0,0 +1,761 @@
+package jsonxtractr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// segmentKind distinguishes how a parsed selector segment is matched.
+type segmentKind int
+
+const (
+	// nameSegment is an unquoted segment; numeric names address array elements
+	// but still name the members of objects
+	nameSegment segmentKind = iota
+	// keySegment is a quoted segment, which always names an object member
+	keySegment
+	// wildcardSegment is a bare "*", matching every member or element
+	wildcardSegment
+	// globSegment is an unquoted segment containing '*' or '?', such as
+	// "cpu_*", matching every object member whose key the pattern matches
+	globSegment
+	// regexSegment is a segment wrapped in slashes, such as "/^id_\d+$/",
+	// matching every object member whose key the regular expression matches
+	regexSegment
+	// filterSegment is a condition such as `#(role=="admin")`, matching the
+	// first array element that is an object satisfying it
+	filterSegment
+	// filterAllSegment is a condition such as `#(role=="admin")#`, matching
+	// every array element that is an object satisfying it
+	filterAllSegment
+	// ordinalSegment is a position such as "#1", matching the object member at
+	// that zero-based position in document order, whatever its key
+	ordinalSegment
+	// indexListSegment is a list of array indexes such as "[0,2,-1]",
+	// selecting a new array of those elements in the order listed
+	indexListSegment
+	// pointerSegment is a JSON Pointer reference token, which names an object
+	// member or, only in the RFC 6901 form "0" or "[1-9][0-9]*", an element
+	pointerSegment
+)
+
+// segment is one parsed step of a selector path.
+type segment struct {
+	kind    segmentKind
+	text    string
+	re      *regexp.Regexp // compiled text of a regexSegment
+	filter  *elementFilter // parsed condition of a filterSegment
+	ordinal int            // parsed position of an ordinalSegment
+	indexes []int          // parsed indexes of an indexListSegment
+}
+
+// isEmpty reports whether the segment is an empty unquoted segment, as in
+// "a..b". A quoted empty segment names the empty-string key instead.
+func (seg segment) isEmpty() bool {
+	return seg.kind == nameSegment && seg.text == ""
+}
+
+// isMultiMatch reports whether the segment may match more than one member.
+func (seg segment) isMultiMatch() bool {
+	switch seg.kind {
+	case wildcardSegment, globSegment, regexSegment, filterAllSegment:
+		return true
+	}
+	return false
+}
+
+// resolvesAlone reports whether the segment must be resolved by navigating to
+// it on its own rather than in a shared walk: a filter reads whole elements
+// an ordinal counts members and an index list gathers several elements, none
+// of which the shared walk does.
+func (seg segment) resolvesAlone() bool {
+	switch seg.kind {
+	case filterSegment, ordinalSegment, indexListSegment:
+		return true
+	}
+	return false
+}
+
+// matchesKey reports whether the segment matches the object key named key.
+// A glob must match the whole key, with '*' matching any run of characters
+// and '?' any single one, while a regular expression is only anchored where
+// it says so.
+func (seg segment) matchesKey(key string) (matches bool) {
+	switch seg.kind {
+	case wildcardSegment:
+		matches = true
+	case globSegment:
+		matches = globMatch(seg.text, key)
+	case regexSegment:
+		matches = seg.re.MatchString(key)
+	}
+	return matches
+}
+
+// globMatch reports whether name matches pattern in full, where '*' matches
+// any sequence of characters, including none, and '?' matches any one.
+func globMatch(pattern, name string) bool {
+	var starPattern, starName int
+
+	starPattern = -1
+	p, n := 0, 0
+	for n < len(name) {
+		if p < len(pattern) {
+			c, size := utf8.DecodeRuneInString(pattern[p:])
+			switch c {
+			case '*':
+				// Remember where to resume if the rest fails to match
+				starPattern, starName = p, n
+				p += size
+				continue
+			case '?':
+				_, nameSize := utf8.DecodeRuneInString(name[n:])
+				p += size
+				n += nameSize
+				continue
+			default:
+				if strings.HasPrefix(name[n:], pattern[p:p+size]) {
+					p += size
+					n += size
+					continue
+				}
+			}
+		}
+		if starPattern < 0 {
+			return false
+		}
+		// Let the last '*' absorb one more character and try again
+		_, nameSize := utf8.DecodeRuneInString(name[starName:])
+		starName += nameSize
+		p, n = starPattern+1, starName
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// RootSelector selects the whole document. A key literally named "$" can still
+// be selected by quoting or escaping it, as `"$"` or `\$`.
+const RootSelector Selector = "$"
+
+// selectorQuote begins and ends a quoted segment, e.g. `"a.b".c`.
+const selectorQuote = '"'
+
+// selectorEscape makes the following character literal, e.g. `a\.b.c`.
+const selectorEscape = '\\'
+
+// regexDelimiter begins and ends a regular expression segment, e.g. `/^id_/`.
+const regexDelimiter = '/'
+
+// ordinalPrefix begins an object member ordinal segment, e.g. "#1".
+const ordinalPrefix = '#'
+
+// parseSelector splits a selector into its segments. Segments are separated
+// by '.', and a segment wrapped in double quotes is taken literally as a
+// single object key, so `"a.b".c` selects key "a.b" and then key "c". Outside
+// quotes a backslash escapes the following character, so `a\.b.c` selects the
+// same path. Within quotes it does the same, e.g. `"say \"hi\""`.
+//
+// A segment wrapped in slashes, such as `/^id_\d+$/`, is a regular expression
+// matched against object keys; within it a backslash is kept as written except
+// that `\/` stands for a literal slash. The expression is compiled here so an
+// invalid one is reported before any JSON is read.
+//
+// A segment such as `#(role=="admin")` is a filter selecting the first array
+// element whose named member compares true against a string or number
+// literal, with any of the operators ==, !=, <, >, <= and >=. Followed by '#',
+// as in `#(role=="admin")#`, it selects every such element instead.
+//
+// Brackets directly after a segment give the next segment in JSONPath style:
+// an index as in "items[0]", or a key quoted with single or double quotes as
+// in "user['name']" or `user["a.b"]`, so "items[0]['name']" is the same path
+// as "items.0.name". Within the quotes a backslash escapes the following
+// character. A bracketed quoted key may also start a segment, as in
+// "['a.b'].c". Brackets holding anything else are invalid.
+//
+// A bracketed list of array indexes, such as "[0,2,-1]", selects a new array
+// of those elements in the order listed, repeating any listed twice. Since it
+// selects a new array rather than a value in the document, it must be the
+// last segment. Written after a dot, as in "a.[0]", a list of one index is
+// still a list, whereas "a[0]" selects the element itself.
+//
+// A segment of '#' followed by digits, such as "#1", selects an object's member
+// by its zero-based position in document order rather than by its key.
+//
+// Empty unquoted segments (as in "a..b") are returned as-is so traversal can
+// report them at their position in the path. RootSelector parses to no
+// segments at all.
+func parseSelector(selector string) (segments []segment, err error) {
+	var seg segment
+	var next int
+
+	segments = make([]segment, 0, strings.Count(selector, ".")+1)
+	if Selector(selector) == RootSelector {
+		goto end
+	}
+	for pos := 0; ; pos = next + 1 {
+		seg, next, err = parseSegment(selector, pos)
+		if err != nil {
+			goto end
+		}
+		segments = append(segments, seg)
+
+		// Brackets may follow a segment directly, as in "items[0]['name']"
+		for next < len(selector) && selector[next] == indexListPrefix {
+			seg, next, err = parseBracketSegment(selector, next)
+			if err != nil {
+				goto end
+			}
+			segments = append(segments, seg)
+		}
+		if next >= len(selector) {
+			break
+		}
+	}
+
+end:
+	return segments, err
+}
+
+// slashesAsDots returns selector with each '/' that would separate segments
+// under WithAnySeparator replaced by '.'. Those within quotes, brackets or a
+// filter's parentheses, or escaped with a backslash, are left as written.
+func slashesAsDots(selector Selector) Selector {
+	var quote byte
+	var depth int
+
+	rewritten := []byte(selector)
+	for i := 0; i < len(rewritten); i++ {
+		c := rewritten[i]
+		switch {
+		case c == selectorEscape:
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == selectorQuote, c == '\'' && depth > 0:
+			quote = c
+		case c == indexListPrefix, c == '(':
+			depth++
+		case c == ']', c == ')':
+			depth--
+		case c == '/' && depth == 0:
+			rewritten[i] = '.'
+		}
+	}
+	return Selector(rewritten)
+}
+
+// Validate checks the selector's syntax without any JSON input, returning an
+// error wrapping ErrJSONSelectorInvalid along with ErrJSONValueSelectorCannotBeEmpty,
+// ErrJSONPathContainsEmptySegment, ErrJSONSelectorUnbalancedQuote,
+// ErrJSONSelectorDanglingEscape, ErrJSONSelectorRegexInvalid,
+// ErrJSONSelectorFilterInvalid, ErrJSONSelectorIndexListInvalid or
+// ErrJSONSelectorBracketInvalid. A selector that validates may still fail to
+// match a given document. A recursive descent such as "users..email" is valid,
+// although only ExtractMatches and its kin expand it.
+func (s Selector) Validate() error {
+	_, err := s.parseMatching()
+	return err
+}
+
+// Segments returns the selector's segments with quotes and escapes resolved,
+// e.g. ["a.b", "c"] for `"a.b".c`, or the errors Validate returns. RootSelector
+// has no segments. A quoted numeric segment such as `"0"` always names an
+// object key, which the returned text alone no longer shows, and a recursive
+// descent is an empty segment, e.g. ["users", "", "email"] for "users..email".
+func (s Selector) Segments() (texts []string, err error) {
+	var segments []segment
+
+	segments, err = s.parseMatching()
+	if err != nil {
+		goto end
+	}
+	texts = segmentTexts(segments)
+
+end:
+	return texts, err
+}
+
+// HasWildcard reports whether the selector can match more than one value,
+// having a wildcard, glob or regular expression segment, a filter ending in
+// '#' that selects every matching element, or a recursive descent such as
+// "users..email". Those fail single-value extraction such as
+// ExtractValueFromBytes with ErrJSONSelectorMultiMatch, and need
+// ExtractMatches instead. A filter without the '#' selects only the first
+// matching element, so doesn't count. It returns the errors Validate returns
+// for an invalid selector.
+func (s Selector) HasWildcard() (has bool, err error) {
+	var segments []segment
+
+	segments, err = s.parseMatching()
+	if err != nil {
+		goto end
+	}
+	for i, seg := range segments {
+		if seg.isMultiMatch() || isDescent(segments, i) {
+			has = true
+			break
+		}
+	}
+
+end:
+	return has, err
+}
+
+// Child returns the selector extended by the object key named key, quoting the
+// key where needed so it is always taken literally, e.g. `a."b.c"` for
+// Selector("a").Child("b.c"). An invalid selector stays invalid.
+func (s Selector) Child(key string) Selector {
+	return s.append(segment{kind: keySegment, text: key})
+}
+
+// Index returns the selector extended by the array index i, where negative
+// indexes count back from the end of the array.
+func (s Selector) Index(i int) Selector {
+	return s.append(segment{kind: nameSegment, text: strconv.Itoa(i)})
+}
+
+// Parent returns the selector without its last segment, or RootSelector for a
+// selector with one segment. RootSelector and invalid selectors are returned
+// unchanged.
+func (s Selector) Parent() (parent Selector) {
+	segments, err := parseSelector(string(s))
+	if err != nil || len(segments) == 0 {
+		parent = s
+		goto end
+	}
+	parent = formatSelector(segments[:len(segments)-1])
+
+end:
+	return parent
+}
+
+// append returns the selector extended by seg.
+func (s Selector) append(seg segment) (extended Selector) {
+	segments, err := parseSelector(string(s))
+	if err != nil {
+		// Keep the selector as written so the original error still surfaces
+		extended = s + "." + Selector(seg.format())
+		goto end
+	}
+	extended = formatSelector(append(segments, seg))
+
+end:
+	return extended
+}
+
+// parse parses the selector, rejecting empty selectors and empty segments
+// that traversal would otherwise only report on reaching them, including
+// those beginning a recursive descent, for callers that can't descend.
+func (s Selector) parse() (segments []segment, err error) {
+	return s.parseSegments(false)
+}
+
+// parseMatching is parse accepting the empty segments that begin a recursive
+// descent, as ExtractMatches expands them, e.g. in "users..email".
+func (s Selector) parseMatching() (segments []segment, err error) {
+	return s.parseSegments(true)
+}
+
+// parseSegments implements parse and, when descents is set, parseMatching.
+func (s Selector) parseSegments(descents bool) (segments []segment, err error) {
+	if len(s) == 0 {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONValueSelectorCannotBeEmpty,
+		)
+		goto end
+	}
+
+	segments, err = parseSelector(string(s))
+	if err != nil {
+		goto end
+	}
+
+	for i, seg := range segments {
+		if !seg.isEmpty() || descents && isDescent(segments, i) {
+			continue
+		}
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONPathContainsEmptySegment,
+			"selector", s,
+			"segment_position", i,
+		)
+		goto end
+	}
+
+end:
+	if err != nil {
+		segments = nil
+	}
+	return segments, err
+}
+
+// parseSegment parses the segment beginning at byte offset pos, returning it
+// along with the offset of the '.' that ends it, or len(selector) at the end.
+func parseSegment(selector string, pos int) (seg segment, next int, err error) {
+	var text strings.Builder
+
+	if strings.HasPrefix(selector[pos:], filterPrefix) {
+		seg, next, err = parseFilterSegment(selector, pos)
+		goto end
+	}
+
+	if pos < len(selector) && selector[pos] == regexDelimiter {
+		seg, next, err = parseRegexSegment(selector, pos)
+		goto end
+	}
+
+	if pos+1 < len(selector) && selector[pos] == indexListPrefix && isBracketQuote(selector[pos+1]) {
+		seg, next, err = parseBracketSegment(selector, pos)
+		goto end
+	}
+
+	if pos < len(selector) && selector[pos] == indexListPrefix {
+		seg, next, err = parseIndexListSegment(selector, pos)
+		goto end
+	}
+
+	if pos >= len(selector) || selector[pos] != selectorQuote {
+		seg, next, err = parseUnquotedSegment(selector, pos)
+		goto end
+	}
+
+	for next = pos + 1; next < len(selector); next++ {
+		c := selector[next]
+		if c == selectorEscape && next+1 < len(selector) {
+			next++
+			text.WriteByte(selector[next])
+			continue
+		}
+		if c == selectorQuote {
+			break
+		}
+		text.WriteByte(c)
+	}
+
+	if next >= len(selector) {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorUnbalancedQuote,
+			"selector", selector,
+			"quote_offset", pos,
+		)
+		goto end
+	}
+
+	// Step past the closing quote, which must end the segment
+	next++
+	if next < len(selector) && selector[next] != '.' && selector[next] != indexListPrefix {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			"selector", selector,
+			"offset", next,
+			"reason", "expected '.' or '[' after closing quote",
+		)
+		goto end
+	}
+	seg = segment{kind: keySegment, text: text.String()}
+
+end:
+	return seg, next, err
+}
+
+// parseRegexSegment parses a regular expression segment whose opening slash
+// is at byte offset pos, compiling it with the regexp package.
+func parseRegexSegment(selector string, pos int) (seg segment, next int, err error) {
+	var text strings.Builder
+	var re *regexp.Regexp
+
+	for next = pos + 1; next < len(selector); next++ {
+		c := selector[next]
+		if c == selectorEscape && next+1 < len(selector) {
+			next++
+			if selector[next] != regexDelimiter {
+				text.WriteByte(c)
+			}
+			text.WriteByte(selector[next])
+			continue
+		}
+		if c == regexDelimiter {
+			break
+		}
+		text.WriteByte(c)
+	}
+
+	if next >= len(selector) {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorRegexInvalid,
+			"selector", selector,
+			"regex_offset", pos,
+			"reason", "missing closing '/'",
+		)
+		goto end
+	}
+
+	// Step past the closing slash, which must end the segment
+	next++
+	if next < len(selector) && selector[next] != '.' {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorRegexInvalid,
+			"selector", selector,
+			"offset", next,
+			"reason", "expected '.' after closing '/'",
+		)
+		goto end
+	}
+
+	re, err = regexp.Compile(text.String())
+	if err != nil {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorRegexInvalid,
+			"selector", selector,
+			"regex_offset", pos,
+			err,
+		)
+		goto end
+	}
+	seg = segment{kind: regexSegment, text: text.String(), re: re}
+
+end:
+	return seg, next, err
+}
+
+// isBracketQuote reports whether c may quote a bracketed key.
+func isBracketQuote(c byte) bool {
+	return c == '\'' || c == selectorQuote
+}
+
+// parseBracketSegment parses the bracket whose '[' is at byte offset pos,
+// holding a key in single or double quotes, an array index, or a list of
+// indexes, returning the offset just past the ']'. Only a '.' or another
+// bracket may follow it.
+func parseBracketSegment(selector string, pos int) (seg segment, next int, err error) {
+	var text strings.Builder
+	var idx int
+	var reason string
+
+	if pos+1 < len(selector) && isBracketQuote(selector[pos+1]) {
+		quote := selector[pos+1]
+		for next = pos + 2; next < len(selector); next++ {
+			c := selector[next]
+			if c == selectorEscape && next+1 < len(selector) {
+				next++
+				text.WriteByte(selector[next])
+				continue
+			}
+			if c == quote {
+				break
+			}
+			text.WriteByte(c)
+		}
+		next++
+		if next >= len(selector) || selector[next] != indexListSuffix {
+			reason = "expected closing quote and ']'"
+			goto end
+		}
+		seg = segment{kind: keySegment, text: text.String()}
+		next++
+		goto follow
+	}
+
+	next = strings.IndexByte(selector[pos:], indexListSuffix)
+	if next < 0 {
+		reason = "missing closing ']'"
+		goto end
+	}
+	next += pos
+	if strings.Contains(selector[pos:next], ",") {
+		seg, next, err = parseIndexListSegment(selector, pos)
+		goto end
+	}
+	idx, err = strconv.Atoi(strings.TrimSpace(selector[pos+1 : next]))
+	if err != nil {
+		err = nil
+		reason = "expected an integer index or a quoted key"
+		goto end
+	}
+	seg = segment{kind: nameSegment, text: strconv.Itoa(idx)}
+	next++
+
+follow:
+	if next < len(selector) && selector[next] != '.' && selector[next] != indexListPrefix {
+		reason = "expected '.' or '[' after ']'"
+	}
+
+end:
+	if reason != "" {
+		err = NewErr(
+			ErrJSONSelectorInvalid,
+			ErrJSONSelectorBracketInvalid,
+			"selector", selector,
+			"bracket_offset", pos,
+			"reason", reason,
+		)
+	}
+	return seg, next, err
+}
+
+// parseUnquotedSegment parses an unquoted segment beginning at byte offset
+// pos. A backslash escapes the following character, so `a\.b` names the key
+// "a.b" and `a\\b` the key `a\b`. An unescaped '[' ends the segment and opens
+// a bracket. Escaped segments are always object keys,
+// which lets `\*` name the literal key "*". An unescaped segment containing
+// '*' or '?' is a glob, so a key such as "cpu_*" must be quoted or escaped to
+// be selected literally.
+func parseUnquotedSegment(selector string, pos int) (seg segment, next int, err error) {
+	var text strings.Builder
+	var escaped bool
+
+	for next = pos; next < len(selector); next++ {
+		c := selector[next]
+		if c == '.' || c == indexListPrefix {
+			break
+		}
+		if c != selectorEscape {
+			text.WriteByte(c)
+			continue
+		}
+		if next+1 >= len(selector) {
+			err = NewErr(
+				ErrJSONSelectorInvalid,
+				ErrJSONSelectorDanglingEscape,
+				"selector", selector,
+				"escape_offset", next,
+			)
+			goto end
+		}
+		next++
+		text.WriteByte(selector[next])
+		escaped = true
+	}
+
+	switch ordinal, isOrdinal := parseOrdinal(text.String()); {
+	case escaped:
+		seg = segment{kind: keySegment, text: text.String()}
+	case isOrdinal:
+		seg = segment{kind: ordinalSegment, text: text.String(), ordinal: ordinal}
+	case text.String() == "*":
+		seg = segment{kind: wildcardSegment, text: "*"}
+	case strings.ContainsAny(text.String(), "*?"):
+		seg = segment{kind: globSegment, text: text.String()}
+	default:
+		seg = segment{kind: nameSegment, text: text.String()}
+	}
+
+end:
+	return seg, next, err
+}
+
+// parseOrdinal parses text as an ordinal segment, '#' followed by one or more
+// decimal digits, returning the position it selects.
+func parseOrdinal(text string) (ordinal int, ok bool) {
+	var err error
+
+	if len(text) < 2 || text[0] != ordinalPrefix {
+		goto end
+	}
+	for _, c := range text[1:] {
+		if c < '0' || c > '9' {
+			goto end
+		}
+	}
+	ordinal, err = strconv.Atoi(text[1:])
+	ok = err == nil
+
+end:
+	return ordinal, ok
+}
+
+// formatSelector joins segments back into a selector, quoting any key that
+// would otherwise be parsed differently.
+func formatSelector(segments []segment) Selector {
+	var sb strings.Builder
+
+	if len(segments) == 0 {
+		return RootSelector
+	}
+	for i, seg := range segments {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(seg.format())
+	}
+	return Selector(sb.String())
+}
+
+// format returns the segment as it would be written in a selector.
+func (seg segment) format() (s string) {
+	var sb strings.Builder
+
+	switch {
+	case seg.kind == wildcardSegment:
+		s = "*"
+		goto end
+	case seg.kind == regexSegment:
+		s = "/" + strings.ReplaceAll(seg.text, "/", `\/`) + "/"
+		goto end
+	case seg.kind == nameSegment, seg.kind == globSegment,
+		seg.kind == filterSegment, seg.kind == filterAllSegment,
+		seg.kind == ordinalSegment, seg.kind == indexListSegment:
+		s = seg.text
+		goto end
+	case !needsQuoting(seg.text):
+		s = seg.text
+		goto end
+	}
+
+	sb.WriteByte(selectorQuote)
+	for i := 0; i < len(seg.text); i++ {
+		c := seg.text[i]
+		if c == selectorQuote || c == selectorEscape {
+			sb.WriteByte(selectorEscape)
+		}
+		sb.WriteByte(c)
+	}
+	sb.WriteByte(selectorQuote)
+	s = sb.String()
+
+end:
+	return s
+}
+
+// needsQuoting reports whether key must be quoted to be parsed as a single
+// literal object key.
+func needsQuoting(key string) (needs bool) {
+	var parseErr error
+	var isOrdinal bool
+
+	if key == "" || key == string(RootSelector) || strings.ContainsAny(key, `."\*?[`) ||
+		key[0] == regexDelimiter || strings.HasPrefix(key, filterPrefix) {
+		needs = true
+		goto end
+	}
+
+	// Numeric names would otherwise address array elements, and names such
+	// as "#1" object members by position
+	_, parseErr = strconv.Atoi(key)
+	_, isOrdinal = parseOrdinal(key)
+	needs = parseErr == nil || isOrdinal
+
+end:
+	return needs
+}
+
+// segmentTexts returns the text of each segment, as used in error context.
+func segmentTexts(segments []segment) []string {
+	texts := make([]string, len(segments))
+	for i, seg := range segments {
+		texts[i] = seg.text
+	}
+	return texts
+}
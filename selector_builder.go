@@ -0,0 +1,69 @@
+package jsonxtractr
+
+import (
+	"strconv"
+)
+
+// SelectorBuilder builds a Selector one segment at a time, quoting keys as
+// needed so that each is always taken literally, e.g.
+//
+//	NewSelectorBuilder().Key("user").Key("a.b").Index(0).Build()
+//
+// builds `user."a.b".0`. The zero value is an empty builder, which builds
+// RootSelector.
+type SelectorBuilder struct {
+	segments []segment
+	err      error
+}
+
+// NewSelectorBuilder returns an empty builder.
+func NewSelectorBuilder() *SelectorBuilder {
+	return &SelectorBuilder{}
+}
+
+// Key appends the object key named key.
+func (b *SelectorBuilder) Key(key string) *SelectorBuilder {
+	b.segments = append(b.segments, segment{kind: keySegment, text: key})
+	return b
+}
+
+// Index appends the array index i, where negative indexes count back from the
+// end of the array.
+func (b *SelectorBuilder) Index(i int) *SelectorBuilder {
+	b.segments = append(b.segments, segment{kind: nameSegment, text: strconv.Itoa(i)})
+	return b
+}
+
+// Path appends the segments of selector, which may itself contain wildcards
+// or other patterns. An invalid selector is recorded and makes MustBuild
+// panic. RootSelector appends nothing.
+func (b *SelectorBuilder) Path(selector Selector) *SelectorBuilder {
+	segments, err := selector.parseMatching()
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		// Keep the selector as written so the original error still surfaces
+		b.segments = append(b.segments, segment{kind: nameSegment, text: string(selector)})
+		return b
+	}
+	b.segments = append(b.segments, segments...)
+	return b
+}
+
+// Build returns the selector built so far. The builder may be extended
+// further afterwards. If an invalid selector was passed to Path, the result
+// is invalid too and fails when used.
+func (b *SelectorBuilder) Build() Selector {
+	return formatSelector(b.segments)
+}
+
+// MustBuild is like Build but panics if an invalid selector was passed to
+// Path. The panic value is the error itself, as with
+// MustExtractValueFromBytes.
+func (b *SelectorBuilder) MustBuild() Selector {
+	if b.err != nil {
+		panic(b.err)
+	}
+	return b.Build()
+}
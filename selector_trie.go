@@ -0,0 +1,668 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// selectorTrie compiles a set of selectors into a prefix tree keyed on path
+// segments so that a single decoder walk can resolve every selector at once.
+type selectorTrie struct {
+	root      *trieNode
+	selectors []Selector
+	paths     [][]segment // parsed segments of each selector
+	rawBytes  []byte
+	consumed  *bytes.Buffer // input read so far when streaming without rawBytes
+	values    []any
+	found     []bool
+	errs      []error
+	opts      options
+	tokens    int64 // tokens stepped over by the single pass
+	alone     []int // indexes of selectors with a filter or ordinal, resolved on their own
+}
+
+// trieNode is one path segment shared by every selector passing through it.
+type trieNode struct {
+	segment  segment
+	position int
+	children []*trieNode
+	terminal []int // indexes of selectors that end at this node
+	subtree  []int // indexes of all selectors that pass through this node
+}
+
+func newSelectorTrie(selectors []Selector, rawBytes []byte, opts options) *selectorTrie {
+	t := &selectorTrie{
+		root:      &trieNode{position: -1},
+		selectors: selectors,
+		paths:     make([][]segment, len(selectors)),
+		rawBytes:  rawBytes,
+		values:    make([]any, len(selectors)),
+		found:     make([]bool, len(selectors)),
+		errs:      make([]error, len(selectors)),
+		opts:      opts,
+	}
+	for i, selector := range selectors {
+		if len(selector) == 0 {
+			t.errs[i] = NewErr(
+				ErrJSONPathTraversalFailed,
+				ErrJSONValueSelectorCannotBeEmpty,
+			)
+			continue
+		}
+		segments, err := opts.parseSelector(selector)
+		if err != nil {
+			t.errs[i] = err
+			continue
+		}
+		state := newExtractState(nil, string(selector), segments, rawBytes)
+		state.opts = opts
+		err = state.rejectWildcards()
+		if err != nil {
+			t.errs[i] = err
+			continue
+		}
+		t.paths[i] = segments
+		if slices.ContainsFunc(segments, segment.resolvesAlone) {
+			t.alone = append(t.alone, i)
+			continue
+		}
+		t.insert(i, segments)
+	}
+	return t
+}
+
+// resolveSelectors resolves every selector against rawBytes in a single pass.
+// With WithConcurrency the selectors are instead split into contiguous shares,
+// each resolved in a pass of its own on a separate goroutine. Results are
+// indexed like selectors either way.
+func resolveSelectors(ctx context.Context, rawBytes []byte, selectors []Selector, opts options) (values []any, found []bool, errs []error) {
+	var wg sync.WaitGroup
+
+	workers := min(opts.concurrency, len(selectors))
+	if workers < 2 {
+		trie := newSelectorTrie(selectors, rawBytes, opts)
+		trie.extract(newContextReader(ctx, rawBytes))
+		values, found, errs = trie.values, trie.found, trie.errs
+		goto end
+	}
+
+	values = make([]any, len(selectors))
+	found = make([]bool, len(selectors))
+	errs = make([]error, len(selectors))
+	for w := range workers {
+		lo := w * len(selectors) / workers
+		hi := (w + 1) * len(selectors) / workers
+		wg.Go(func() {
+			// Each share writes only its own range of the results
+			trie := newSelectorTrie(selectors[lo:hi], rawBytes, opts)
+			trie.extract(newContextReader(ctx, rawBytes))
+			copy(values[lo:hi], trie.values)
+			copy(found[lo:hi], trie.found)
+			copy(errs[lo:hi], trie.errs)
+		})
+	}
+	wg.Wait()
+
+end:
+	return values, found, errs
+}
+
+// insert adds the selector at index idx to the trie, reusing existing nodes
+// for any shared prefix.
+func (t *selectorTrie) insert(idx int, segments []segment) {
+	node := t.root
+	node.subtree = append(node.subtree, idx)
+	for pos, seg := range segments {
+		var child *trieNode
+		for _, c := range node.children {
+			if c.segment.kind == seg.kind && c.segment.text == seg.text {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			child = &trieNode{segment: seg, position: pos}
+			node.children = append(node.children, child)
+		}
+		child.subtree = append(child.subtree, idx)
+		node = child
+	}
+	node.terminal = append(node.terminal, idx)
+}
+
+// streams reports whether the trie can be walked straight from a stream, which
+// needs every selector to be resolved by the shared walk rather than navigated
+// on its own over the whole input.
+func (t *selectorTrie) streams() bool {
+	return len(t.alone) == 0 &&
+		!t.opts.scansWholeObjects() &&
+		t.opts.maxDepth <= 0 &&
+		t.opts.decoderFactory == nil &&
+		t.opts.concurrency < 2
+}
+
+// raw returns the JSON the trie is walking, or as much of it as has been read
+// when streaming.
+func (t *selectorTrie) raw() []byte {
+	if t.rawBytes == nil && t.consumed != nil {
+		return t.consumed.Bytes()
+	}
+	return t.rawBytes
+}
+
+// extract walks the document read from reader once, recording a value or an
+// error for every selector in the trie. The reader must yield t.rawBytes, or
+// feed t.consumed when streaming. The walk stops reading as soon as every
+// selector is resolved.
+func (t *selectorTrie) extract(reader io.Reader) {
+	t.resolveIndividually(t.alone)
+	if len(t.root.children) == 0 {
+		goto end
+	}
+	if t.opts.scansWholeObjects() {
+		// Ruling out ambiguous or duplicate keys means reading every object
+		// on a path in full, which the shared walk avoids, so navigate each
+		// selector on its own
+		t.resolveIndividually(t.root.subtree)
+		goto end
+	}
+	if t.opts.maxDepth > 0 {
+		// Depth is tracked while navigating a single selector
+		t.resolveIndividually(t.root.subtree)
+		goto end
+	}
+	if t.opts.decoderFactory != nil {
+		// The shared walk reads with a jsontext.Decoder of its own
+		t.resolveIndividually(t.root.subtree)
+		goto end
+	}
+	_ = t.walkValue(t.opts.newDecoder(reader), t.root, false)
+end:
+	return
+}
+
+// walkValue resolves node against the value the decoder is positioned at. When
+// consume is true the decoder is left positioned after the value so that the
+// caller can continue reading its enclosing container.
+func (t *selectorTrie) walkValue(decoder *jsontext.Decoder, node *trieNode, consume bool) (err error) {
+	var value jsontext.Value
+	var reader *bytes.Reader
+
+	if len(node.terminal) == 0 {
+		err = t.walkContainer(decoder, node, consume)
+		goto end
+	}
+
+	if len(node.children) == 0 {
+		var v any
+		err = jsonv2.UnmarshalDecode(decoder, &v, t.opts.unmarshalOptions())
+		if err != nil {
+			t.failValue(node.terminal,
+				ErrJSONStreamingParseFailed,
+				ErrJSONUnmarshalFailed,
+				err,
+			)
+			goto end
+		}
+		t.resolve(node.terminal, v)
+		goto end
+	}
+
+	// Selectors end here and others continue below, so capture the value
+	// once and walk its children with a decoder of their own.
+	value, err = decoder.ReadValue()
+	if err != nil {
+		t.failValue(node.terminal,
+			ErrJSONStreamingParseFailed,
+			ErrJSONUnmarshalFailed,
+			err,
+		)
+		// The value is malformed, but selectors below it may still resolve
+		// against its well-formed prefix, so navigate those individually
+		t.resolveIndividually(node.subtree)
+		goto end
+	}
+	for _, idx := range node.terminal {
+		var v any
+		unmarshalErr := jsonv2.Unmarshal(value, &v, t.opts.unmarshalOptions())
+		if unmarshalErr != nil {
+			t.failValue([]int{idx},
+				ErrJSONStreamingParseFailed,
+				ErrJSONUnmarshalFailed,
+				unmarshalErr,
+			)
+			continue
+		}
+		t.resolve([]int{idx}, v)
+	}
+	reader = getReader(value)
+	_ = t.walkContainer(t.opts.newDecoder(reader), node, false)
+	putReader(reader)
+
+end:
+	return err
+}
+
+// walkContainer dispatches node's children against the object or array the
+// decoder is positioned at.
+func (t *selectorTrie) walkContainer(decoder *jsontext.Decoder, node *trieNode, consume bool) (err error) {
+	var keyChildren, indexChildren []*trieNode
+	var wantObject, wantArray []*trieNode
+	var indexes []int
+	var unread []byte
+	var read bool
+
+	kind := decoder.PeekKind()
+
+	for _, child := range node.children {
+		if child.segment.isEmpty() {
+			t.fail(child.subtree, child.position,
+				ErrJSONPathTraversalFailed,
+				ErrJSONPathContainsEmptySegment,
+			)
+			continue
+		}
+		idx, parseErr := strconv.Atoi(child.segment.text)
+		switch {
+		case parseErr != nil || child.segment.kind == keySegment || kind == '{':
+			if kind != '{' {
+				wantObject = append(wantObject, child)
+				continue
+			}
+			keyChildren = append(keyChildren, child)
+		case kind != '[':
+			wantArray = append(wantArray, child)
+		default:
+			indexChildren = append(indexChildren, child)
+			indexes = append(indexes, idx)
+		}
+	}
+
+	if len(wantObject) > 0 || len(wantArray) > 0 {
+		// A value no child can descend into is read whole, so that all
+		// of it is at hand to preview
+		unread = decoder.UnreadBuffer()
+		if len(keyChildren) == 0 && len(indexChildren) == 0 {
+			var readErr error
+			unread, readErr = decoder.ReadValue()
+			if consume {
+				err = readErr
+			}
+			read = true
+		}
+		preview := previewValue(unread, node.segment.text, t.opts)
+		for _, child := range wantObject {
+			parts := []any{
+				ErrJSONPathTraversalFailed,
+				ErrJSONPathExpectedObjectAtSegment,
+				"expected_type", "object",
+				"actual_type", kindOf(kind).String(),
+				"actual_value_preview", preview,
+			}
+			if kind == '[' && child.segment.kind == nameSegment {
+				parts = append(parts, "reason", indexExpected(child.segment.text))
+			}
+			t.fail(child.subtree, child.position, parts...)
+		}
+		for _, child := range wantArray {
+			t.fail(child.subtree, child.position,
+				ErrJSONPathTraversalFailed,
+				ErrJSONPathExpectedArrayAtSegment,
+				"expected_type", "array",
+				"actual_type", kindOf(kind).String(),
+				"actual_value_preview", preview,
+			)
+		}
+	}
+
+	switch {
+	case len(keyChildren) > 0:
+		err = t.walkObject(decoder, keyChildren, consume)
+	case len(indexChildren) > 0:
+		err = t.walkArray(decoder, indexChildren, indexes, consume)
+	case consume && !read:
+		err = decoder.SkipValue()
+	}
+	return err
+}
+
+// countToken records that the walk stepped over another object member or array
+// element, failing with ErrJSONTooManyTokens once past the limit.
+func (t *selectorTrie) countToken() (err error) {
+	t.tokens++
+	if t.tokens > t.opts.tokenLimit() {
+		err = NewErr(
+			ErrJSONTooManyTokens,
+			"max_tokens", t.opts.tokenLimit(),
+		)
+	}
+	return err
+}
+
+// walkObject scans an object's members once, descending into every child
+// whose key appears and reporting the keys that never do.
+func (t *selectorTrie) walkObject(decoder *jsontext.Decoder, children []*trieNode, consume bool) (err error) {
+	var keyToken jsontext.Token
+	var availableKeys []string
+
+	pending := make(map[string][]*trieNode, len(children))
+	for _, child := range children {
+		key := t.opts.foldKey(child.segment.text)
+		pending[key] = append(pending[key], child)
+	}
+
+	// Read object start token '{'
+	_, err = decoder.ReadToken()
+	if err != nil {
+		t.failPending(pending,
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "object_start",
+			err,
+		)
+		goto end
+	}
+
+	// Collect available keys for error context
+	availableKeys = make([]string, 0)
+
+	for decoder.PeekKind() != '}' {
+		err = t.countToken()
+		if err != nil {
+			t.failPending(pending,
+				ErrJSONPathTraversalFailed,
+				ErrJSONTooManyTokens,
+				"max_tokens", t.opts.tokenLimit(),
+			)
+			goto end
+		}
+		keyToken, err = decoder.ReadToken()
+		if err != nil {
+			t.failPending(pending,
+				ErrJSONPathTraversalFailed,
+				ErrJSONTokenReadFailed,
+				"reading", "object_key",
+				err,
+			)
+			goto end
+		}
+
+		key := keyToken.String()
+		availableKeys = append(availableKeys, key)
+
+		folded := t.opts.foldKey(key)
+		matched, ok := pending[folded]
+		switch {
+		case !ok:
+			err = decoder.SkipValue()
+		case len(matched) == 1:
+			delete(pending, folded)
+			err = t.walkValue(decoder, matched[0], consume || len(pending) > 0)
+		default:
+			// Several segments name the same key, e.g. quoted and unquoted
+			err = t.walkShared(decoder, matched, nil)
+			if err == nil {
+				delete(pending, folded)
+			}
+		}
+		if err != nil {
+			t.failPending(pending, skipParts(!ok, "skipping_key", key, err)...)
+			goto end
+		}
+
+		if len(pending) == 0 && !consume {
+			goto end
+		}
+	}
+
+	// Keys not found
+	for _, child := range children {
+		if _, ok := pending[t.opts.foldKey(child.segment.text)]; !ok {
+			continue
+		}
+		t.fail(child.subtree, child.position,
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathSegmentNotFound,
+			"missing_key", child.segment.text,
+			"available_keys", availableKeys,
+		)
+	}
+
+	if consume {
+		// Read object end token '}'
+		_, err = decoder.ReadToken()
+	}
+end:
+	return err
+}
+
+// walkArray scans an array's elements once, descending into every child
+// whose index is present and reporting the indexes that are out of range.
+func (t *selectorTrie) walkArray(decoder *jsontext.Decoder, children []*trieNode, indexes []int, consume bool) (err error) {
+	var currentIdx int
+	var trailing *trailingValues
+
+	pending := make(map[int][]*trieNode, len(children))
+	for i, child := range children {
+		pending[indexes[i]] = append(pending[indexes[i]], child)
+		if indexes[i] >= 0 {
+			continue
+		}
+		// Negative indexes count back from the end of the array, so the
+		// trailing elements are buffered until the end is reached
+		if trailing == nil || trailingCount(indexes[i]) > trailing.size {
+			trailing = newTrailingValues(trailingCount(indexes[i]))
+		}
+	}
+
+	// Read array start token '['
+	_, err = decoder.ReadToken()
+	if err != nil {
+		t.failPendingIndexes(pending,
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "array_start",
+			err,
+		)
+		goto end
+	}
+
+	currentIdx = 0
+	for len(pending) > 0 || consume {
+		if decoder.PeekKind() == ']' {
+			break
+		}
+		err = t.countToken()
+		if err != nil {
+			t.failPendingIndexes(pending,
+				ErrJSONPathTraversalFailed,
+				ErrJSONTooManyTokens,
+				"max_tokens", t.opts.tokenLimit(),
+			)
+			goto end
+		}
+		matched, ok := pending[currentIdx]
+		switch {
+		case trailing != nil:
+			err = t.walkShared(decoder, matched, trailing)
+			if err == nil {
+				delete(pending, currentIdx)
+			}
+		case !ok:
+			err = decoder.SkipValue()
+		case len(matched) == 1:
+			delete(pending, currentIdx)
+			err = t.walkValue(decoder, matched[0], consume || len(pending) > 0)
+		default:
+			// Several segments (e.g. "1" and "+1") name the same index
+			err = t.walkShared(decoder, matched, nil)
+			if err == nil {
+				delete(pending, currentIdx)
+			}
+		}
+		if err != nil {
+			t.failPendingIndexes(pending, skipParts(!ok, "skip_index", currentIdx, err)...)
+			goto end
+		}
+		currentIdx++
+	}
+
+	// Resolve negative indexes now that the array length is known
+	for i, child := range children {
+		if indexes[i] >= 0 {
+			continue
+		}
+		value, _, fromEndErr := trailing.fromEnd(indexes[i])
+		if fromEndErr != nil {
+			continue
+		}
+		delete(pending, indexes[i])
+		reader := getReader(value)
+		_ = t.walkValue(t.opts.newDecoder(reader), child, false)
+		putReader(reader)
+	}
+
+	// Indexes beyond either end of the array
+	for i, child := range children {
+		if _, ok := pending[indexes[i]]; !ok {
+			continue
+		}
+		t.fail(child.subtree, child.position,
+			ErrJSONPathTraversalFailed,
+			ErrJSONIndexOutOfRange,
+			"target_index", indexes[i],
+			"array_length", currentIdx,
+		)
+	}
+
+	if consume {
+		// Read array end token ']'
+		_, err = decoder.ReadToken()
+	}
+end:
+	return err
+}
+
+// walkShared resolves several sibling nodes that address the same value by
+// capturing it once and walking each node over its own decoder. The value is
+// also retained in trailing when it is non-nil.
+func (t *selectorTrie) walkShared(decoder *jsontext.Decoder, nodes []*trieNode, trailing *trailingValues) (err error) {
+	var value jsontext.Value
+
+	value, err = decoder.ReadValue()
+	if err != nil {
+		for _, node := range nodes {
+			t.resolveIndividually(node.subtree)
+		}
+		goto end
+	}
+	if trailing != nil {
+		trailing.add(value, decoder.InputOffset()-int64(len(value)))
+	}
+	for _, node := range nodes {
+		reader := getReader(value)
+		_ = t.walkValue(t.opts.newDecoder(reader), node, false)
+		putReader(reader)
+	}
+end:
+	return err
+}
+
+// failValue records an enriched error for every unresolved selector index in
+// idxs whose path was fully navigated but whose value could not be decoded.
+func (t *selectorTrie) failValue(idxs []int, parts ...any) {
+	for _, idx := range idxs {
+		if t.found[idx] || t.errs[idx] != nil {
+			continue
+		}
+		state := t.newState(idx)
+		state.position = len(state.segments) - 1
+		state.pathProgress = segmentTexts(state.segments)
+		t.errs[idx] = state.enrichError(parts...)
+	}
+}
+
+// resolveIndividually navigates each unresolved selector index in idxs
+// on its own, as if it were the only selector requested. When streaming only
+// the input read so far is navigated.
+func (t *selectorTrie) resolveIndividually(idxs []int) {
+	for _, idx := range idxs {
+		if t.found[idx] || t.errs[idx] != nil {
+			continue
+		}
+		raw := t.raw()
+		reader := getReader(raw)
+		value, err := extractSingleValue(reader, t.selectors[idx], raw, t.opts)
+		putReader(reader)
+		if err != nil {
+			t.errs[idx] = err
+			continue
+		}
+		t.resolve([]int{idx}, value)
+	}
+}
+
+// resolve records value for every selector index in idxs.
+func (t *selectorTrie) resolve(idxs []int, value any) {
+	for _, idx := range idxs {
+		t.values[idx] = value
+		t.found[idx] = true
+	}
+}
+
+// fail records an enriched error for every unresolved selector index in idxs,
+// using the selector's own path context at the given segment position.
+func (t *selectorTrie) fail(idxs []int, position int, parts ...any) {
+	for _, idx := range idxs {
+		if t.found[idx] || t.errs[idx] != nil {
+			continue
+		}
+		state := t.newState(idx)
+		state.position = position
+		state.pathProgress = segmentTexts(state.segments[:min(position, len(state.segments))])
+		t.errs[idx] = state.enrichError(parts...)
+	}
+}
+
+// newState returns a state for reporting on the selector at index idx.
+func (t *selectorTrie) newState(idx int) *extractState {
+	state := newExtractState(nil, string(t.selectors[idx]), t.paths[idx], t.rawBytes)
+	state.opts = t.opts
+	state.consumed = t.consumed
+	return state
+}
+
+// skipParts returns the parts of the error reporting cause, a failure while
+// walking the member or element that name and id identify, wrapping
+// ErrJSONSkipFailed when skipped because no selector descends into it.
+func skipParts(skipped bool, name string, id any, cause error) []any {
+	parts := []any{ErrJSONPathTraversalFailed, ErrJSONTokenReadFailed}
+	if skipped {
+		parts = append(parts, ErrJSONSkipFailed)
+	}
+	return append(parts, name, id, cause)
+}
+
+// failPending records an error for every selector below the pending object children.
+func (t *selectorTrie) failPending(pending map[string][]*trieNode, parts ...any) {
+	for _, children := range pending {
+		for _, child := range children {
+			t.fail(child.subtree, child.position, parts...)
+		}
+	}
+}
+
+// failPendingIndexes records an error for every selector below the pending array children.
+func (t *selectorTrie) failPendingIndexes(pending map[int][]*trieNode, parts ...any) {
+	for _, children := range pending {
+		for _, child := range children {
+			t.fail(child.subtree, child.position, parts...)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// maxNullPadding is the most null elements WithCreateMissing pads an array
+// with, so an index far past the end fails rather than building a huge array.
+const maxNullPadding = 1 << 10
+
+// SetValue returns a copy of JSON bytes with the value selected by selector
+// replaced by the JSON encoding of newValue. The rest of the document is
+// copied verbatim, so its key order, formatting and escapes are preserved.
+// The selected value must exist; use SetValueOpts with WithCreateMissing to
+// add missing object members and array elements along the way.
+func SetValue(jsonBytes []byte, selector Selector, newValue any) ([]byte, error) {
+	return SetValueOpts(jsonBytes, selector, newValue)
+}
+
+// SetValueOpts is SetValue with options.
+func SetValueOpts(jsonBytes []byte, selector Selector, newValue any, opts ...Option) (updated []byte, err error) {
+	var state *extractState
+	var encoded []byte
+
+	state, encoded, err = newEditState(jsonBytes, selector, newValue, newOptions(opts))
+	if err != nil {
+		goto end
+	}
+
+	updated, err = state.setValue(encoded)
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToSetValueInJSON,
+			ErrSettingInJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return updated, err
+}
+
+// AppendValue returns a copy of JSON bytes with the JSON encoding of value
+// added as the last element of the array selected by selector. The rest of
+// the document is copied verbatim, and the new element is separated from the
+// one before it by the same whitespace as that element's own, so it lands on
+// its own line in an array laid out one element per line. A selector that
+// reaches a value other than an array fails with
+// ErrJSONPathExpectedArrayAtSegment.
+func AppendValue(jsonBytes []byte, selector Selector, value any) (updated []byte, err error) {
+	var state *extractState
+	var encoded []byte
+
+	state, encoded, err = newEditState(jsonBytes, selector, value, options{})
+	if err != nil {
+		goto end
+	}
+
+	updated, err = state.appendValue(encoded)
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToSetValueInJSON,
+			ErrSettingInJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return updated, err
+}
+
+// newEditState returns the JSON encoding of value along with a state ready to
+// navigate selector over jsonBytes, the document being edited.
+func newEditState(jsonBytes []byte, selector Selector, value any, opts options) (state *extractState, encoded []byte, err error) {
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	encoded, err = jsonv2.Marshal(value)
+	if err != nil {
+		err = NewErr(
+			ErrJSONMarshalFailed,
+			"value_type", fmt.Sprintf("%T", value),
+			err,
+		)
+		goto end
+	}
+
+	state, err = newSelectorState(bytes.NewReader(jsonBytes), selector, jsonBytes, opts)
+	if err == nil {
+		err = state.rejectUnsettable()
+	}
+
+end:
+	return state, encoded, err
+}
+
+// setValue navigates to the selected value and returns rawBytes with it
+// replaced by encoded, or with the missing part of the path created to hold
+// it when the options allow.
+func (s *extractState) setValue(encoded []byte) (updated []byte, err error) {
+	var containerStart, end int64
+	var value jsontext.Value
+
+	for i, seg := range s.segments {
+		s.position = i
+
+		// The container the segment steps into starts where the decoder is
+		containerStart = s.inputOffset()
+		err = s.navigateToSegment(seg)
+		if err == nil {
+			err = s.checkDepth()
+		}
+		if err != nil && s.opts.createMissing {
+			updated, err = s.createMissing(containerStart, encoded, err)
+		}
+		if err != nil {
+			goto end
+		}
+		if updated != nil {
+			goto end
+		}
+		s.pathProgress = append(s.pathProgress, seg.text)
+	}
+
+	value, err = s.decoder.ReadValue()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+		goto end
+	}
+	end = s.inputOffset()
+	updated = splice(s.rawBytes, end-int64(len(value)), end, encoded)
+
+end:
+	return updated, err
+}
+
+// rejectUnsettable reports the first segment that can't address a single
+// stored value: an empty segment, or an index list, whose value is an array
+// assembled from several elements.
+func (s *extractState) rejectUnsettable() (err error) {
+	for i, seg := range s.segments {
+		s.position = i
+		switch {
+		case seg.isEmpty():
+			err = s.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONPathContainsEmptySegment,
+			)
+		case seg.kind == indexListSegment:
+			err = s.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONSelectorMultiMatch,
+			)
+		}
+		if err != nil {
+			goto end
+		}
+	}
+	s.position = 0
+
+end:
+	return err
+}
+
+// appendValue navigates to the selected array and returns rawBytes with
+// encoded added as its last element.
+func (s *extractState) appendValue(encoded []byte) (updated []byte, err error) {
+	var kind jsontext.Kind
+	var array jsontext.Value
+	var start, insertAt int64
+	var element []byte
+
+	err = s.navigate()
+	if err != nil {
+		goto end
+	}
+
+	kind = jsontext.Kind(s.decoder.PeekKind())
+	if kind != '[' {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONPathExpectedArrayAtSegment,
+			"expected_type", "array",
+			"actual_type", kindOf(kind).String(),
+			"actual_value_preview", s.valuePreview(s.unreadInput()),
+		)
+		goto end
+	}
+
+	array, err = s.decoder.ReadValue()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+		goto end
+	}
+	start = s.inputOffset() - int64(len(array))
+
+	insertAt, element = appendedElement(array, encoded)
+	updated = splice(s.rawBytes, start+insertAt, start+insertAt, element)
+
+end:
+	return updated, err
+}
+
+// appendedElement returns the offset within array, just past its last
+// element, at which to insert encoded, and encoded preceded by the comma and
+// whitespace that separate it from that element. The whitespace is that
+// before the last element, so the new one is laid out as the last one is.
+func appendedElement(array, encoded []byte) (insertAt int64, element []byte) {
+	var lastStart, afterSeparator int
+
+	closing := len(array) - 1
+	for pos := 1; pos < closing; {
+		afterSeparator = pos
+		lastStart = skipJSONSpace(array, pos)
+		if lastStart >= closing {
+			break
+		}
+		pos = skipJSONValue(array, lastStart)
+		insertAt = int64(pos)
+		pos = skipJSONSpace(array, pos)
+		if pos < closing && array[pos] == ',' {
+			pos++
+		}
+	}
+
+	if insertAt == 0 {
+		// An empty array, which may still hold whitespace
+		return 1, encoded
+	}
+	element = append(element, ',')
+	element = append(element, array[afterSeparator:lastStart]...)
+	return insertAt, append(element, encoded...)
+}
+
+// createMissing is called when the segment at s.position failed with cause
+// to step into the container starting at or after containerStart. When cause
+// is a missing key or an index past the end of the array, it returns
+// rawBytes with the member or element added to the container, holding
+// encoded nested within whatever the remaining segments select. Otherwise,
+// or when a remaining segment can't be created, it returns cause.
+func (s *extractState) createMissing(containerStart int64, encoded []byte, cause error) (updated []byte, err error) {
+	var value, member []byte
+	var start, closing, insertAt, length int
+	var ok bool
+
+	seg := s.segments[s.position]
+	idx, isIndex := seg.arrayIndex()
+
+	// A numeric segment may have been taken as an object key
+	isIndex = isIndex && !s.numericKey
+
+	err = cause
+	switch {
+	case isIndex && idx >= 0 && errors.Is(cause, ErrJSONIndexOutOfRange):
+	case !isIndex && (seg.kind == nameSegment || seg.kind == keySegment) &&
+		errors.Is(cause, ErrJSONPathSegmentNotFound):
+	default:
+		goto end
+	}
+
+	for _, next := range s.segments[s.position+1:] {
+		nextIdx, nextIsIndex := next.arrayIndex()
+		if nextIsIndex && nextIdx > maxNullPadding {
+			err = s.paddingTooLong(nextIdx, 0)
+			goto end
+		}
+	}
+
+	value, ok = nestedValue(s.segments[s.position+1:], encoded)
+	if !ok {
+		goto end
+	}
+
+	// Skip to the container past the ':' or ',' that may precede it
+	start = skipJSONSpace(s.rawBytes, int(containerStart))
+	if start < len(s.rawBytes) && (s.rawBytes[start] == ':' || s.rawBytes[start] == ',') {
+		start = skipJSONSpace(s.rawBytes, start+1)
+	}
+	closing = skipJSONValue(s.rawBytes, start) - 1
+	if closing <= start {
+		goto end
+	}
+
+	if isIndex {
+		length, ok = arrayLength(s.rawBytes[start:closing+1], s.opts)
+		if !ok || idx < length {
+			goto end
+		}
+		if idx-length > maxNullPadding {
+			err = s.paddingTooLong(idx, length)
+			goto end
+		}
+		member = nullElements(idx - length)
+		member = append(member, value...)
+	} else {
+		member, _ = jsontext.AppendQuote(member, seg.text)
+		member = append(member, ':')
+		member = append(member, value...)
+	}
+
+	// Insert after the last member, so the container's closing whitespace
+	// is kept as it is
+	insertAt = closing
+	for insertAt > start && isJSONSpace(s.rawBytes[insertAt-1]) {
+		insertAt--
+	}
+	if insertAt-1 > start {
+		member = append([]byte{','}, member...)
+	}
+	updated = splice(s.rawBytes, int64(insertAt), int64(insertAt), member)
+	err = nil
+
+end:
+	return updated, err
+}
+
+// paddingTooLong returns the error for creating element idx of an array of
+// length elements, which would take more than maxNullPadding nulls.
+func (s *extractState) paddingTooLong(idx, length int) error {
+	return s.enrichError(
+		ErrJSONPathTraversalFailed,
+		ErrJSONIndexOutOfRange,
+		"target_index", idx,
+		"array_length", length,
+		"max_padding", maxNullPadding,
+	)
+}
+
+// arrayIndex returns the array index a name segment holds, if it holds one.
+func (seg segment) arrayIndex() (idx int, ok bool) {
+	var err error
+	if seg.kind != nameSegment {
+		goto end
+	}
+	idx, err = strconv.Atoi(seg.text)
+	ok = err == nil
+end:
+	return idx, ok
+}
+
+// nestedValue returns encoded nested within the objects and arrays that
+// segments step into, such that the segments select it. It reports false if
+// a segment is neither a key nor a non-negative array index.
+func nestedValue(segments []segment, encoded []byte) (value []byte, ok bool) {
+	value = encoded
+	for i := len(segments) - 1; i >= 0; i-- {
+		var nested []byte
+		seg := segments[i]
+		idx, isIndex := seg.arrayIndex()
+		switch {
+		case isIndex && idx >= 0:
+			nested = append(nested, '[')
+			nested = append(nested, nullElements(idx)...)
+			nested = append(nested, value...)
+			nested = append(nested, ']')
+		case isIndex:
+			goto end
+		case seg.kind == nameSegment, seg.kind == keySegment:
+			nested = append(nested, '{')
+			nested, _ = jsontext.AppendQuote(nested, seg.text)
+			nested = append(nested, ':')
+			nested = append(nested, value...)
+			nested = append(nested, '}')
+		default:
+			goto end
+		}
+		value = nested
+	}
+	ok = true
+
+end:
+	return value, ok
+}
+
+// nullElements returns n null array elements, each followed by a comma.
+func nullElements(n int) []byte {
+	return bytes.Repeat([]byte("null,"), n)
+}
+
+// arrayLength returns the number of elements in the array array.
+func arrayLength(array []byte, opts options) (length int, ok bool) {
+	var err error
+
+	decoder := opts.newDecoder(bytes.NewReader(array))
+	_, err = decoder.ReadToken()
+	if err != nil {
+		goto end
+	}
+	for decoder.PeekKind() != ']' {
+		err = decoder.SkipValue()
+		if err != nil {
+			goto end
+		}
+		length++
+	}
+	ok = true
+
+end:
+	return length, ok
+}
+
+// splice returns a copy of raw with raw[start:end] replaced by insert.
+func splice(raw []byte, start, end int64, insert []byte) []byte {
+	spliced := make([]byte, 0, int64(len(raw))-(end-start)+int64(len(insert)))
+	spliced = append(spliced, raw[:start]...)
+	spliced = append(spliced, insert...)
+	return append(spliced, raw[end:]...)
+}
@@ -0,0 +1,127 @@
+package jsonxtractr
+
+// ExtractSubtree is ExtractValuesFromBytes returning the values found nested
+// the way their selectors are, rather than in a flat map, so that "user.name"
+// and "user.age" give {"user": {"name": ..., "age": ...}}. Each segment becomes
+// a key, including array indexes, so "items.0" gives {"items": {"0": ...}}.
+// Selectors not found are left out of the result and returned along with the
+// error explaining them, as for ExtractValuesFromBytes.
+//
+// A selected value can't also hold another selected path, since the two
+// would disagree about what is there, so passing both "user" and "user.name"
+// fails with ErrJSONSelectorConflict before anything is extracted. The same
+// goes for RootSelector passed with any other selector, while on its own it
+// gives the whole document, which must then be an object. Segments that only
+// resolve against the document, such as filters, ordinals and index lists,
+// name no key to nest under and fail with ErrJSONSelectorInvalid.
+func ExtractSubtree(jsonBytes []byte, selectors []Selector) (subtree map[string]any, notFound []Selector, err error) {
+	var paths [][]segment
+	var valuesMap ValuesMap
+
+	selectors = Selectors(selectors).Unique()
+	paths, err = subtreePaths(selectors)
+	if err != nil {
+		goto end
+	}
+
+	valuesMap, notFound, err = ExtractValuesFromBytes(jsonBytes, selectors)
+	if valuesMap == nil {
+		goto end
+	}
+
+	subtree = make(map[string]any, len(valuesMap))
+	for i, selector := range selectors {
+		value, ok := valuesMap[selector]
+		if !ok {
+			continue
+		}
+		if len(paths[i]) > 0 {
+			insertSubtree(subtree, paths[i], value)
+			continue
+		}
+		// The root selector is the only one, so its object is the result
+		root, isObject := value.(map[string]any)
+		if !isObject {
+			subtree = nil
+			err = NewErr(
+				ErrJSONSelectorConflict,
+				"selector", selector,
+				"reason", "root value is not an object",
+			)
+			goto end
+		}
+		subtree = root
+	}
+
+end:
+	return subtree, notFound, err
+}
+
+// subtreePaths parses selectors, failing when one selector's value would hold
+// another's.
+func subtreePaths(selectors []Selector) (paths [][]segment, err error) {
+	var segments []segment
+
+	// Which selector each selected path, and each path leading to one, is for
+	selected := make(map[Selector]Selector, len(selectors))
+	ancestors := make(map[Selector]Selector)
+
+	paths = make([][]segment, len(selectors))
+	for i, selector := range selectors {
+		segments, err = selector.parse()
+		if err != nil {
+			goto end
+		}
+		for _, seg := range segments {
+			if !seg.resolvesAlone() {
+				continue
+			}
+			err = NewErr(
+				ErrJSONSelectorInvalid,
+				"selector", selector,
+				"segment", seg.text,
+				"reason", "segment names no key to nest its value under",
+			)
+			goto end
+		}
+		paths[i] = segments
+	}
+
+	for i, selector := range selectors {
+		path := formatSelector(paths[i])
+		other, ok := ancestors[path]
+		for depth := 0; !ok && depth < len(paths[i]); depth++ {
+			other, ok = selected[formatSelector(paths[i][:depth])]
+		}
+		if ok {
+			err = NewErr(
+				ErrJSONSelectorConflict,
+				"selector", selector,
+				"conflicting_selector", other,
+			)
+			goto end
+		}
+		selected[path] = selector
+		for depth := 0; depth < len(paths[i]); depth++ {
+			ancestors[formatSelector(paths[i][:depth])] = selector
+		}
+	}
+
+end:
+	return paths, err
+}
+
+// insertSubtree sets value in subtree at path, creating the objects leading
+// to it as needed.
+func insertSubtree(subtree map[string]any, path []segment, value any) {
+	node := subtree
+	for _, seg := range path[:len(path)-1] {
+		child, ok := node[seg.text].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			node[seg.text] = child
+		}
+		node = child
+	}
+	node[path[len(path)-1].text] = value
+}
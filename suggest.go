@@ -0,0 +1,46 @@
+package jsonxtractr
+
+import (
+	"unicode/utf8"
+)
+
+// suggestKey returns the key among keys closest to target by edit distance,
+// provided it is close enough to plausibly be what a mistyped target meant:
+// within one edit per three characters of target, rounded up.
+// Ties go to the key that comes first in keys.
+func suggestKey(target string, keys []string) (suggestion string, ok bool) {
+	best := max(1, (utf8.RuneCountInString(target)+2)/3) + 1
+	for _, key := range keys {
+		distance := editDistance(target, key)
+		if distance == 0 || distance >= best {
+			continue
+		}
+		best = distance
+		suggestion, ok = key, true
+	}
+	return suggestion, ok
+}
+
+// editDistance returns the Levenshtein distance between a and b, counting
+// the insertions, deletions and substitutions of runes needed to turn one
+// into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
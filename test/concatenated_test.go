@@ -0,0 +1,99 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// documentResult records one call of an ExtractFromDocuments callback.
+type documentResult struct {
+	index int
+	value any
+	err   error
+}
+
+func collectDocuments(input string, selector jsonxtractr.Selector) (results []documentResult) {
+	jsonxtractr.ExtractFromDocuments(strings.NewReader(input), selector, func(docIndex int, value any, err error) {
+		results = append(results, documentResult{index: docIndex, value: value, err: err})
+	})
+	return results
+}
+
+func TestExtractFromDocuments(t *testing.T) {
+	input := `{"id": 1, "tags": ["a"]}{"id": 2}
+	[{"id": 3}]`
+
+	results := collectDocuments(input, "id")
+	if len(results) != 3 {
+		t.Fatalf("ExtractFromDocuments() made %d calls, want 3", len(results))
+	}
+	for i, want := range []any{float64(1), float64(2), nil} {
+		if results[i].index != i {
+			t.Errorf("call %d: docIndex = %d, want %d", i, results[i].index, i)
+		}
+		if !reflect.DeepEqual(results[i].value, want) {
+			t.Errorf("call %d: value = %v, want %v", i, results[i].value, want)
+		}
+	}
+	if results[0].err != nil || results[1].err != nil {
+		t.Errorf("ExtractFromDocuments() errors = %v, %v, want nil", results[0].err, results[1].err)
+	}
+	if !errors.Is(results[2].err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment) {
+		t.Errorf("call 2: error = %v, want %v", results[2].err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment)
+	}
+
+	// The array document can still be selected into
+	results = collectDocuments(input, "0.id")
+	if !reflect.DeepEqual(results[2].value, float64(3)) || results[2].err != nil {
+		t.Errorf("call 2: value = %v, error = %v, want 3", results[2].value, results[2].err)
+	}
+}
+
+func TestExtractFromDocuments_Scalars(t *testing.T) {
+	results := collectDocuments(`1 "two" {"a": true} null`, jsonxtractr.RootSelector)
+	var values []any
+	for _, result := range results {
+		if result.err != nil {
+			t.Errorf("document %d: error = %v", result.index, result.err)
+		}
+		values = append(values, result.value)
+	}
+	want := []any{float64(1), "two", map[string]any{"a": true}, nil}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractFromDocuments() values = %v, want %v", values, want)
+	}
+
+	results = collectDocuments(`1 {"a": true}`, "a")
+	if !errors.Is(results[0].err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment) {
+		t.Errorf("document 0: error = %v, want %v", results[0].err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment)
+	}
+	if results[1].value != true {
+		t.Errorf("document 1: value = %v, want true", results[1].value)
+	}
+}
+
+func TestExtractFromDocuments_Errors(t *testing.T) {
+	results := collectDocuments(`{"a": 1}{"b": 2}{"a": `, "a")
+	if len(results) != 3 {
+		t.Fatalf("ExtractFromDocuments() made %d calls, want 3", len(results))
+	}
+	if !errors.Is(results[1].err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("document 1: error = %v, want %v", results[1].err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	if !errors.Is(results[2].err, jsonxtractr.ErrJSONStreamingParseFailed) {
+		t.Errorf("document 2: error = %v, want %v", results[2].err, jsonxtractr.ErrJSONStreamingParseFailed)
+	}
+
+	results = collectDocuments(`{"a": 1}`, "a...b")
+	if len(results) != 1 || !errors.Is(results[0].err, jsonxtractr.ErrJSONPathContainsEmptySegment) {
+		t.Errorf("ExtractFromDocuments() = %v, want one %v", results, jsonxtractr.ErrJSONPathContainsEmptySegment)
+	}
+
+	if results := collectDocuments("  ", "a"); len(results) != 0 {
+		t.Errorf("ExtractFromDocuments() = %v, want no calls", results)
+	}
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestCount(t *testing.T) {
+	jsonData := []byte(`{
+		"items": [1, {"a": [1, 2, 3]}, [4, 5], "six", null],
+		"user": {"name": "Alice", "tags": ["x"], "address": {"city": "Paris"}},
+		"none": [],
+		"blank": {}
+	}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     int
+	}{
+		{name: "array", selector: "items", want: 5},
+		{name: "nested array", selector: "items.1.a", want: 3},
+		{name: "object", selector: "user", want: 3},
+		{name: "root object", selector: jsonxtractr.RootSelector, want: 4},
+		{name: "empty array", selector: "none", want: 0},
+		{name: "empty object", selector: "blank", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Count(jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCount_Errors(t *testing.T) {
+	jsonData := []byte(`{"name": "Alice", "age": 30, "ok": true, "nothing": null, "list": [1]}`)
+
+	tests := []struct {
+		name     string
+		raw      []byte
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "string", raw: jsonData, selector: "name", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "number", raw: jsonData, selector: "age", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "bool", raw: jsonData, selector: "ok", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "null", raw: jsonData, selector: "nothing", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "missing", raw: jsonData, selector: "list.3", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "truncated array", raw: []byte(`{"list": [1, 2, `), selector: "list", wantErr: jsonxtractr.ErrJSONTokenReadFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Count(tt.raw, tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Count() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != 0 {
+				t.Errorf("Count() = %d, want 0 on error", got)
+			}
+		})
+	}
+}
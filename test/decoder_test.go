@@ -0,0 +1,85 @@
+package test
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// countingDecoder wraps a jsontext.Decoder, counting the tokens read through
+// it to show it was the one used.
+type countingDecoder struct {
+	*jsontext.Decoder
+	tokens *atomic.Int64
+}
+
+func (d countingDecoder) ReadToken() (jsontext.Token, error) {
+	d.tokens.Add(1)
+	return d.Decoder.ReadToken()
+}
+
+func (d countingDecoder) UnmarshalInto(v any, opts ...jsonv2.Options) error {
+	return jsonv2.UnmarshalDecode(d.Decoder, v, opts...)
+}
+
+func TestWithDecoderFactory(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice", "tags": ["a", "b"]}, "items": [{"id": 1}, {"id": 2}], "count": 2}`)
+
+	var tokens atomic.Int64
+	factory := jsonxtractr.WithDecoderFactory(func(reader io.Reader) jsonxtractr.Decoder {
+		return countingDecoder{Decoder: jsontext.NewDecoder(reader), tokens: &tokens}
+	})
+
+	t.Run("single value", func(t *testing.T) {
+		tokens.Store(0)
+		got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, "user.tags.-1", factory)
+		if err != nil || got != "b" {
+			t.Errorf("ExtractValueFromBytesOpts() = %v, %v, want b", got, err)
+		}
+		if tokens.Load() == 0 {
+			t.Error("ExtractValueFromBytesOpts() read no tokens through the injected decoder")
+		}
+	})
+
+	t.Run("several values", func(t *testing.T) {
+		tokens.Store(0)
+		selectors := []jsonxtractr.Selector{"user.name", "items.#(id==2).id", "count", "missing"}
+		got, notFound, _ := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, selectors, factory)
+		want, wantNotFound, _ := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+		if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(notFound, wantNotFound) {
+			t.Errorf("ExtractValuesFromBytesOpts() = %v, %v, want %v, %v", got, notFound, want, wantNotFound)
+		}
+		if tokens.Load() == 0 {
+			t.Error("ExtractValuesFromBytesOpts() read no tokens through the injected decoder")
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		tokens.Store(0)
+		got, err := jsonxtractr.ExtractValueFromReaderOpts(strings.NewReader(string(jsonData)), "items.1.id", factory)
+		if err != nil || got != float64(2) {
+			t.Errorf("ExtractValueFromReaderOpts() = %v, %v, want 2", got, err)
+		}
+		if tokens.Load() == 0 {
+			t.Error("ExtractValueFromReaderOpts() read no tokens through the injected decoder")
+		}
+	})
+
+	t.Run("matches", func(t *testing.T) {
+		tokens.Store(0)
+		got, err := jsonxtractr.ExtractMatchesOpts(jsonData, "items.*.id", factory)
+		want, _ := jsonxtractr.ExtractMatches(jsonData, "items.*.id")
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ExtractMatchesOpts() = %v, %v, want %v", got, err, want)
+		}
+		if tokens.Load() == 0 {
+			t.Error("ExtractMatchesOpts() read no tokens through the injected decoder")
+		}
+	})
+}
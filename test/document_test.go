@@ -0,0 +1,216 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestDocument_Value(t *testing.T) {
+	doc, err := jsonxtractr.NewDocument([]byte(`{
+		"user": {"name": "Alice", "age": 30, "tags": ["admin", "dev"]},
+		"scores": [100, 85, 92],
+		"empty": {}
+	}`))
+	if err != nil {
+		t.Fatalf("NewDocument() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		selector     jsonxtractr.Selector
+		want         any
+		wantErrIsAll []error
+	}{
+		{
+			name:     "nested key",
+			selector: "user.name",
+			want:     "Alice",
+		},
+		{
+			name:     "array element",
+			selector: "scores.2",
+			want:     float64(92),
+		},
+		{
+			name:     "nested array element",
+			selector: "user.tags.1",
+			want:     "dev",
+		},
+		{
+			name:     "object subtree",
+			selector: "user",
+			want: map[string]any{
+				"name": "Alice",
+				"age":  float64(30),
+				"tags": []any{"admin", "dev"},
+			},
+		},
+		{
+			name:     "empty object",
+			selector: "empty",
+			want:     map[string]any{},
+		},
+		{
+			name:     "missing key",
+			selector: "user.email",
+			wantErrIsAll: []error{
+				jsonxtractr.ErrFailedToExtractValueFromJSON,
+				jsonxtractr.ErrJSONPathSegmentNotFound,
+			},
+		},
+		{
+			name:     "index out of range",
+			selector: "scores.3",
+			wantErrIsAll: []error{
+				jsonxtractr.ErrJSONIndexOutOfRange,
+			},
+		},
+		{
+			name:     "numeric key missing from object",
+			selector: "user.0",
+			wantErrIsAll: []error{
+				jsonxtractr.ErrJSONPathSegmentNotFound,
+			},
+		},
+		{
+			name:     "empty segment",
+			selector: "user...name",
+			wantErrIsAll: []error{
+				jsonxtractr.ErrJSONPathContainsEmptySegment,
+			},
+		},
+		{
+			name:     "empty selector",
+			selector: "",
+			wantErrIsAll: []error{
+				jsonxtractr.ErrJSONValueSelectorCannotBeEmpty,
+			},
+		},
+	}
+
+	// Query the same document repeatedly to confirm lookups don't consume it
+	for range 2 {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := doc.Value(tt.selector)
+				if len(tt.wantErrIsAll) > 0 {
+					if err == nil {
+						t.Fatalf("Value() expected an error, got nil (value=%#v)", got)
+					}
+					for _, we := range tt.wantErrIsAll {
+						if !errors.Is(err, we) {
+							t.Fatalf("Value() error %v is not errors.Is(...) to %v", err, we)
+						}
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Value() unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("Value() got %#v (%T), want %#v (%T)", got, got, tt.want, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestDocument_Values(t *testing.T) {
+	jsonData := []byte(`{"a": 1, "b": {"c": 2}, "d": [3, 4, 5]}`)
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() unexpected error: %v", err)
+	}
+
+	selectors := []jsonxtractr.Selector{"a", "b.c", "b.missing", "d.2", "d.9"}
+	valuesMap, notFound, err := doc.Values(selectors)
+	if err == nil {
+		t.Fatal("Expected error for missing selectors")
+	}
+
+	// Results should match the streaming extraction exactly
+	wantValuesMap, wantNotFound, _ := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+	if !reflect.DeepEqual(valuesMap, wantValuesMap) {
+		t.Errorf("ValuesMap mismatch:\n  got:  %#v\n  want: %#v", valuesMap, wantValuesMap)
+	}
+	if !reflect.DeepEqual(notFound, wantNotFound) {
+		t.Errorf("NotFound selectors mismatch:\n  got:  %v\n  want: %v", notFound, wantNotFound)
+	}
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("Error should be errors.Is(..., ErrJSONPathSegmentNotFound): %v", err)
+	}
+	if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+		t.Errorf("Error should be errors.Is(..., ErrJSONIndexOutOfRange): %v", err)
+	}
+}
+
+func TestNewDocument_InvalidJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{"empty body", ``, jsonxtractr.ErrJSONBodyCannotBeEmpty},
+		{"unterminated object", `{"a": 1`, jsonxtractr.ErrJSONTokenReadFailed},
+		{"malformed array", `[1, 2,, 3]`, jsonxtractr.ErrJSONTokenReadFailed},
+		{"trailing garbage", `{"a": 1} garbage`, jsonxtractr.ErrJSONTokenReadFailed},
+		{"second value", `{"a": 1} {"a": 2}`, jsonxtractr.ErrJSONStreamingParseFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := jsonxtractr.NewDocument([]byte(tt.raw))
+			if err == nil {
+				t.Fatalf("NewDocument() expected an error, got nil (doc=%v)", doc)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("NewDocument() error %v is not errors.Is(...) to %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDocument_CopiesInput(t *testing.T) {
+	raw := []byte(`{"a": "x"}`)
+	doc, err := jsonxtractr.NewDocument(raw)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	// Changing the caller's slice leaves the Document as it was
+	copy(raw, `{"a": "y"}`)
+	value, err := doc.Value("a")
+	if err != nil || value != "x" {
+		t.Errorf("Value() = %v, %v, want x", value, err)
+	}
+
+	// Trailing whitespace is not a second value
+	_, err = jsonxtractr.NewDocument([]byte("{\"a\": 1}\n\t "))
+	if err != nil {
+		t.Errorf("NewDocument() error = %v, want nil", err)
+	}
+}
+
+func TestDocument_NegativeIndex(t *testing.T) {
+	doc, err := jsonxtractr.NewDocument([]byte(`{"xs": [10, 20, 30]}`))
+	if err != nil {
+		t.Fatalf("NewDocument() unexpected error: %v", err)
+	}
+
+	got, err := doc.Value("xs.-1")
+	if err != nil {
+		t.Fatalf("Value() unexpected error: %v", err)
+	}
+	if got != float64(30) {
+		t.Errorf("Value() got %#v, want %#v", got, float64(30))
+	}
+
+	_, err = doc.Value("xs.-4")
+	if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+		t.Errorf("Value() error %v is not errors.Is(...) to ErrJSONIndexOutOfRange", err)
+	}
+}
@@ -0,0 +1,203 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExists(t *testing.T) {
+	jsonData := `{"user": {"name": "Alice", "email": null, "tags": ["a", "b"]}, "count": 3}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     bool
+	}{
+		{name: "present key", selector: "user.name", want: true},
+		{name: "present null", selector: "user.email", want: true},
+		{name: "present object", selector: "user", want: true},
+		{name: "present index", selector: "user.tags.1", want: true},
+		{name: "present negative index", selector: "user.tags.-1", want: true},
+		{name: "absent key", selector: "user.phone", want: false},
+		{name: "absent nested key", selector: "account.id", want: false},
+		{name: "absent index", selector: "user.tags.2", want: false},
+		{name: "scalar where object expected", selector: "count.value", want: false},
+		{name: "object where array expected", selector: "user.0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Exists([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Exists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExists_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "truncated before key", raw: `{"a": 1, `, selector: "b", wantErr: jsonxtractr.ErrJSONTokenReadFailed},
+		{name: "truncated selected value", raw: `{"a": [1, 2`, selector: "a", wantErr: jsonxtractr.ErrJSONStreamingParseFailed},
+		{name: "empty body", raw: ``, selector: "a", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+		{name: "empty segment", raw: `{"a": {"b": 1}}`, selector: "a...b", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{name: "empty selector", raw: `{"a": 1}`, selector: "", wantErr: jsonxtractr.ErrJSONValueSelectorCannotBeEmpty},
+		{name: "unbalanced quote", raw: `{"a": 1}`, selector: `"a`, wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Exists([]byte(tt.raw), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Exists() error = %v, want %v", err, tt.wantErr)
+			}
+			if got {
+				t.Errorf("Exists() = true, want false on error")
+			}
+		})
+	}
+}
+
+func TestPresentNullVersusAbsent(t *testing.T) {
+	jsonData := []byte(`{"user": {"email": null, "tags": [null]}}`)
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	for _, selector := range []jsonxtractr.Selector{"user.email", "user.tags.0"} {
+		value, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+		if err != nil || value != nil {
+			t.Errorf("ExtractValueFromBytes(%s) = %v, %v, want nil, nil", selector, value, err)
+		}
+		value, err = doc.Value(selector)
+		if err != nil || value != nil {
+			t.Errorf("Document.Value(%s) = %v, %v, want nil, nil", selector, value, err)
+		}
+		values, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{selector})
+		if _, ok := values[selector]; !ok || len(notFound) != 0 || err != nil {
+			t.Errorf("ExtractValuesFromBytes(%s) = %v, %v, %v, want a nil value present", selector, values, notFound, err)
+		}
+		if exists, err := jsonxtractr.Exists(jsonData, selector); !exists || err != nil {
+			t.Errorf("Exists(%s) = %v, %v, want true, nil", selector, exists, err)
+		}
+		if value, err := jsonxtractr.ExtractValueOr(jsonData, selector, "default"); value != nil || err != nil {
+			t.Errorf("ExtractValueOr(%s) = %v, %v, want nil, nil", selector, value, err)
+		}
+	}
+
+	for _, selector := range []jsonxtractr.Selector{"user.phone", "user.tags.1", "user.email.address"} {
+		value, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+		if !jsonxtractr.IsAbsent(err) || value != nil {
+			t.Errorf("ExtractValueFromBytes(%s) = %v, %v, want an absent error", selector, value, err)
+		}
+		_, err = doc.Value(selector)
+		if !jsonxtractr.IsAbsent(err) {
+			t.Errorf("Document.Value(%s) error = %v, want an absent error", selector, err)
+		}
+		if exists, err := jsonxtractr.Exists(jsonData, selector); exists || err != nil {
+			t.Errorf("Exists(%s) = %v, %v, want false, nil", selector, exists, err)
+		}
+	}
+
+	_, err = jsonxtractr.ExtractValueFromBytes(jsonData, "user.phone")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValueFromBytes(user.phone) error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+}
+
+func TestIsAbsent(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{name: "malformed JSON", raw: `{"a": `, want: false},
+		{name: "empty body", raw: ``, want: false},
+		{name: "present", raw: `{"a": 1}`, want: false},
+		{name: "absent", raw: `{"b": 1}`, want: true},
+		{name: "wrong type", raw: `[1]`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytes([]byte(tt.raw), "a")
+			if got := jsonxtractr.IsAbsent(err); got != tt.want {
+				t.Errorf("IsAbsent(%v) = %v, want %v", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExistsNonNull(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice", "email": null, "tags": [null, 0], "active": false}}`)
+
+	tests := []struct {
+		name        string
+		selector    jsonxtractr.Selector
+		wantExists  bool
+		wantNonNull bool
+	}{
+		{name: "absent key", selector: "user.phone", wantExists: false, wantNonNull: false},
+		{name: "absent index", selector: "user.tags.2", wantExists: false, wantNonNull: false},
+		{name: "wrong type", selector: "user.name.first", wantExists: false, wantNonNull: false},
+		{name: "present null", selector: "user.email", wantExists: true, wantNonNull: false},
+		{name: "present null element", selector: "user.tags.0", wantExists: true, wantNonNull: false},
+		{name: "present string", selector: "user.name", wantExists: true, wantNonNull: true},
+		{name: "present zero", selector: "user.tags.1", wantExists: true, wantNonNull: true},
+		{name: "present false", selector: "user.active", wantExists: true, wantNonNull: true},
+		{name: "present object", selector: "user", wantExists: true, wantNonNull: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, err := jsonxtractr.Exists(jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			nonNull, err := jsonxtractr.ExistsNonNull(jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("ExistsNonNull() error = %v", err)
+			}
+			if exists != tt.wantExists || nonNull != tt.wantNonNull {
+				t.Errorf("Exists(), ExistsNonNull() = %v, %v, want %v, %v", exists, nonNull, tt.wantExists, tt.wantNonNull)
+			}
+		})
+	}
+}
+
+func TestExistsNonNull_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "truncated before key", raw: `{"a": 1, `, selector: "b", wantErr: jsonxtractr.ErrJSONTokenReadFailed},
+		{name: "empty body", raw: ``, selector: "a", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+		{name: "empty segment", raw: `{"a": {"b": 1}}`, selector: "a...b", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExistsNonNull([]byte(tt.raw), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExistsNonNull() error = %v, want %v", err, tt.wantErr)
+			}
+			if got {
+				t.Errorf("ExistsNonNull() = true, want false on error")
+			}
+		})
+	}
+}
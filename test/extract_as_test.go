@@ -0,0 +1,530 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+const extractAsJSON = `{
+	"count": 42,
+	"level": 300,
+	"big": 9223372036854775807,
+	"huge": 1e300,
+	"ratio": 2.5,
+	"name": "Alice",
+	"active": true,
+	"flag": "true",
+	"user": {"name": "Bob", "age": 30, "tags": ["a", "b"], "extra": null},
+	"ids": [1, 2, 3]
+}`
+
+func TestExtractAs_Int(t *testing.T) {
+	got, err := jsonxtractr.ExtractAs[int]([]byte(extractAsJSON), "count")
+	if err != nil {
+		t.Fatalf("ExtractAs[int]() error = %v", err)
+	}
+	if got != 42 {
+		t.Errorf("ExtractAs[int]() = %d, want 42", got)
+	}
+
+	big, err := jsonxtractr.ExtractAs[int64]([]byte(extractAsJSON), "big")
+	if err != nil {
+		t.Fatalf("ExtractAs[int64]() error = %v", err)
+	}
+	if big != 9223372036854775807 {
+		t.Errorf("ExtractAs[int64]() = %d, want max int64", big)
+	}
+}
+
+func TestExtractAs_String(t *testing.T) {
+	got, err := jsonxtractr.ExtractAs[string]([]byte(extractAsJSON), "user.name")
+	if err != nil {
+		t.Fatalf("ExtractAs[string]() error = %v", err)
+	}
+	if got != "Bob" {
+		t.Errorf("ExtractAs[string]() = %q, want %q", got, "Bob")
+	}
+}
+
+func TestExtractAs_Bool(t *testing.T) {
+	got, err := jsonxtractr.ExtractAs[bool]([]byte(extractAsJSON), "active")
+	if err != nil {
+		t.Fatalf("ExtractAs[bool]() error = %v", err)
+	}
+	if !got {
+		t.Errorf("ExtractAs[bool]() = %v, want true", got)
+	}
+}
+
+func TestExtractAs_Struct(t *testing.T) {
+	type user struct {
+		Name string   `json:"name"`
+		Age  int      `json:"age"`
+		Tags []string `json:"tags"`
+	}
+
+	got, err := jsonxtractr.ExtractAs[user]([]byte(extractAsJSON), "user")
+	if err != nil {
+		t.Fatalf("ExtractAs[user]() error = %v", err)
+	}
+	want := user{Name: "Bob", Age: 30, Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractAs[user]() = %+v, want %+v", got, want)
+	}
+
+	ids, err := jsonxtractr.ExtractAs[[]int]([]byte(extractAsJSON), "ids")
+	if err != nil {
+		t.Fatalf("ExtractAs[[]int]() error = %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("ExtractAs[[]int]() = %v, want [1 2 3]", ids)
+	}
+}
+
+func TestExtractAs_SelectorNotFound(t *testing.T) {
+	_, err := jsonxtractr.ExtractAs[int]([]byte(extractAsJSON), "missing")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractAs[int]() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	if errors.Is(err, jsonxtractr.ErrJSONTypeMismatch) {
+		t.Errorf("ExtractAs[int]() error = %v, want no %v", err, jsonxtractr.ErrJSONTypeMismatch)
+	}
+}
+
+func TestExtractAs_TypeMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		extract func() error
+	}{
+		{name: "string as bool", extract: func() error {
+			_, err := jsonxtractr.ExtractAs[bool]([]byte(extractAsJSON), "flag")
+			return err
+		}},
+		{name: "fraction as int", extract: func() error {
+			_, err := jsonxtractr.ExtractAs[int]([]byte(extractAsJSON), "ratio")
+			return err
+		}},
+		{name: "overflow int8", extract: func() error {
+			_, err := jsonxtractr.ExtractAs[int8]([]byte(extractAsJSON), "level")
+			return err
+		}},
+		{name: "overflow int64", extract: func() error {
+			_, err := jsonxtractr.ExtractAs[int64]([]byte(extractAsJSON), "huge")
+			return err
+		}},
+		{name: "number as string", extract: func() error {
+			_, err := jsonxtractr.ExtractAs[string]([]byte(extractAsJSON), "count")
+			return err
+		}},
+		{name: "object as int", extract: func() error {
+			_, err := jsonxtractr.ExtractAs[int]([]byte(extractAsJSON), "user")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.extract()
+			if !errors.Is(err, jsonxtractr.ErrJSONTypeMismatch) {
+				t.Errorf("ExtractAs() error = %v, want %v", err, jsonxtractr.ErrJSONTypeMismatch)
+			}
+		})
+	}
+}
+
+func TestExtractInto_Struct(t *testing.T) {
+	type user struct {
+		FullName string   `json:"name"`
+		Years    int      `json:"age"`
+		Labels   []string `json:"tags"`
+		Ignored  string   `json:"-"`
+	}
+
+	var got user
+	err := jsonxtractr.ExtractInto([]byte(extractAsJSON), "user", &got)
+	if err != nil {
+		t.Fatalf("ExtractInto() error = %v", err)
+	}
+	want := user{FullName: "Bob", Years: 30, Labels: []string{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractInto() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExtractInto_Map(t *testing.T) {
+	var got map[string]any
+	err := jsonxtractr.ExtractInto([]byte(extractAsJSON), "user", &got)
+	if err != nil {
+		t.Fatalf("ExtractInto() error = %v", err)
+	}
+	want := map[string]any{
+		"name":  "Bob",
+		"age":   float64(30),
+		"tags":  []any{"a", "b"},
+		"extra": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractInto() = %v, want %v", got, want)
+	}
+}
+
+func TestExtractInto_Slice(t *testing.T) {
+	var got []int
+	err := jsonxtractr.ExtractInto([]byte(extractAsJSON), "ids", &got)
+	if err != nil {
+		t.Fatalf("ExtractInto() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("ExtractInto() = %v, want [1 2 3]", got)
+	}
+}
+
+func TestExtractInto_Errors(t *testing.T) {
+	var name string
+	var count int
+	var nilPtr *string
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		dst      any
+		wantErr  error
+	}{
+		{name: "missing key", selector: "user.missing", dst: &name, wantErr: jsonxtractr.ErrJSONSelectorNotFound},
+		{name: "index out of range", selector: "ids.5", dst: &count, wantErr: jsonxtractr.ErrJSONSelectorNotFound},
+		{name: "key through a scalar", selector: "count.x", dst: &count, wantErr: jsonxtractr.ErrJSONSelectorNotFound},
+		{name: "nil destination", selector: "user.name", dst: nil, wantErr: jsonxtractr.ErrJSONDestinationInvalid},
+		{name: "nil pointer destination", selector: "user.name", dst: nilPtr, wantErr: jsonxtractr.ErrJSONDestinationInvalid},
+		{name: "non-pointer destination", selector: "user.name", dst: name, wantErr: jsonxtractr.ErrJSONDestinationInvalid},
+		{name: "mismatched destination", selector: "user.name", dst: &count, wantErr: jsonxtractr.ErrJSONUnmarshalFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := jsonxtractr.ExtractInto([]byte(extractAsJSON), tt.selector, tt.dst)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExtractInto() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTypedExtractors(t *testing.T) {
+	jsonData := []byte(`{
+		"name": "Alice",
+		"empty": "",
+		"count": 42,
+		"negative": -7,
+		"whole": 3.0,
+		"ratio": 1.5,
+		"big": 9007199254740993,
+		"huge": 1e30,
+		"hundred": 1e2,
+		"nearly": 12345678901234567890.5,
+		"active": true,
+		"inactive": false,
+		"nothing": null,
+		"numeric": "12",
+		"list": [1]
+	}`)
+
+	extract := map[string]func(selector jsonxtractr.Selector) (any, error){
+		"ExtractString": func(selector jsonxtractr.Selector) (any, error) {
+			return jsonxtractr.ExtractString(jsonData, selector)
+		},
+		"ExtractInt": func(selector jsonxtractr.Selector) (any, error) {
+			return jsonxtractr.ExtractInt(jsonData, selector)
+		},
+		"ExtractInt64": func(selector jsonxtractr.Selector) (any, error) {
+			return jsonxtractr.ExtractInt64(jsonData, selector)
+		},
+		"ExtractFloat64": func(selector jsonxtractr.Selector) (any, error) {
+			return jsonxtractr.ExtractFloat64(jsonData, selector)
+		},
+		"ExtractBool": func(selector jsonxtractr.Selector) (any, error) {
+			return jsonxtractr.ExtractBool(jsonData, selector)
+		},
+	}
+
+	tests := []struct {
+		fn       string
+		selector jsonxtractr.Selector
+		want     any
+		wantErr  error
+	}{
+		{fn: "ExtractString", selector: "name", want: "Alice"},
+		{fn: "ExtractString", selector: "empty", want: ""},
+		{fn: "ExtractString", selector: "count", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractString", selector: "nothing", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractString", selector: "list", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+
+		{fn: "ExtractInt", selector: "count", want: 42},
+		{fn: "ExtractInt", selector: "negative", want: -7},
+		{fn: "ExtractInt", selector: "whole", want: 3},
+		{fn: "ExtractInt", selector: "ratio", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractInt", selector: "huge", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractInt", selector: "numeric", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractInt", selector: "nothing", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+
+		{fn: "ExtractInt64", selector: "count", want: int64(42)},
+		{fn: "ExtractInt64", selector: "big", want: int64(9007199254740993)},
+		{fn: "ExtractInt64", selector: "hundred", want: int64(100)},
+		{fn: "ExtractInt64", selector: "ratio", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractInt64", selector: "nearly", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractInt64", selector: "name", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+
+		{fn: "ExtractFloat64", selector: "ratio", want: 1.5},
+		{fn: "ExtractFloat64", selector: "count", want: float64(42)},
+		{fn: "ExtractFloat64", selector: "active", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractFloat64", selector: "numeric", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+
+		{fn: "ExtractBool", selector: "active", want: true},
+		{fn: "ExtractBool", selector: "inactive", want: false},
+		{fn: "ExtractBool", selector: "name", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractBool", selector: "count", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{fn: "ExtractBool", selector: "nothing", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+
+		{fn: "ExtractString", selector: "missing", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn+"/"+string(tt.selector), func(t *testing.T) {
+			got, err := extract[tt.fn](tt.selector)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("%s() error = %v, want %v", tt.fn, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.fn, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s() = %#v, want %#v", tt.fn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    int64
+		wantErr error
+	}{
+		{name: "float64", value: float64(42), want: 42},
+		{name: "negative float64", value: float64(-7), want: -7},
+		{name: "json.Number", value: json.Number("12345678901234567"), want: 12345678901234567},
+		{name: "json.Number exponent", value: json.Number("1e2"), want: 100},
+		{name: "int", value: 5, want: 5},
+		{name: "int64", value: int64(-5), want: -5},
+		{name: "non-integral float64", value: 2.5, wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "non-integral json.Number", value: json.Number("2.5"), wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "NaN", value: math.NaN(), wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "overflowing float64", value: 1e300, wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "float64 at 2^63", value: float64(math.MaxInt64), wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "json.Number at MinInt64", value: json.Number("-9223372036854775808"), want: math.MinInt64},
+		{name: "overflowing json.Number", value: json.Number("9223372036854775808"), wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "json.Number with huge exponent", value: json.Number("1e640000000"), wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "overflowing uint64", value: uint64(math.MaxUint64), wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "invalid json.Number", value: json.Number("0x10"), wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "string", value: "42", wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "bool", value: true, wantErr: jsonxtractr.ErrJSONTypeMismatch},
+		{name: "nil", value: nil, wantErr: jsonxtractr.ErrJSONTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.AsInt64(tt.value)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("AsInt64() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AsInt64() = %d, want %d", got, tt.want)
+			}
+
+			gotInt, err := jsonxtractr.AsInt(tt.value)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("AsInt() error = %v, want %v", err, tt.wantErr)
+			}
+			if int64(gotInt) != tt.want {
+				t.Errorf("AsInt() = %d, want %d", gotInt, tt.want)
+			}
+		})
+	}
+
+	// Extracted values convert as they come
+	value, err := jsonxtractr.ExtractValueFromBytes([]byte(extractAsJSON), "count")
+	if err != nil {
+		t.Fatalf("ExtractValueFromBytes() error = %v", err)
+	}
+	n, err := jsonxtractr.AsInt(value)
+	if err != nil || n != 42 {
+		t.Errorf("AsInt() = %d, %v, want 42", n, err)
+	}
+}
+
+func TestExtractMapAs(t *testing.T) {
+	jsonData := []byte(`{
+	"limits": {"cpu": 4, "memory": 2048, "disks": 2e0},
+	"labels": {"env": "prod", "team": "core"},
+	"servers": {"a": {"host": "a.example", "port": 80}, "b": {"host": "b.example", "port": 8080}},
+	"empty": {},
+	"dup": {"k": 1, "k": 2},
+	"mixed": {"ok": 1, "bad": 1.5},
+	"list": [1, 2]
+}`)
+
+	t.Run("map[string]int", func(t *testing.T) {
+		got, err := jsonxtractr.ExtractMapAs[int](jsonData, "limits")
+		if err != nil {
+			t.Fatalf("ExtractMapAs() error = %v", err)
+		}
+		want := map[string]int{"cpu": 4, "memory": 2048, "disks": 2}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ExtractMapAs() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("map[string]string", func(t *testing.T) {
+		got, err := jsonxtractr.ExtractMapAs[string](jsonData, "labels")
+		if err != nil {
+			t.Fatalf("ExtractMapAs() error = %v", err)
+		}
+		want := map[string]string{"env": "prod", "team": "core"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ExtractMapAs() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("map[string]struct", func(t *testing.T) {
+		type server struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+		}
+		got, err := jsonxtractr.ExtractMapAs[server](jsonData, "servers")
+		if err != nil {
+			t.Fatalf("ExtractMapAs() error = %v", err)
+		}
+		want := map[string]server{"a": {Host: "a.example", Port: 80}, "b": {Host: "b.example", Port: 8080}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ExtractMapAs() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("empty object", func(t *testing.T) {
+		got, err := jsonxtractr.ExtractMapAs[int](jsonData, "empty")
+		if err != nil || got == nil || len(got) != 0 {
+			t.Errorf("ExtractMapAs() = %v, %v, want an empty map", got, err)
+		}
+	})
+
+	t.Run("first duplicate wins", func(t *testing.T) {
+		got, err := jsonxtractr.ExtractMapAs[int](jsonData, "dup")
+		if err != nil || got["k"] != 1 {
+			t.Errorf("ExtractMapAs() = %v, %v, want k=1", got, err)
+		}
+	})
+
+	t.Run("value that doesn't fit", func(t *testing.T) {
+		_, err := jsonxtractr.ExtractMapAs[int](jsonData, "mixed")
+		if !errors.Is(err, jsonxtractr.ErrJSONTypeMismatch) {
+			t.Fatalf("ExtractMapAs() error = %v, want %v", err, jsonxtractr.ErrJSONTypeMismatch)
+		}
+		if !strings.Contains(err.Error(), "key=bad") {
+			t.Errorf("ExtractMapAs() error = %v, want it to name key=bad", err)
+		}
+	})
+
+	for _, selector := range []jsonxtractr.Selector{"list", "labels.env"} {
+		_, err := jsonxtractr.ExtractMapAs[int](jsonData, selector)
+		if !errors.Is(err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment) {
+			t.Errorf("ExtractMapAs(%s) error = %v, want %v", selector, err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment)
+		}
+	}
+
+	_, err := jsonxtractr.ExtractMapAs[int](jsonData, "missing")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractMapAs() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+}
+
+func TestExtractIntoOpts_RejectUnknownMembers(t *testing.T) {
+	type server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	jsonData := []byte(`{
+	"known": {"host": "a.example", "port": 80},
+	"extra": {"host": "b.example", "port": 8080, "tls": true}
+}`)
+
+	var got server
+	err := jsonxtractr.ExtractIntoOpts(jsonData, "known", &got, jsonxtractr.WithRejectUnknownMembers())
+	if err != nil {
+		t.Fatalf("ExtractIntoOpts(known) error = %v", err)
+	}
+	if got != (server{Host: "a.example", Port: 80}) {
+		t.Errorf("ExtractIntoOpts(known) = %+v", got)
+	}
+
+	got = server{}
+	err = jsonxtractr.ExtractIntoOpts(jsonData, "extra", &got, jsonxtractr.WithRejectUnknownMembers())
+	if !errors.Is(err, jsonxtractr.ErrJSONUnmarshalFailed) {
+		t.Errorf("ExtractIntoOpts(extra) error = %v, want %v", err, jsonxtractr.ErrJSONUnmarshalFailed)
+	}
+
+	// Without the option the unknown member is ignored
+	got = server{}
+	err = jsonxtractr.ExtractInto(jsonData, "extra", &got)
+	if err != nil {
+		t.Fatalf("ExtractInto(extra) error = %v", err)
+	}
+	if got != (server{Host: "b.example", Port: 8080}) {
+		t.Errorf("ExtractInto(extra) = %+v", got)
+	}
+}
+
+func TestExtractAs_HugeExponent(t *testing.T) {
+	jsonData := []byte(`{"m":{"a":1e640000000}}`)
+
+	extractors := map[string]func() error{
+		"ExtractAs": func() error {
+			_, err := jsonxtractr.ExtractAs[int64](jsonData, "m.a")
+			return err
+		},
+		"ExtractMapAs": func() error {
+			_, err := jsonxtractr.ExtractMapAs[uint8](jsonData, "m")
+			return err
+		},
+		"AsInt64": func() error {
+			_, err := jsonxtractr.AsInt64(json.Number("1e640000000"))
+			return err
+		},
+	}
+
+	for name, extract := range extractors {
+		t.Run(name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			err := extract()
+			runtime.ReadMemStats(&after)
+
+			if !errors.Is(err, jsonxtractr.ErrJSONTypeMismatch) {
+				t.Errorf("%s() error = %v, want %v", name, err, jsonxtractr.ErrJSONTypeMismatch)
+			}
+			// The overflow is found without materializing the integer
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+				t.Errorf("%s() allocated %d bytes, want under 1 MiB", name, allocated)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractRaw(t *testing.T) {
+	jsonData := `{
+		"obj": {"z": 1,  "a": [1.50, 2e3],"m": "\u00e9"},
+		"arr": [ {"b":2, "a":1} , 3 ],
+		"num": 1.0e+2,
+		"str": "tab\there \"quoted\"",
+		"yes": true,
+		"none": null
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     string
+	}{
+		{name: "object keeps key order and spacing", selector: "obj", want: `{"z": 1,  "a": [1.50, 2e3],"m": "\u00e9"}`},
+		{name: "array keeps spacing", selector: "arr", want: `[ {"b":2, "a":1} , 3 ]`},
+		{name: "nested object", selector: "arr.0", want: `{"b":2, "a":1}`},
+		{name: "negative index", selector: "arr.-2", want: `{"b":2, "a":1}`},
+		{name: "number formatting", selector: "num", want: `1.0e+2`},
+		{name: "trailing zeros", selector: "obj.a.0", want: `1.50`},
+		{name: "string escapes", selector: "str", want: `"tab\there \"quoted\""`},
+		{name: "unicode escape", selector: "obj.m", want: `"\u00e9"`},
+		{name: "bool", selector: "yes", want: `true`},
+		{name: "null", selector: "none", want: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractRaw([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractRaw() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ExtractRaw() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractRaw_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "missing key", raw: `{"a": 1}`, selector: "b", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "empty body", raw: ``, selector: "a", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+		{name: "truncated value", raw: `{"a": {"b": 1`, selector: "a", wantErr: jsonxtractr.ErrJSONStreamingParseFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractRaw([]byte(tt.raw), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExtractRaw() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteValueTo(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice",  "scores": [1.50, 2e3]}, "count": 1.0e+2}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     string
+	}{
+		{name: "object subtree", selector: "user", want: `{"name": "Alice",  "scores": [1.50, 2e3]}`},
+		{name: "array", selector: "user.scores", want: `[1.50, 2e3]`},
+		{name: "scalar", selector: "count", want: `1.0e+2`},
+		{name: "string", selector: "user.name", want: `"Alice"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := jsonxtractr.WriteValueTo(&buf, jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("WriteValueTo() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteValueTo() wrote %s, want %s", buf.String(), tt.want)
+			}
+			if n != len(tt.want) {
+				t.Errorf("WriteValueTo() = %d, want %d", n, len(tt.want))
+			}
+		})
+	}
+}
+
+// failingWriter accepts up to limit bytes and then fails.
+type failingWriter struct {
+	limit int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return w.limit, errWriteFailed
+	}
+	return len(p), nil
+}
+
+func TestWriteValueTo_Errors(t *testing.T) {
+	jsonData := []byte(`{"a": {"b": 1}}`)
+
+	var buf bytes.Buffer
+	n, err := jsonxtractr.WriteValueTo(&buf, jsonData, "a.c")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("WriteValueTo() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	if n != 0 || buf.Len() != 0 {
+		t.Errorf("WriteValueTo() wrote %q (%d), want nothing", buf.String(), n)
+	}
+
+	n, err = jsonxtractr.WriteValueTo(&failingWriter{limit: 3}, jsonData, "a")
+	if !errors.Is(err, jsonxtractr.ErrJSONWriteFailed) {
+		t.Errorf("WriteValueTo() error = %v, want %v", err, jsonxtractr.ErrJSONWriteFailed)
+	}
+	if !errors.Is(err, errWriteFailed) {
+		t.Errorf("WriteValueTo() error = %v, want %v", err, errWriteFailed)
+	}
+	if n != 3 {
+		t.Errorf("WriteValueTo() = %d, want 3", n)
+	}
+}
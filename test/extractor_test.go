@@ -0,0 +1,90 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractor(t *testing.T) {
+	jsonData := []byte(`{"price": 1.50, "items": [{"id": 10}, {"id": 20}]}`)
+	x := jsonxtractr.NewExtractor(jsonxtractr.WithNumbersAsString())
+
+	value, err := x.Value(jsonData, "price")
+	if err != nil || value != "1.50" {
+		t.Errorf("Value() = %#v, %v, want \"1.50\"", value, err)
+	}
+
+	values, _, err := x.Values(jsonData, []jsonxtractr.Selector{"price", "items.1.id"})
+	want := jsonxtractr.ValuesMap{"price": "1.50", "items.1.id": "20"}
+	if err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("Values() = %#v, %v, want %#v", values, err, want)
+	}
+
+	value, err = x.ValueFromReader(strings.NewReader(string(jsonData)), "items.0.id")
+	if err != nil || value != "10" {
+		t.Errorf("ValueFromReader() = %#v, %v, want \"10\"", value, err)
+	}
+
+	values, _, err = x.ValuesFromReader(strings.NewReader(string(jsonData)), []jsonxtractr.Selector{"price"})
+	if err != nil || values["price"] != "1.50" {
+		t.Errorf("ValuesFromReader() = %#v, %v, want price \"1.50\"", values, err)
+	}
+
+	matches, err := x.Matches(jsonData, "items.*.id")
+	wantMatches := []jsonxtractr.Match{{Path: "items.0.id", Value: "10"}, {Path: "items.1.id", Value: "20"}}
+	if err != nil || !reflect.DeepEqual(matches, wantMatches) {
+		t.Errorf("Matches() = %#v, %v, want %#v", matches, err, wantMatches)
+	}
+
+	_, err = x.Value(jsonData, "missing")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("Value() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+}
+
+func TestExtractor_ZeroValue(t *testing.T) {
+	var x jsonxtractr.Extractor
+
+	value, err := x.Value([]byte(`{"n": 1.50}`), "n")
+	if err != nil || value != 1.5 {
+		t.Errorf("Value() = %#v, %v, want 1.5", value, err)
+	}
+}
+
+func TestExtractor_OptionsNotShared(t *testing.T) {
+	opts := []jsonxtractr.Option{jsonxtractr.WithNumbersAsString()}
+	x := jsonxtractr.NewExtractor(opts...)
+
+	// Changing the caller's slice afterwards doesn't change the Extractor
+	opts[0] = jsonxtractr.WithSmartNumbers()
+	value, err := x.Value([]byte(`{"n": 2}`), "n")
+	if err != nil || value != "2" {
+		t.Errorf("Value() = %#v, %v, want \"2\"", value, err)
+	}
+}
+
+func TestExtractor_Concurrent(t *testing.T) {
+	jsonData := []byte(`{"a": 1.0, "b": [2, 3]}`)
+	x := jsonxtractr.NewExtractor(jsonxtractr.WithNumbersAsString())
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				values, _, err := x.Values(jsonData, []jsonxtractr.Selector{"a", "b.1"})
+				if err != nil || values["a"] != "1.0" || values["b.1"] != "3" {
+					t.Errorf("Values() = %#v, %v, want a \"1.0\" and b.1 \"3\"", values, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
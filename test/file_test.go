@@ -0,0 +1,79 @@
+package test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func writeJSONFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.json")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestExtractValueFromFile(t *testing.T) {
+	path := writeJSONFile(t, `{"server": {"host": "localhost", "port": 8080}}`)
+
+	got, err := jsonxtractr.ExtractValueFromFile(path, "server.port")
+	if err != nil {
+		t.Fatalf("ExtractValueFromFile() error = %v", err)
+	}
+	if got != float64(8080) {
+		t.Errorf("ExtractValueFromFile() = %v, want 8080", got)
+	}
+
+	_, err = jsonxtractr.ExtractValueFromFile(path, "server.user")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValueFromFile() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	if !strings.Contains(err.Error(), path) {
+		t.Errorf("ExtractValueFromFile() error = %v, want the path in context", err)
+	}
+}
+
+func TestExtractValuesFromFile(t *testing.T) {
+	path := writeJSONFile(t, `{"server": {"host": "localhost", "port": 8080}}`)
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromFile(path, []jsonxtractr.Selector{"server.host", "server.port", "server.user"})
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValuesFromFile() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	want := jsonxtractr.ValuesMap{"server.host": "localhost", "server.port": float64(8080)}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromFile() values = %v, want %v", values, want)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"server.user"}) {
+		t.Errorf("ExtractValuesFromFile() notFound = %v, want [server.user]", notFound)
+	}
+}
+
+func TestExtractFromFile_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+
+	_, err := jsonxtractr.ExtractValueFromFile(path, "a")
+	if !errors.Is(err, jsonxtractr.ErrJSONFileReadFailed) {
+		t.Errorf("ExtractValueFromFile() error = %v, want %v", err, jsonxtractr.ErrJSONFileReadFailed)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ExtractValueFromFile() error = %v, want %v", err, fs.ErrNotExist)
+	}
+	if path, ok := jsonxtractr.ErrValue[string](err, "path"); !ok || !strings.HasSuffix(path, "missing.json") {
+		t.Errorf("ExtractValueFromFile() error path = %q, want the file's path", path)
+	}
+
+	_, _, err = jsonxtractr.ExtractValuesFromFile(path, []jsonxtractr.Selector{"a", "b"})
+	if !errors.Is(err, jsonxtractr.ErrJSONFileReadFailed) {
+		t.Errorf("ExtractValuesFromFile() error = %v, want %v", err, jsonxtractr.ErrJSONFileReadFailed)
+	}
+}
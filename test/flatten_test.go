@@ -0,0 +1,116 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestFlatten(t *testing.T) {
+	jsonData := []byte(`{
+		"name": "shop",
+		"items": [
+			{"name": "pen", "price": 1.5, "tags": ["blue", "cheap"]},
+			{"name": "ink", "price": null, "tags": []}
+		],
+		"meta": {"open": true, "hours": {"mon": "9-5"}, "empty": {}},
+		"odd keys": {"a.b": 1, "0": 2, "*": 3}
+	}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     map[jsonxtractr.Selector]any
+	}{
+		{
+			name:     "whole document",
+			selector: jsonxtractr.RootSelector,
+			want: map[jsonxtractr.Selector]any{
+				"name":           "shop",
+				"items.0.name":   "pen",
+				"items.0.price":  1.5,
+				"items.0.tags.0": "blue",
+				"items.0.tags.1": "cheap",
+				"items.1.name":   "ink",
+				"items.1.price":  nil,
+				"meta.open":      true,
+				"meta.hours.mon": "9-5",
+				`odd keys."a.b"`: float64(1),
+				`odd keys."0"`:   float64(2),
+				`odd keys."*"`:   float64(3),
+			},
+		},
+		{
+			name:     "subtree",
+			selector: "items.0",
+			want: map[jsonxtractr.Selector]any{
+				"items.0.name":   "pen",
+				"items.0.price":  1.5,
+				"items.0.tags.0": "blue",
+				"items.0.tags.1": "cheap",
+			},
+		},
+		{
+			name:     "scalar",
+			selector: "meta.hours.mon",
+			want:     map[jsonxtractr.Selector]any{"meta.hours.mon": "9-5"},
+		},
+		{
+			name:     "empty container",
+			selector: "meta.empty",
+			want:     map[jsonxtractr.Selector]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Flatten(jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("Flatten() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flatten() = %#v, want %#v", got, tt.want)
+			}
+
+			// Every key selects its leaf again
+			for selector, want := range got {
+				value, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+				if err != nil {
+					t.Errorf("ExtractValueFromBytes(%s) error = %v", selector, err)
+					continue
+				}
+				if !reflect.DeepEqual(value, want) {
+					t.Errorf("ExtractValueFromBytes(%s) = %v, want %v", selector, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFlatten_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "missing path", raw: `{"a": {"b": 1}}`, selector: "a.c", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "wildcard", raw: `{"a": [1, 2]}`, selector: "a.*", wantErr: jsonxtractr.ErrJSONSelectorMultiMatch},
+		{name: "malformed leaf", raw: `{"a": {"b": tru}}`, selector: "a", wantErr: jsonxtractr.ErrJSONStreamingParseFailed},
+		{name: "empty body", raw: ``, selector: "a", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Flatten([]byte(tt.raw), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Flatten() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("Flatten() = %v, want nil", got)
+			}
+		})
+	}
+}
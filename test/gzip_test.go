@@ -0,0 +1,81 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// gzipBytes returns data gzip-compressed.
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractValuesFromGzipReader(t *testing.T) {
+	compressed := gzipBytes(t, `{"user": {"name": "Alice", "tags": ["a", "b"]}, "count": 2}`)
+
+	selectors := []jsonxtractr.Selector{"user.name", "count", "missing"}
+	valuesMap, notFound, err := jsonxtractr.ExtractValuesFromGzipReader(bytes.NewReader(compressed), selectors)
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValuesFromGzipReader() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	want := jsonxtractr.ValuesMap{"user.name": "Alice", "count": float64(2)}
+	if !reflect.DeepEqual(valuesMap, want) {
+		t.Errorf("ExtractValuesFromGzipReader() = %v, want %v", valuesMap, want)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"missing"}) {
+		t.Errorf("ExtractValuesFromGzipReader() notFound = %v, want [missing]", notFound)
+	}
+
+	// A lone selector streams through the decompressor
+	valuesMap, _, err = jsonxtractr.ExtractValuesFromGzipReader(bytes.NewReader(compressed), []jsonxtractr.Selector{"user.tags.1"})
+	if err != nil || valuesMap["user.tags.1"] != "b" {
+		t.Errorf("ExtractValuesFromGzipReader() = %v, %v, want b", valuesMap, err)
+	}
+}
+
+func TestExtractValuesFromGzipReader_Errors(t *testing.T) {
+	compressed := gzipBytes(t, `{"a": 1, "b": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]}`)
+
+	corrupt := bytes.Clone(compressed)
+	corrupt[len(corrupt)-6] ^= 0xff // checksum
+
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr error
+	}{
+		{name: "not gzip", input: []byte(`{"a": 1, "b": 2}`), wantErr: jsonxtractr.ErrJSONGzipReadFailed},
+		{name: "truncated", input: compressed[:len(compressed)/2], wantErr: jsonxtractr.ErrJSONGzipReadFailed},
+		{name: "corrupt checksum", input: corrupt, wantErr: jsonxtractr.ErrJSONGzipReadFailed},
+		{name: "empty", input: nil, wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := jsonxtractr.ExtractValuesFromGzipReader(bytes.NewReader(tt.input), []jsonxtractr.Selector{"a", "b"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExtractValuesFromGzipReader() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, _, err := jsonxtractr.ExtractValuesFromGzipReader(bytes.NewReader([]byte(`{"a": 1}`)), []jsonxtractr.Selector{"a"})
+	if reason, _ := jsonxtractr.ErrValue[string](err, "reason"); reason != "input is not gzip-compressed" {
+		t.Errorf("ExtractValuesFromGzipReader() reason = %q, want the missing magic bytes reported", reason)
+	}
+}
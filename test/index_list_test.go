@@ -0,0 +1,138 @@
+package test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractValue_IndexList(t *testing.T) {
+	jsonData := []byte(`{
+		"scores": [10, 20, 30, 40, 50],
+		"users": [{"name": "a"}, {"name": "b"}],
+		"name": "not a list",
+		"[0]": "literal"
+	}`)
+
+	tests := []struct {
+		name      string
+		selector  jsonxtractr.Selector
+		want      any
+		wantErr   error
+		wantIndex int
+	}{
+		{name: "requested order", selector: "scores.[4,0,2]", want: []any{float64(50), float64(10), float64(30)}},
+		{name: "duplicate index", selector: "scores.[1,1,3]", want: []any{float64(20), float64(20), float64(40)}},
+		{name: "single index", selector: "scores.[3]", want: []any{float64(40)}},
+		{name: "negative indexes", selector: "scores.[-1, 0, -5]", want: []any{float64(50), float64(10), float64(10)}},
+		{name: "objects", selector: "users.[1,0]", want: []any{map[string]any{"name": "b"}, map[string]any{"name": "a"}}},
+		{name: "quoted is a key", selector: `"[0]"`, want: "literal"},
+		{name: "out of range", selector: "scores.[0,5,1]", wantErr: jsonxtractr.ErrJSONIndexOutOfRange, wantIndex: 5},
+		{name: "negative out of range", selector: "scores.[0,-6]", wantErr: jsonxtractr.ErrJSONIndexOutOfRange, wantIndex: -6},
+		{name: "MinInt out of range", selector: "scores.[0,-9223372036854775808]", wantErr: jsonxtractr.ErrJSONIndexOutOfRange, wantIndex: math.MinInt},
+		{name: "not an array", selector: "name.[0]", wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment},
+	}
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("ExtractValueFromBytes() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytes() = %#v, want %#v", got, tt.want)
+			}
+			if tt.wantIndex != 0 {
+				if idx, _ := jsonxtractr.ErrValue[int](err, "target_index"); idx != tt.wantIndex {
+					t.Errorf("target_index = %d, want %d in %v", idx, tt.wantIndex, err)
+				}
+				if length, _ := jsonxtractr.ErrValue[int](err, "array_length"); length != 5 {
+					t.Errorf("array_length = %d, want 5 in %v", length, err)
+				}
+			}
+
+			// The streaming, multi-selector and indexed paths agree
+			got, err = jsonxtractr.ExtractValueFromReader(strings.NewReader(string(jsonData)), tt.selector)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromReader() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+
+			valuesMap, _, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{tt.selector, "scores.0"})
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(valuesMap[tt.selector], tt.want) {
+				t.Errorf("ExtractValuesFromBytes() = %v, %v, want %v, %v", valuesMap[tt.selector], err, tt.want, tt.wantErr)
+			}
+			if valuesMap["scores.0"] != float64(10) {
+				t.Errorf("ExtractValuesFromBytes() scores.0 = %v, want 10", valuesMap["scores.0"])
+			}
+
+			got, err = doc.Value(tt.selector)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.Value() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractValue_IndexListStopsAtLastIndex(t *testing.T) {
+	// Everything after the highest index listed is left unread
+	jsonData := `{"xs": [1, 2, 3, @@@`
+
+	got, err := jsonxtractr.ExtractValueFromReader(strings.NewReader(jsonData), "xs.[2,0]")
+	if err != nil {
+		t.Fatalf("ExtractValueFromReader() error = %v", err)
+	}
+	if want := []any{float64(3), float64(1)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractValueFromReader() = %v, want %v", got, want)
+	}
+}
+
+func TestSelector_IndexListInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+	}{
+		{name: "not last", selector: "xs.[0,1].name"},
+		{name: "empty", selector: "xs.[]"},
+		{name: "not an integer", selector: "xs.[0,a]"},
+		{name: "empty entry", selector: "xs.[0,,1]"},
+		{name: "missing closing bracket", selector: "xs.[0,1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.selector.Validate()
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorIndexListInvalid) || !errors.Is(err, jsonxtractr.ErrJSONSelectorInvalid) {
+				t.Errorf("Validate() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorIndexListInvalid)
+			}
+
+			_, err = jsonxtractr.ExtractValueFromBytes([]byte(`{"xs": [1, 2]}`), tt.selector)
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorIndexListInvalid) {
+				t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorIndexListInvalid)
+			}
+		})
+	}
+}
+
+func TestSelector_IndexListRoundTrip(t *testing.T) {
+	selector := jsonxtractr.Selector("a.[ 2, -1,0 ]")
+
+	segments, err := selector.Segments()
+	if err != nil {
+		t.Fatalf("Segments() error = %v", err)
+	}
+	if want := []string{"a", "[2,-1,0]"}; !reflect.DeepEqual(segments, want) {
+		t.Errorf("Segments() = %q, want %q", segments, want)
+	}
+	if got := selector.Parent().Child("[0]"); got != `a."[0]"` {
+		t.Errorf("Child([0]) = %s, want %s", got, `a."[0]"`)
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestKeys(t *testing.T) {
+	jsonData := []byte(`{
+		"zeta": 1,
+		"user": {"name": "Alice", "address": {"city": "Paris", "zip": "75001"}, "age": 30},
+		"empty": {},
+		"alpha": [1, 2]
+	}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []string
+	}{
+		{name: "root keys keep document order", selector: jsonxtractr.RootSelector, want: []string{"zeta", "user", "empty", "alpha"}},
+		{name: "nested object", selector: "user", want: []string{"name", "address", "age"}},
+		{name: "deeply nested object", selector: "user.address", want: []string{"city", "zip"}},
+		{name: "empty object", selector: "empty", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Keys(jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("Keys() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Keys() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeys_Errors(t *testing.T) {
+	jsonData := []byte(`{"list": [1, 2], "name": "x", "obj": {"a": 1}}`)
+
+	tests := []struct {
+		name     string
+		raw      []byte
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "array is not an object", raw: jsonData, selector: "list", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "string is not an object", raw: jsonData, selector: "name", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "root array is not an object", raw: []byte(`[1]`), selector: jsonxtractr.RootSelector, wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "missing path", raw: jsonData, selector: "obj.b", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "truncated object", raw: []byte(`{"obj": {"a": 1, "b"`), selector: "obj", wantErr: jsonxtractr.ErrJSONTokenReadFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Keys(tt.raw, tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Keys() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("Keys() = %v, want nil on error", got)
+			}
+		})
+	}
+}
+
+func TestKeyAt(t *testing.T) {
+	jsonData := []byte(`{"order": {"zeta": 1, "alpha": {"deep": true}, "mid": [1, 2]}, "list": [1]}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		ordinal  int
+		want     string
+		wantErr  error
+	}{
+		{name: "first", selector: "order", ordinal: 0, want: "zeta"},
+		{name: "middle", selector: "order", ordinal: 1, want: "alpha"},
+		{name: "last", selector: "order", ordinal: 2, want: "mid"},
+		{name: "root", selector: jsonxtractr.RootSelector, ordinal: 1, want: "list"},
+		{name: "out of range", selector: "order", ordinal: 3, wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "negative", selector: "order", ordinal: -1, wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "not an object", selector: "list", ordinal: 0, wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "missing path", selector: "other", ordinal: 0, wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.KeyAt(jsonData, tt.selector, tt.ordinal)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("KeyAt() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("KeyAt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyAt_PairsWithOrdinalSegment(t *testing.T) {
+	jsonData := []byte(`{"m": {"b": 2, "a": 1, "c": 3}}`)
+
+	keys, err := jsonxtractr.Keys(jsonData, "m")
+	if err != nil {
+		t.Fatalf("Keys() error = %v", err)
+	}
+	for i, want := range keys {
+		key, err := jsonxtractr.KeyAt(jsonData, "m", i)
+		if err != nil || key != want {
+			t.Errorf("KeyAt(%d) = %q, %v, want %q", i, key, err, want)
+		}
+
+		byOrdinal, err := jsonxtractr.ExtractValueFromBytes(jsonData, jsonxtractr.Selector(fmt.Sprintf("m.#%d", i)))
+		if err != nil {
+			t.Fatalf("ExtractValueFromBytes(m.#%d) error = %v", i, err)
+		}
+		byKey, err := jsonxtractr.ExtractValueFromBytes(jsonData, jsonxtractr.Selector("m").Child(key))
+		if err != nil || byKey != byOrdinal {
+			t.Errorf("m.%s = %v, %v, want %v", key, byKey, err, byOrdinal)
+		}
+	}
+}
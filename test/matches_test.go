@@ -0,0 +1,774 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractMatches(t *testing.T) {
+	jsonData := `{
+		"users": [
+			{"name": "Alice", "roles": ["admin", "dev"]},
+			{"name": "Bob", "roles": []},
+			{"nickname": "Carol", "roles": ["ops"]}
+		],
+		"settings": {"theme": "dark", "lang": "en"},
+		"groups": {"a": {"ids": [1, 2]}, "b": {"ids": [3]}, "c": {"ids": "none"}},
+		"count": 3
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []jsonxtractr.Match
+	}{
+		{
+			name:     "wildcard over array",
+			selector: "users.*.name",
+			want: []jsonxtractr.Match{
+				{Path: "users.0.name", Value: "Alice"},
+				{Path: "users.1.name", Value: "Bob"},
+			},
+		},
+		{
+			name:     "wildcard over object",
+			selector: "settings.*",
+			want: []jsonxtractr.Match{
+				{Path: "settings.theme", Value: "dark"},
+				{Path: "settings.lang", Value: "en"},
+			},
+		},
+		{
+			name:     "nested wildcards over arrays",
+			selector: "users.*.roles.*",
+			want: []jsonxtractr.Match{
+				{Path: "users.0.roles.0", Value: "admin"},
+				{Path: "users.0.roles.1", Value: "dev"},
+				{Path: "users.2.roles.0", Value: "ops"},
+			},
+		},
+		{
+			name:     "mixed object and array wildcards",
+			selector: "groups.*.ids.*",
+			want: []jsonxtractr.Match{
+				{Path: "groups.a.ids.0", Value: float64(1)},
+				{Path: "groups.a.ids.1", Value: float64(2)},
+				{Path: "groups.b.ids.0", Value: float64(3)},
+			},
+		},
+		{
+			name:     "wildcard then index",
+			selector: "groups.*.ids.0",
+			want: []jsonxtractr.Match{
+				{Path: "groups.a.ids.0", Value: float64(1)},
+				{Path: "groups.b.ids.0", Value: float64(3)},
+			},
+		},
+		{
+			name:     "wildcard over scalar",
+			selector: "count.*",
+			want:     []jsonxtractr.Match{},
+		},
+		{
+			name:     "plain selector",
+			selector: "settings.theme",
+			want: []jsonxtractr.Match{
+				{Path: "settings.theme", Value: "dark"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractMatches([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractMatches() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractMatches() mismatch:\n  got:  %#v\n  want: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMatches_Glob(t *testing.T) {
+	jsonData := `{
+		"metrics": {"cpu_user": 12, "cpu_system": 3, "mem_used": 512, "gpu_user": 1, "cpu_": 0, "xcpu_user": 9},
+		"hosts": [
+			{"name": "web1", "tags": {"env_prod": true, "env_dev": false, "tier": "web"}},
+			{"name": "db1", "tags": {"env_prod": true}}
+		],
+		"codes": {"a1": 1, "b2": 2, "a12": 12, "ä1": 4},
+		"list": ["cpu_user", "cpu_system"]
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []jsonxtractr.Match
+	}{
+		{
+			name:     "prefix glob",
+			selector: "metrics.cpu_*",
+			want: []jsonxtractr.Match{
+				{Path: "metrics.cpu_user", Value: float64(12)},
+				{Path: "metrics.cpu_system", Value: float64(3)},
+				{Path: "metrics.cpu_", Value: float64(0)},
+			},
+		},
+		{
+			name:     "suffix glob",
+			selector: "metrics.*_user",
+			want: []jsonxtractr.Match{
+				{Path: "metrics.cpu_user", Value: float64(12)},
+				{Path: "metrics.gpu_user", Value: float64(1)},
+				{Path: "metrics.xcpu_user", Value: float64(9)},
+			},
+		},
+		{
+			name:     "single character glob",
+			selector: "codes.?1",
+			want: []jsonxtractr.Match{
+				{Path: "codes.a1", Value: float64(1)},
+				{Path: "codes.ä1", Value: float64(4)},
+			},
+		},
+		{
+			name:     "single character and prefix glob",
+			selector: "metrics.?pu_*r",
+			want: []jsonxtractr.Match{
+				{Path: "metrics.cpu_user", Value: float64(12)},
+				{Path: "metrics.gpu_user", Value: float64(1)},
+			},
+		},
+		{
+			name:     "glob below wildcard",
+			selector: "hosts.*.tags.env_*",
+			want: []jsonxtractr.Match{
+				{Path: "hosts.0.tags.env_prod", Value: true},
+				{Path: "hosts.0.tags.env_dev", Value: false},
+				{Path: "hosts.1.tags.env_prod", Value: true},
+			},
+		},
+		{
+			name:     "no match",
+			selector: "metrics.disk_*",
+			want:     []jsonxtractr.Match{},
+		},
+		{
+			name:     "anchored to the whole key",
+			selector: "metrics.pu_*",
+			want:     []jsonxtractr.Match{},
+		},
+		{
+			name:     "glob over array",
+			selector: "list.?",
+			want:     []jsonxtractr.Match{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractMatches([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractMatches() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractMatches() mismatch:\n  got:  %#v\n  want: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlob_LiteralKeys(t *testing.T) {
+	jsonData := []byte(`{"cpu_*": "literal", "cpu_user": 12}`)
+
+	for _, selector := range []jsonxtractr.Selector{`"cpu_*"`, `cpu_\*`} {
+		value, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+		if err != nil || value != "literal" {
+			t.Errorf("ExtractValueFromBytes(%s) = %v, %v, want literal", selector, value, err)
+		}
+	}
+
+	_, err := jsonxtractr.ExtractValueFromBytes(jsonData, "cpu_*")
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorMultiMatch) {
+		t.Errorf("ExtractValueFromBytes(cpu_*) error = %v, want ErrJSONSelectorMultiMatch", err)
+	}
+
+	if got := jsonxtractr.RootSelector.Child("cpu_*"); got != `"cpu_*"` {
+		t.Errorf("Child(cpu_*) = %s, want \"cpu_*\"", got)
+	}
+}
+
+func TestExtractMatches_Regex(t *testing.T) {
+	jsonData := `{
+		"items": {
+			"id_1": {"value": "one"},
+			"id_22": {"value": "two"},
+			"id_x": {"value": "x"},
+			"my_id_3": {"value": "three"},
+			"a.b/c": {"value": "slash"},
+			"axb/c": {"value": "unescaped dot"}
+		},
+		"list": [{"value": 1}]
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []jsonxtractr.Match
+	}{
+		{
+			name:     "anchored regex",
+			selector: `items./^id_\d+$/.value`,
+			want: []jsonxtractr.Match{
+				{Path: "items.id_1.value", Value: "one"},
+				{Path: "items.id_22.value", Value: "two"},
+			},
+		},
+		{
+			name:     "unanchored regex",
+			selector: `items./id_\d/.value`,
+			want: []jsonxtractr.Match{
+				{Path: "items.id_1.value", Value: "one"},
+				{Path: "items.id_22.value", Value: "two"},
+				{Path: "items.my_id_3.value", Value: "three"},
+			},
+		},
+		{
+			name:     "regex containing dots and slashes",
+			selector: `items./^a\.b\/c$/.value`,
+			want: []jsonxtractr.Match{
+				{Path: `items."a.b/c".value`, Value: "slash"},
+			},
+		},
+		{
+			name:     "no match",
+			selector: `items./^name_/.value`,
+			want:     []jsonxtractr.Match{},
+		},
+		{
+			name:     "regex over array",
+			selector: `list./0/.value`,
+			want:     []jsonxtractr.Match{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractMatches([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractMatches() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractMatches() mismatch:\n  got:  %#v\n  want: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMatches_RegexInvalid(t *testing.T) {
+	// An invalid regex is reported before any JSON is read, so malformed
+	// input doesn't mask it
+	err := jsonxtractr.ForEachMatch(strings.NewReader(`{"items": `), `items./id_(\d+/.value`, func(string, any) error { return nil })
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorRegexInvalid) {
+		t.Errorf("ForEachMatch() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorRegexInvalid)
+	}
+	if errors.Is(err, jsonxtractr.ErrJSONPathTraversalFailed) {
+		t.Errorf("ForEachMatch() error = %v, want a parse error before traversal", err)
+	}
+
+	_, err = jsonxtractr.ExtractMatches([]byte(`{"items": {}}`), `items./[a-/`)
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorRegexInvalid) {
+		t.Errorf("ExtractMatches() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorRegexInvalid)
+	}
+}
+
+func TestExtractMatches_Filter(t *testing.T) {
+	jsonData := `{
+		"users": [
+			{"name": "Alice", "role": "admin", "age": 34},
+			{"name": "Bob", "role": "dev", "age": 21},
+			{"name": "Carol", "role": "admin", "age": "unknown"},
+			{"name": "Dan", "age": 17},
+			"not an object",
+			{"name": "Eve", "role": "a.b)c", "age": 21.5}
+		],
+		"team": {"role": "admin"}
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []jsonxtractr.Match
+	}{
+		{
+			name:     "string equality",
+			selector: `users.#(role=="admin")#.name`,
+			want: []jsonxtractr.Match{
+				{Path: "users.0.name", Value: "Alice"},
+				{Path: "users.2.name", Value: "Carol"},
+			},
+		},
+		{
+			name:     "string inequality skips missing keys",
+			selector: `users.#(role != "admin")#.name`,
+			want: []jsonxtractr.Match{
+				{Path: "users.1.name", Value: "Bob"},
+				{Path: "users.5.name", Value: "Eve"},
+			},
+		},
+		{
+			name:     "literal containing dot and parenthesis",
+			selector: `users.#(role=="a.b)c")#.name`,
+			want: []jsonxtractr.Match{
+				{Path: "users.5.name", Value: "Eve"},
+			},
+		},
+		{
+			name:     "numeric comparison skips strings",
+			selector: `users.#(age>=21)#.name`,
+			want: []jsonxtractr.Match{
+				{Path: "users.0.name", Value: "Alice"},
+				{Path: "users.1.name", Value: "Bob"},
+				{Path: "users.5.name", Value: "Eve"},
+			},
+		},
+		{
+			name:     "numeric less than",
+			selector: `users.#(age<21)#`,
+			want: []jsonxtractr.Match{
+				{Path: "users.3", Value: map[string]any{"name": "Dan", "age": float64(17)}},
+			},
+		},
+		{
+			name:     "numeric equality",
+			selector: `users.#(age==21.5)#.name`,
+			want: []jsonxtractr.Match{
+				{Path: "users.5.name", Value: "Eve"},
+			},
+		},
+		{
+			name:     "string literal against numbers",
+			selector: `users.#(age>"20")#.name`,
+			want:     []jsonxtractr.Match{{Path: "users.2.name", Value: "Carol"}},
+		},
+		{
+			name:     "matches nothing",
+			selector: `users.#(role=="owner")#.name`,
+			want:     []jsonxtractr.Match{},
+		},
+		{
+			name:     "filter over object",
+			selector: `team.#(role=="admin")#`,
+			want:     []jsonxtractr.Match{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractMatches([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractMatches() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractMatches() mismatch:\n  got:  %#v\n  want: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMatches_FilterInvalid(t *testing.T) {
+	for _, selector := range []jsonxtractr.Selector{
+		`users.#(role=="admin"`,
+		`users.#(role)#`,
+		`users.#(=="admin")#`,
+		`users.#(role=admin)#`,
+		`users.#(role=="admin)#`,
+		`users.#(age>=abc)#`,
+		`users.#(age>1e999)#`,
+		`users.#(age>1)x`,
+		`users.#(age>1)#x`,
+	} {
+		t.Run(string(selector), func(t *testing.T) {
+			_, err := jsonxtractr.ExtractMatches([]byte(`{"users": []}`), selector)
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorFilterInvalid) {
+				t.Errorf("ExtractMatches() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorFilterInvalid)
+			}
+		})
+	}
+
+	// A filter can match several elements, so single-value extraction rejects it
+	_, err := jsonxtractr.ExtractValueFromBytes([]byte(`{"users": []}`), `users.#(age>1)#`)
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorMultiMatch) {
+		t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorMultiMatch)
+	}
+}
+
+func TestExtractValue_FirstMatchFilter(t *testing.T) {
+	jsonData := []byte(`{
+		"users": [
+			{"id": 1, "name": "Alice", "role": "dev"},
+			{"id": 3, "name": "Bob", "role": "admin"},
+			{"id": 3, "name": "Bob again", "role": "admin"},
+			{"id": 4, "role": "admin", "tags": ["x", "y"]}
+		],
+		"name": "not a list"
+	}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     any
+		wantErr  error
+	}{
+		{name: "match", selector: `users.#(role=="admin")`, want: map[string]any{"id": float64(3), "name": "Bob", "role": "admin"}},
+		{name: "chained key", selector: `users.#(id==3).name`, want: "Bob"},
+		{name: "chained index", selector: `users.#(id>3).tags.-1`, want: "y"},
+		{name: "no match", selector: `users.#(id==9).name`, wantErr: jsonxtractr.ErrJSONSelectorNotFound},
+		{name: "first match lacks key", selector: `users.#(id>=4).name`, wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "not an array", selector: `name.#(id==3)`, wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("ExtractValueFromBytes() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytes() = %v, want %v", got, tt.want)
+			}
+
+			// The streaming, multi-selector and indexed paths agree
+			got, err = jsonxtractr.ExtractValueFromReader(strings.NewReader(string(jsonData)), tt.selector)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromReader() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+
+			valuesMap, _, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{tt.selector, "users.0.id"})
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(valuesMap[tt.selector], tt.want) {
+				t.Errorf("ExtractValuesFromBytes() = %v, %v, want %v, %v", valuesMap[tt.selector], err, tt.want, tt.wantErr)
+			}
+			if valuesMap["users.0.id"] != float64(1) {
+				t.Errorf("ExtractValuesFromBytes() users.0.id = %v, want 1", valuesMap["users.0.id"])
+			}
+
+			doc, err := jsonxtractr.NewDocument(jsonData)
+			if err != nil {
+				t.Fatalf("NewDocument() error = %v", err)
+			}
+			got, err = doc.Value(tt.selector)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.Value() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractMatches_FirstMatchFilter(t *testing.T) {
+	jsonData := []byte(`{"groups": [
+		{"users": [{"id": 1, "name": "a"}, {"id": 3, "name": "b"}, {"id": 3, "name": "c"}]},
+		{"users": [{"id": 2, "name": "d"}]},
+		{"users": [{"id": 3, "name": "e"}]}
+	]}`)
+
+	got, err := jsonxtractr.ExtractMatches(jsonData, `groups.*.users.#(id==3).name`)
+	if err != nil {
+		t.Fatalf("ExtractMatches() error = %v", err)
+	}
+	want := []jsonxtractr.Match{
+		{Path: "groups.0.users.1.name", Value: "b"},
+		{Path: "groups.2.users.0.name", Value: "e"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractMatches() mismatch:\n  got:  %#v\n  want: %#v", got, want)
+	}
+}
+
+func TestExtractMatches_Order(t *testing.T) {
+	// Keys are deliberately out of sorted order, and some members lack "b" or
+	// hold a scalar there, so any sorting or map iteration shows up
+	jsonData := []byte(`{"a": {
+		"zeta": {"b": {"y": 1, "x": 2}},
+		"alpha": {"c": 0},
+		"mid": {"b": [3, 4, 5]},
+		"beta": {"b": 6},
+		"10": {"b": {"k": 7}},
+		"2": {"b": [8]}
+	}}`)
+
+	want := []jsonxtractr.Match{
+		{Path: "a.zeta.b.y", Value: float64(1)},
+		{Path: "a.zeta.b.x", Value: float64(2)},
+		{Path: "a.mid.b.0", Value: float64(3)},
+		{Path: "a.mid.b.1", Value: float64(4)},
+		{Path: "a.mid.b.2", Value: float64(5)},
+		{Path: `a."10".b.k`, Value: float64(7)},
+		{Path: `a."2".b.0`, Value: float64(8)},
+	}
+
+	// Repeated runs give the same order
+	for range 10 {
+		got, err := jsonxtractr.ExtractMatches(jsonData, "a.*.b.*")
+		if err != nil {
+			t.Fatalf("ExtractMatches() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("ExtractMatches() order:\n  got:  %v\n  want: %v", got, want)
+		}
+	}
+
+	var paths []string
+	err := jsonxtractr.ForEachMatch(bytes.NewReader(jsonData), "a.*.b.*", func(path string, value any) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachMatch() error = %v", err)
+	}
+	for i, match := range want {
+		if i >= len(paths) || paths[i] != string(match.Path) {
+			t.Fatalf("ForEachMatch() paths = %v, want the order of %v", paths, want)
+		}
+	}
+}
+
+func TestExtractMatches_RecursiveDescent(t *testing.T) {
+	jsonData := `{
+		"users": {
+			"email": "top@example.com",
+			"list": [
+				{"name": "Alice", "email": "alice@example.com"},
+				{"name": "email", "contact": {"email": "bob@example.com", "note": {"email": null}}}
+			]
+		},
+		"email": "outside@example.com"
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []jsonxtractr.Match
+	}{
+		{
+			name:     "key at several depths",
+			selector: "users..email",
+			want: []jsonxtractr.Match{
+				{Path: "users.email", Value: "top@example.com"},
+				{Path: "users.list.0.email", Value: "alice@example.com"},
+				{Path: "users.list.1.contact.email", Value: "bob@example.com"},
+				{Path: "users.list.1.contact.note.email", Value: nil},
+			},
+		},
+		{
+			name:     "from the root",
+			selector: "$..email",
+			want: []jsonxtractr.Match{
+				{Path: "users.email", Value: "top@example.com"},
+				{Path: "users.list.0.email", Value: "alice@example.com"},
+				{Path: "users.list.1.contact.email", Value: "bob@example.com"},
+				{Path: "users.list.1.contact.note.email", Value: nil},
+				{Path: "email", Value: "outside@example.com"},
+			},
+		},
+		{
+			name:     "followed by more segments",
+			selector: "..contact.note",
+			want: []jsonxtractr.Match{
+				{Path: "users.list.1.contact.note", Value: map[string]any{"email": nil}},
+			},
+		},
+		{
+			name:     "glob",
+			selector: "users.list..n*",
+			want: []jsonxtractr.Match{
+				{Path: "users.list.0.name", Value: "Alice"},
+				{Path: "users.list.1.name", Value: "email"},
+				{Path: "users.list.1.contact.note", Value: map[string]any{"email": nil}},
+			},
+		},
+		{
+			name:     "no match",
+			selector: "users..phone",
+			want:     []jsonxtractr.Match{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractMatches([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractMatches() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractMatches() = %v, want %v", got, tt.want)
+			}
+			for _, match := range got {
+				value, err := jsonxtractr.ExtractValueFromBytes([]byte(jsonData), match.Path)
+				if err != nil || !reflect.DeepEqual(value, match.Value) {
+					t.Errorf("ExtractValueFromBytes(%q) = %v, %v, want %v", match.Path, value, err, match.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractMatches_Errors(t *testing.T) {
+	jsonData := `{"users": [{"name": "Alice"}], "settings": {"theme": "dark"}}`
+
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+		want     error
+	}{
+		{"missing prefix", jsonData, "missing.*.name", jsonxtractr.ErrJSONPathSegmentNotFound},
+		{"empty segment below wildcard", jsonData, "users.*..", jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{"leading empty segment", jsonData, ".users", jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{"descent into filter", jsonData, `users..#(name=="Alice")`, jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{"empty selector", jsonData, "", jsonxtractr.ErrJSONValueSelectorCannotBeEmpty},
+		{"empty body", ``, "users.*", jsonxtractr.ErrJSONBodyCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractMatches([]byte(tt.raw), tt.selector)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ExtractMatches() error %v is not errors.Is(...) to %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValue_RejectsWildcard(t *testing.T) {
+	jsonData := []byte(`{"users": [{"name": "Alice"}, {"name": "Bob"}]}`)
+
+	_, err := jsonxtractr.ExtractValueFromBytes(jsonData, "users.*.name")
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorMultiMatch) {
+		t.Errorf("ExtractValueFromBytes() error %v is not errors.Is(...) to ErrJSONSelectorMultiMatch", err)
+	}
+
+	valuesMap, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{"users.0.name", "users.*.name"})
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorMultiMatch) {
+		t.Errorf("ExtractValuesFromBytes() error %v is not errors.Is(...) to ErrJSONSelectorMultiMatch", err)
+	}
+	if valuesMap["users.0.name"] != "Alice" {
+		t.Errorf("ExtractValuesFromBytes() got %#v for users.0.name, want %#v", valuesMap["users.0.name"], "Alice")
+	}
+	if len(notFound) != 0 {
+		t.Errorf("ExtractValuesFromBytes() notFound got %v, want none", notFound)
+	}
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() unexpected error: %v", err)
+	}
+	_, err = doc.Value("users.*.name")
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorMultiMatch) {
+		t.Errorf("Document.Value() error %v is not errors.Is(...) to ErrJSONSelectorMultiMatch", err)
+	}
+}
+
+func TestForEachMatch(t *testing.T) {
+	jsonData := `{"users": [{"name": "Alice"}, {"nickname": "Bob"}, {"name": "Carol"}], "meta": {"count": 3}}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []jsonxtractr.Match
+	}{
+		{
+			name:     "wildcard",
+			selector: "users.*.name",
+			want: []jsonxtractr.Match{
+				{Path: "users.0.name", Value: "Alice"},
+				{Path: "users.2.name", Value: "Carol"},
+			},
+		},
+		{
+			name:     "plain selector matches once",
+			selector: "meta.count",
+			want:     []jsonxtractr.Match{{Path: "meta.count", Value: float64(3)}},
+		},
+		{
+			name:     "zero matches",
+			selector: "users.*.email",
+			want:     nil,
+		},
+		{
+			name:     "wildcard over scalar",
+			selector: "meta.count.*",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []jsonxtractr.Match
+			err := jsonxtractr.ForEachMatch(strings.NewReader(jsonData), tt.selector, func(path string, value any) error {
+				got = append(got, jsonxtractr.Match{Path: jsonxtractr.Selector(path), Value: value})
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ForEachMatch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForEachMatch() matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForEachMatch_EarlyTermination(t *testing.T) {
+	errStop := errors.New("stop")
+	jsonData := `{"items": [1, 2, 3, 4, 5]}`
+
+	var seen []any
+	err := jsonxtractr.ForEachMatch(strings.NewReader(jsonData), "items.*", func(path string, value any) error {
+		seen = append(seen, value)
+		if len(seen) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("ForEachMatch() error = %v, want the callback's error", err)
+	}
+	if !reflect.DeepEqual(seen, []any{float64(1), float64(2)}) {
+		t.Errorf("ForEachMatch() visited %v, want [1 2]", seen)
+	}
+}
+
+func TestForEachMatch_StopsBeforeReadingRest(t *testing.T) {
+	errStop := errors.New("stop")
+
+	// The document is truncated after the first match, which is fine as long
+	// as the walk stops there
+	reader := strings.NewReader(`{"items": [{"id": 1}, {"id": 2`)
+	err := jsonxtractr.ForEachMatch(reader, "items.*.id", func(path string, value any) error {
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("ForEachMatch() error = %v, want the callback's error", err)
+	}
+}
+
+func TestForEachMatch_Errors(t *testing.T) {
+	noop := func(path string, value any) error { return nil }
+
+	err := jsonxtractr.ForEachMatch(strings.NewReader(`{"a": 1}`), "b.*", noop)
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ForEachMatch() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+
+	err = jsonxtractr.ForEachMatch(nil, "a", noop)
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) {
+		t.Errorf("ForEachMatch() error = %v, want %v", err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+}
@@ -0,0 +1,193 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestMultiError(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice", "tags": ["a"]}, "count": 3}`)
+	selectors := []jsonxtractr.Selector{"user.name", "user.tags.5", "user.email", "count.x", "user...name"}
+
+	wantReasons := map[jsonxtractr.Selector]error{
+		"user.tags.5": jsonxtractr.ErrJSONIndexOutOfRange,
+		"user.email":  jsonxtractr.ErrJSONPathSegmentNotFound,
+		"count.x":     jsonxtractr.ErrJSONPathExpectedObjectAtSegment,
+		"user...name": jsonxtractr.ErrJSONPathContainsEmptySegment,
+	}
+	wantFailed := []jsonxtractr.Selector{"user.tags.5", "user.email", "count.x", "user...name"}
+	wantNotFound := []jsonxtractr.Selector{"user.tags.5", "user.email", "count.x"}
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	extractors := map[string]func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error){
+		"bytes": func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error) {
+			return jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+		},
+		"reader": func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error) {
+			return jsonxtractr.ExtractValuesFromReader(strings.NewReader(string(jsonData)), selectors)
+		},
+		"document": func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error) {
+			return doc.Values(selectors)
+		},
+	}
+
+	for name, extract := range extractors {
+		t.Run(name, func(t *testing.T) {
+			valuesMap, notFound, err := extract()
+			if valuesMap["user.name"] != "Alice" {
+				t.Errorf("valuesMap = %v, want user.name extracted", valuesMap)
+			}
+			if !reflect.DeepEqual(notFound, wantNotFound) {
+				t.Errorf("notFound = %v, want %v", notFound, wantNotFound)
+			}
+
+			var multi *jsonxtractr.MultiError
+			if !errors.As(err, &multi) {
+				t.Fatalf("error = %T %v, want a *MultiError", err, err)
+			}
+			failures := multi.Failures()
+			if len(failures) != len(wantReasons) {
+				t.Errorf("Failures() = %v, want %d failures", failures, len(wantReasons))
+			}
+			for selector, want := range wantReasons {
+				if !errors.Is(failures[selector], want) {
+					t.Errorf("Failures()[%s] = %v, want %v", selector, failures[selector], want)
+				}
+				if !errors.Is(err, want) {
+					t.Errorf("errors.Is(err, %v) = false, want true", want)
+				}
+			}
+			if !reflect.DeepEqual(multi.Selectors(), wantFailed) {
+				t.Errorf("Selectors() = %v, want %v", multi.Selectors(), wantFailed)
+			}
+
+			// Only the selectors present but failing are reported by Failed
+			failed := multi.Failed()
+			if len(failed) != 1 || failed["user...name"] == nil {
+				t.Errorf("Failed() = %v, want user...name", failed)
+			}
+		})
+	}
+}
+
+func TestMultiError_LoneSelector(t *testing.T) {
+	jsonData := []byte(`{"a": [1, 2]}`)
+
+	// A lone failing selector is still reported through a MultiError
+	_, notFound, err := jsonxtractr.ExtractValuesFromReader(strings.NewReader(string(jsonData)), []jsonxtractr.Selector{"a.9"})
+	var multi *jsonxtractr.MultiError
+	if !errors.As(err, &multi) || !errors.Is(multi.Failures()["a.9"], jsonxtractr.ErrJSONIndexOutOfRange) {
+		t.Errorf("ExtractValuesFromReader() error = %v, want a *MultiError with an index out of range", err)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"a.9"}) {
+		t.Errorf("ExtractValuesFromReader() notFound = %v, want [a.9]", notFound)
+	}
+
+	// The single-selector functions report the failure itself
+	_, err = jsonxtractr.ExtractValueFromBytes(jsonData, "a.9")
+	if errors.As(err, &multi) {
+		t.Errorf("ExtractValueFromBytes() error = %v, want no *MultiError", err)
+	}
+	if length, _ := jsonxtractr.ErrValue[int](err, "array_length"); length != 2 {
+		t.Errorf("array_length = %d, want 2", length)
+	}
+
+	// Success returns a nil error rather than an empty MultiError
+	_, _, err = jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{"a.0", "a.1"})
+	if err != nil {
+		t.Errorf("ExtractValuesFromBytes() error = %v, want nil", err)
+	}
+}
+
+func TestMultiError_AbsentAndFailedSeparated(t *testing.T) {
+	jsonData := []byte(`[{"ok": 1}, {"x": 1, "x": 2}]`)
+	selectors := []jsonxtractr.Selector{"0.ok", "0.missing", "1"}
+
+	extractors := map[string]func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error){
+		"bytes": func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error) {
+			return jsonxtractr.ExtractValuesFromBytesOpts(jsonData, selectors, jsonxtractr.WithRejectDuplicateKeys())
+		},
+		"reader": func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error) {
+			return jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(string(jsonData)), selectors, jsonxtractr.WithRejectDuplicateKeys())
+		},
+	}
+
+	for name, extract := range extractors {
+		t.Run(name, func(t *testing.T) {
+			valuesMap, notFound, err := extract()
+			if !reflect.DeepEqual(valuesMap, jsonxtractr.ValuesMap{"0.ok": float64(1)}) {
+				t.Errorf("valuesMap = %v, want map[0.ok:1]", valuesMap)
+			}
+
+			// Only the absent path is not found
+			if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"0.missing"}) {
+				t.Errorf("notFound = %v, want [0.missing]", notFound)
+			}
+
+			var multi *jsonxtractr.MultiError
+			if !errors.As(err, &multi) {
+				t.Fatalf("error = %T %v, want a *MultiError", err, err)
+			}
+			failed := multi.Failed()
+			if len(failed) != 1 || !errors.Is(failed["1"], jsonxtractr.ErrJSONUnmarshalFailed) {
+				t.Errorf("Failed() = %v, want 1 failing with %v", failed, jsonxtractr.ErrJSONUnmarshalFailed)
+			}
+			if !errors.Is(multi.Failures()["0.missing"], jsonxtractr.ErrJSONPathSegmentNotFound) {
+				t.Errorf("Failures()[0.missing] = %v, want %v", multi.Failures()["0.missing"], jsonxtractr.ErrJSONPathSegmentNotFound)
+			}
+		})
+	}
+}
+
+func TestMultiError_WrongTypeIsAbsent(t *testing.T) {
+	jsonData := []byte(`{"count": 3, "tags": ["a"]}`)
+	selectors := []jsonxtractr.Selector{"count.x", "tags.name", "count"}
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	extractors := map[string]func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error){
+		"bytes": func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error) {
+			return jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+		},
+		"document": func() (jsonxtractr.ValuesMap, []jsonxtractr.Selector, error) {
+			return doc.Values(selectors)
+		},
+	}
+
+	for name, extract := range extractors {
+		t.Run(name, func(t *testing.T) {
+			_, notFound, err := extract()
+
+			// A path through a value of the wrong type is absent, as Exists
+			// reports it
+			for _, selector := range selectors[:2] {
+				exists, existsErr := jsonxtractr.Exists(jsonData, selector)
+				if exists || existsErr != nil {
+					t.Errorf("Exists(%s) = %v, %v, want false, nil", selector, exists, existsErr)
+				}
+			}
+			if !reflect.DeepEqual(notFound, selectors[:2]) {
+				t.Errorf("notFound = %v, want %v", notFound, selectors[:2])
+			}
+
+			var multi *jsonxtractr.MultiError
+			if !errors.As(err, &multi) {
+				t.Fatalf("error = %T %v, want a *MultiError", err, err)
+			}
+			if failed := multi.Failed(); len(failed) != 0 {
+				t.Errorf("Failed() = %v, want none", failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// recoverErr runs fn and returns the error it panicked with, if any.
+func recoverErr(t *testing.T, fn func()) (err error) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		var ok bool
+		err, ok = r.(error)
+		if !ok {
+			t.Fatalf("panic value = %#v, want an error", r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+func TestMustExtractValueFromBytes(t *testing.T) {
+	jsonData := []byte(`{"user": {"tags": ["a", "b"]}}`)
+
+	got := jsonxtractr.MustExtractValueFromBytes(jsonData, "user.tags")
+	if !reflect.DeepEqual(got, []any{"a", "b"}) {
+		t.Errorf("MustExtractValueFromBytes() = %v, want [a b]", got)
+	}
+
+	err := recoverErr(t, func() {
+		jsonxtractr.MustExtractValueFromBytes(jsonData, "user.name")
+	})
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("MustExtractValueFromBytes() panicked with %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+}
+
+func TestMustExtractAs(t *testing.T) {
+	jsonData := []byte(`{"port": 8080, "host": "localhost"}`)
+
+	if got := jsonxtractr.MustExtractAs[int](jsonData, "port"); got != 8080 {
+		t.Errorf("MustExtractAs[int]() = %d, want 8080", got)
+	}
+
+	err := recoverErr(t, func() {
+		jsonxtractr.MustExtractAs[int](jsonData, "host")
+	})
+	if !errors.Is(err, jsonxtractr.ErrJSONTypeMismatch) {
+		t.Errorf("MustExtractAs[int]() panicked with %v, want %v", err, jsonxtractr.ErrJSONTypeMismatch)
+	}
+
+	err = recoverErr(t, func() {
+		jsonxtractr.MustExtractAs[string](nil, "host")
+	})
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) {
+		t.Errorf("MustExtractAs[string]() panicked with %v, want %v", err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+}
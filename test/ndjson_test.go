@@ -0,0 +1,96 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractValuesFromNDJSON(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"level": "info", "msg": "started"}`,
+		`{"level": "warn"}`,
+		``,
+		`   `,
+		`{"level": "error", "msg": "failed"}`,
+		`{"level": "info", "msg": `,
+		`[1, 2]`,
+		`{"msg": "a"} {"msg": "b"}`,
+		`{"msg": null}`,
+	}, "\n") + "\n"
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromNDJSON(strings.NewReader(stream), "msg")
+
+	wantValues := []any{"started", nil, nil, nil, "failed", nil, nil, nil, nil}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("ExtractValuesFromNDJSON() values = %#v, want %#v", values, wantValues)
+	}
+	if !reflect.DeepEqual(notFound, []int{1, 6}) {
+		t.Errorf("ExtractValuesFromNDJSON() notFound = %v, want [1 6]", notFound)
+	}
+
+	if !errors.Is(err, jsonxtractr.ErrJSONNDJSONLineFailed) {
+		t.Fatalf("ExtractValuesFromNDJSON() error = %v, want %v", err, jsonxtractr.ErrJSONNDJSONLineFailed)
+	}
+	var lines []int
+	for _, lineErr := range err.(interface{ Unwrap() []error }).Unwrap() {
+		line, ok := jsonxtractr.ErrValue[int](lineErr, "line")
+		if ok {
+			lines = append(lines, line)
+		}
+	}
+	if !reflect.DeepEqual(lines, []int{6, 8}) {
+		t.Errorf("ExtractValuesFromNDJSON() failed lines = %v, want [6 8]", lines)
+	}
+}
+
+func TestExtractValuesFromNDJSON_NoTrailingNewline(t *testing.T) {
+	values, notFound, err := jsonxtractr.ExtractValuesFromNDJSON(strings.NewReader("{\"a\": 1}\r\n{\"a\": 2}"), "a")
+	if err != nil {
+		t.Fatalf("ExtractValuesFromNDJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(values, []any{float64(1), float64(2)}) {
+		t.Errorf("ExtractValuesFromNDJSON() values = %v, want [1 2]", values)
+	}
+	if len(notFound) != 0 {
+		t.Errorf("ExtractValuesFromNDJSON() notFound = %v, want none", notFound)
+	}
+}
+
+func TestExtractValuesFromNDJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *strings.Reader
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "empty selector", reader: strings.NewReader(`{"a": 1}`), selector: "", wantErr: jsonxtractr.ErrJSONValueSelectorCannotBeEmpty},
+		{name: "empty segment", reader: strings.NewReader(`{"a": 1}`), selector: "a...b", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{name: "wildcard", reader: strings.NewReader(`{"a": 1}`), selector: "*", wantErr: jsonxtractr.ErrJSONSelectorMultiMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _, err := jsonxtractr.ExtractValuesFromNDJSON(tt.reader, tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExtractValuesFromNDJSON() error = %v, want %v", err, tt.wantErr)
+			}
+			if values != nil {
+				t.Errorf("ExtractValuesFromNDJSON() values = %v, want nil", values)
+			}
+		})
+	}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromNDJSON(strings.NewReader(""), "a")
+	if err != nil || len(values) != 0 || len(notFound) != 0 {
+		t.Errorf("ExtractValuesFromNDJSON() = %v, %v, %v, want an empty result", values, notFound, err)
+	}
+
+	_, _, err = jsonxtractr.ExtractValuesFromNDJSON(&errAfterReader{data: strings.NewReader("{\"a\": 1}\n{")}, "a")
+	if !errors.Is(err, jsonxtractr.ErrJSONReadFailed) {
+		t.Errorf("ExtractValuesFromNDJSON() error = %v, want %v", err, jsonxtractr.ErrJSONReadFailed)
+	}
+}
@@ -0,0 +1,1160 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractValueFromBytesOpts_NumbersAsString(t *testing.T) {
+	jsonData := []byte(`{"price": 1.50, "id": 12345678901234567890, "nested": {"n": [1e3, "x", true]}}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{name: "keeps trailing zeros", selector: "price", want: "1.50"},
+		{name: "keeps precision", selector: "id", want: "12345678901234567890"},
+		{name: "keeps exponent", selector: "nested.n.0", want: "1e3"},
+		{name: "applies within containers", selector: "nested", want: map[string]any{"n": []any{"1e3", "x", true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, tt.selector, jsonxtractr.WithNumbersAsString())
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytesOpts() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValueFromBytesOpts_CaseInsensitiveKeys(t *testing.T) {
+	jsonData := []byte(`{"User": {"UserName": "alice", "EMAIL": "a@example.com"}}`)
+
+	got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, "user.userName", jsonxtractr.WithCaseInsensitiveKeys())
+	if err != nil {
+		t.Fatalf("ExtractValueFromBytesOpts() error = %v", err)
+	}
+	if got != "alice" {
+		t.Errorf("ExtractValueFromBytesOpts() = %v, want alice", got)
+	}
+
+	// Matching stays exact by default
+	_, err = jsonxtractr.ExtractValueFromBytes(jsonData, "user.userName")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+}
+
+func TestExtractValuesFromBytesOpts_CombinedOptions(t *testing.T) {
+	jsonData := []byte(`{"Order": {"Total": 19.90, "Items": [{"SKU": "a1", "Qty": 2}]}, "order": {"total": 0}}`)
+	selectors := []jsonxtractr.Selector{"order.total", "ORDER.items.0.qty", "order.items.0.sku", "order.missing"}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, selectors,
+		jsonxtractr.WithNumbersAsString(),
+		jsonxtractr.WithCaseInsensitiveKeys(),
+	)
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+
+	// "Order" comes first in the document, so it wins over "order"
+	want := jsonxtractr.ValuesMap{
+		"order.total":       "19.90",
+		"ORDER.items.0.qty": "2",
+		"order.items.0.sku": "a1",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromBytesOpts() values = %#v, want %#v", values, want)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"order.missing"}) {
+		t.Errorf("ExtractValuesFromBytesOpts() notFound = %v, want [order.missing]", notFound)
+	}
+}
+
+func TestExtractValuesFromBytesOpts_NoOptionsMatchesDefaults(t *testing.T) {
+	jsonData := []byte(`{"a": 1.5, "B": "x"}`)
+	selectors := []jsonxtractr.Selector{"a", "b"}
+
+	withOpts, notFoundOpts, errOpts := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, selectors)
+	defaults, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+
+	if !reflect.DeepEqual(withOpts, defaults) || !reflect.DeepEqual(notFoundOpts, notFound) {
+		t.Errorf("ExtractValuesFromBytesOpts() = %v, %v, want %v, %v", withOpts, notFoundOpts, defaults, notFound)
+	}
+	if (errOpts == nil) != (err == nil) {
+		t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", errOpts, err)
+	}
+}
+
+func TestExtractValueFromBytesOpts_AmbiguousCaseInsensitiveKeys(t *testing.T) {
+	jsonData := []byte(`{"user": {"Name": "first", "age": 30, "NAME": "second"}, "id": 7}`)
+
+	// The first key in document order wins by default
+	got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, "user.name", jsonxtractr.WithCaseInsensitiveKeys())
+	if err != nil {
+		t.Fatalf("ExtractValueFromBytesOpts() error = %v", err)
+	}
+	if got != "first" {
+		t.Errorf("ExtractValueFromBytesOpts() = %v, want first", got)
+	}
+
+	// Strict mode reports the ambiguity along with the matching keys
+	_, err = jsonxtractr.ExtractValueFromBytesOpts(jsonData, "user.name", jsonxtractr.WithStrictCaseInsensitiveKeys())
+	if !errors.Is(err, jsonxtractr.ErrJSONAmbiguousKey) {
+		t.Fatalf("ExtractValueFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONAmbiguousKey)
+	}
+	if !strings.Contains(err.Error(), "matching_keys=[Name NAME]") {
+		t.Errorf("ExtractValueFromBytesOpts() error = %v, want matching_keys in context", err)
+	}
+
+	// Unambiguous keys still resolve in strict mode, including below a
+	// fully scanned object
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(jsonData,
+		[]jsonxtractr.Selector{"USER.AGE", "ID", "user.name"},
+		jsonxtractr.WithStrictCaseInsensitiveKeys(),
+	)
+	if !errors.Is(err, jsonxtractr.ErrJSONAmbiguousKey) {
+		t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONAmbiguousKey)
+	}
+	want := jsonxtractr.ValuesMap{"USER.AGE": float64(30), "ID": float64(7)}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromBytesOpts() values = %v, want %v", values, want)
+	}
+	if len(notFound) != 0 {
+		t.Errorf("ExtractValuesFromBytesOpts() notFound = %v, want none", notFound)
+	}
+}
+
+func TestExtractValueFromBytesOpts_DuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		raw           string
+		selector      jsonxtractr.Selector
+		wantPermitted any
+		wantKey       string
+	}{
+		{name: "selected key duplicated", raw: `{"a":1,"a":2}`, selector: "a", wantPermitted: float64(1), wantKey: "a"},
+		{name: "duplicate before selected key", raw: `{"a":1,"a":2,"b":3}`, selector: "b", wantPermitted: float64(3), wantKey: "a"},
+		{name: "duplicate after selected key", raw: `{"b":3,"a":1,"a":2}`, selector: "b", wantPermitted: float64(3), wantKey: "a"},
+		{name: "duplicate within selected value", raw: `{"x":{"k":1,"k":2}}`, selector: "x", wantPermitted: map[string]any{"k": float64(2)}, wantKey: "k"},
+		{name: "duplicate in parent object", raw: `{"p":{"c":1},"p":{"c":2}}`, selector: "p.c", wantPermitted: float64(1), wantKey: "p"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes([]byte(tt.raw), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantPermitted) {
+				t.Errorf("ExtractValueFromBytes() = %v, want %v", got, tt.wantPermitted)
+			}
+
+			_, err = jsonxtractr.ExtractValueFromBytesOpts([]byte(tt.raw), tt.selector, jsonxtractr.WithRejectDuplicateKeys())
+			if !errors.Is(err, jsonxtractr.ErrJSONDuplicateKey) {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONDuplicateKey)
+			}
+			if !strings.Contains(err.Error(), "duplicate_key="+tt.wantKey) {
+				t.Errorf("ExtractValueFromBytesOpts() error = %v, want duplicate_key=%s in context", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestExtractValuesFromBytesOpts_RejectDuplicateKeys(t *testing.T) {
+	jsonData := []byte(`[{"v": 1}, {"v": 1, "v": 2}]`)
+	selectors := []jsonxtractr.Selector{"0.v", "1.v"}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, selectors, jsonxtractr.WithRejectDuplicateKeys())
+	if !errors.Is(err, jsonxtractr.ErrJSONDuplicateKey) {
+		t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONDuplicateKey)
+	}
+	if !reflect.DeepEqual(values, jsonxtractr.ValuesMap{"0.v": float64(1)}) {
+		t.Errorf("ExtractValuesFromBytesOpts() values = %v, want map[0.v:1]", values)
+	}
+	if len(notFound) != 0 {
+		t.Errorf("ExtractValuesFromBytesOpts() notFound = %v, want none", notFound)
+	}
+
+	// Permissive by default, where the first duplicate wins
+	values, _, err = jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+	if err != nil {
+		t.Fatalf("ExtractValuesFromBytes() error = %v", err)
+	}
+	if !reflect.DeepEqual(values, jsonxtractr.ValuesMap{"0.v": float64(1), "1.v": float64(1)}) {
+		t.Errorf("ExtractValuesFromBytes() values = %v", values)
+	}
+}
+
+func TestExtractValuesFromBytesOpts_Concurrency(t *testing.T) {
+	doc, records := largeDocument(64 << 10)
+	selectors := spreadSelectors(records, 200)
+	for i := range 10 {
+		selectors = append(selectors, jsonxtractr.Selector(fmt.Sprintf("missing%d.name", i)))
+	}
+	// Interleave the missing selectors so they land in different shares
+	slices.Reverse(selectors[len(selectors)/2:])
+
+	wantValues, wantNotFound, wantErr := jsonxtractr.ExtractValuesFromBytes(doc, selectors)
+	if !errors.Is(wantErr, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Fatalf("ExtractValuesFromBytes() error = %v, want %v", wantErr, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+
+	for _, n := range []int{-1, 0, 1, 2, 4, 8, 1000} {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			values, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(doc, selectors, jsonxtractr.WithConcurrency(n))
+			if !reflect.DeepEqual(values, wantValues) {
+				t.Errorf("ExtractValuesFromBytesOpts() values differ from serial extraction")
+			}
+			if !reflect.DeepEqual(notFound, wantNotFound) {
+				t.Errorf("ExtractValuesFromBytesOpts() notFound = %v, want %v", notFound, wantNotFound)
+			}
+			if err == nil || err.Error() != wantErr.Error() {
+				t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", err, wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractValuesFromReaderOpts_Concurrency(t *testing.T) {
+	jsonData := `{"a": 1, "b": {"c": [true, "x"]}, "d": null}`
+	selectors := []jsonxtractr.Selector{"d", "b.c.1", "a", "e", "b.c.0"}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(jsonData), selectors, jsonxtractr.WithConcurrency(3))
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValuesFromReaderOpts() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	want := jsonxtractr.ValuesMap{"d": nil, "b.c.1": "x", "a": float64(1), "b.c.0": true}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromReaderOpts() values = %v, want %v", values, want)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"e"}) {
+		t.Errorf("ExtractValuesFromReaderOpts() notFound = %v, want [e]", notFound)
+	}
+}
+
+func TestWithMaxInputBytes(t *testing.T) {
+	body := `{"first": 1, "padding": "` + strings.Repeat("x", 200) + `", "last": 2}`
+	limit := jsonxtractr.WithMaxInputBytes(64)
+
+	t.Run("bytes over the limit", func(t *testing.T) {
+		_, err := jsonxtractr.ExtractValueFromBytesOpts([]byte(body), "first", limit)
+		if !errors.Is(err, jsonxtractr.ErrJSONInputTooLarge) {
+			t.Errorf("ExtractValueFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONInputTooLarge)
+		}
+		if n, ok := jsonxtractr.ErrValue[int64](err, "max_input_bytes"); !ok || n != 64 {
+			t.Errorf("ExtractValueFromBytesOpts() max_input_bytes = %v, want 64", n)
+		}
+	})
+
+	t.Run("reader over the limit", func(t *testing.T) {
+		_, _, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(body), []jsonxtractr.Selector{"first", "last"}, limit)
+		if !errors.Is(err, jsonxtractr.ErrJSONInputTooLarge) {
+			t.Errorf("ExtractValuesFromReaderOpts() error = %v, want %v", err, jsonxtractr.ErrJSONInputTooLarge)
+		}
+	})
+
+	t.Run("streamed value past the limit", func(t *testing.T) {
+		values, _, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(body), []jsonxtractr.Selector{"last"}, limit)
+		if !errors.Is(err, jsonxtractr.ErrJSONInputTooLarge) {
+			t.Errorf("ExtractValuesFromReaderOpts() error = %v, want %v", err, jsonxtractr.ErrJSONInputTooLarge)
+		}
+		if len(values) != 0 {
+			t.Errorf("ExtractValuesFromReaderOpts() values = %v, want none from a truncated body", values)
+		}
+	})
+
+	t.Run("streamed value within the limit", func(t *testing.T) {
+		got, _, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(body), []jsonxtractr.Selector{"first"}, limit)
+		if err != nil {
+			t.Fatalf("ExtractValuesFromReaderOpts() error = %v", err)
+		}
+		if got["first"] != float64(1) {
+			t.Errorf("ExtractValuesFromReaderOpts() = %v, want first = 1", got)
+		}
+	})
+
+	t.Run("exactly at the limit", func(t *testing.T) {
+		exact := `{"a": "` + strings.Repeat("y", 56) + `"}`
+		values, _, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(exact), []jsonxtractr.Selector{"a", "b"}, jsonxtractr.WithMaxInputBytes(int64(len(exact))))
+		if errors.Is(err, jsonxtractr.ErrJSONInputTooLarge) {
+			t.Errorf("ExtractValuesFromReaderOpts() error = %v, want no size error", err)
+		}
+		if values["a"] != strings.Repeat("y", 56) {
+			t.Errorf("ExtractValuesFromReaderOpts() values = %v", values)
+		}
+	})
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		values, notFound, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(body), []jsonxtractr.Selector{"first", "last"})
+		if err != nil || len(notFound) > 0 || len(values) != 2 {
+			t.Errorf("ExtractValuesFromReaderOpts() = %v, %v, %v", values, notFound, err)
+		}
+	})
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("[", depth) + "1" + strings.Repeat("]", depth)
+	}
+	const limit = 5
+
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+		wantErr  bool
+	}{
+		{name: "root value at the limit", raw: nested(limit), selector: jsonxtractr.RootSelector},
+		{name: "root value over the limit", raw: nested(limit + 1), selector: jsonxtractr.RootSelector, wantErr: true},
+		{name: "path and value at the limit", raw: `{"a": {"b": ` + nested(limit-2) + `}}`, selector: "a.b"},
+		{name: "path and value over the limit", raw: `{"a": {"b": ` + nested(limit-1) + `}}`, selector: "a.b", wantErr: true},
+		{name: "path at the limit", raw: nested(limit), selector: "0.0.0.0.0"},
+		{name: "path over the limit", raw: nested(limit + 1), selector: "0.0.0.0.0.0", wantErr: true},
+		{name: "negative index under the limit", raw: `[[0, ` + nested(limit-2) + `]]`, selector: "0.-1"},
+		{name: "negative index over the limit", raw: `[[0, ` + nested(limit-1) + `]]`, selector: "0.-1", wantErr: true},
+		{name: "deep value not selected", raw: `{"deep": ` + nested(limit*2) + `, "flat": 1}`, selector: "flat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytesOpts([]byte(tt.raw), tt.selector, jsonxtractr.WithMaxDepth(limit))
+			if tt.wantErr != errors.Is(err, jsonxtractr.ErrJSONMaxDepthExceeded) {
+				t.Errorf("ExtractValueFromBytesOpts() error = %v, want max depth error: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("ExtractValueFromBytesOpts() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWithMaxDepth_Selectors(t *testing.T) {
+	raw := []byte(`{"shallow": [1], "deep": [[[[1]]]]}`)
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(raw, []jsonxtractr.Selector{"shallow", "deep"}, jsonxtractr.WithMaxDepth(3))
+	if !errors.Is(err, jsonxtractr.ErrJSONMaxDepthExceeded) {
+		t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONMaxDepthExceeded)
+	}
+	if !reflect.DeepEqual(values, jsonxtractr.ValuesMap{"shallow": []any{float64(1)}}) {
+		t.Errorf("ExtractValuesFromBytesOpts() values = %v", values)
+	}
+	if len(notFound) != 0 {
+		t.Errorf("ExtractValuesFromBytesOpts() notFound = %v, want none", notFound)
+	}
+
+	// Streaming a lone selector is held to the same limit
+	_, _, err = jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(string(raw)), []jsonxtractr.Selector{"deep.0"}, jsonxtractr.WithMaxDepth(3))
+	if !errors.Is(err, jsonxtractr.ErrJSONMaxDepthExceeded) {
+		t.Errorf("ExtractValuesFromReaderOpts() error = %v, want %v", err, jsonxtractr.ErrJSONMaxDepthExceeded)
+	}
+}
+
+func TestWithErrorJSONRedactKeys(t *testing.T) {
+	const secret = "s3cr3t-t0k3n"
+
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+	}{
+		{name: "string value", raw: `{"user": "alice", "password": "` + secret + `"}`, selector: "missing"},
+		{name: "nested key", raw: `{"auth": {"token": "` + secret + `", "kind": "bearer"}}`, selector: "auth.missing"},
+		{name: "object value", raw: `{"creds": {"key": "` + secret + `", "n": [1, 2]}, "a": 1}`, selector: "a.b"},
+		{name: "key inside an array", raw: `[{"token": "` + secret + `"}, {"token": "` + secret + `"}]`, selector: "5"},
+		{name: "malformed JSON", raw: `{"password": "` + secret + `", "a": }`, selector: "a"},
+		{name: "truncated value", raw: `{"a": [1, {"password": "` + secret, selector: "a.1.b"},
+		{name: "long document", raw: `{"padding": "` + strings.Repeat("x", 300) + `", "password": "` + secret + `"}`, selector: "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []jsonxtractr.Option{
+				jsonxtractr.WithErrorJSONRedactKeys("password", "token"),
+				jsonxtractr.WithErrorJSONRedactKeys("key"),
+				jsonxtractr.WithErrorJSONMaxLen(1000),
+			}
+
+			_, err := jsonxtractr.ExtractValueFromBytesOpts([]byte(tt.raw), tt.selector, opts...)
+			if err == nil {
+				t.Fatal("ExtractValueFromBytesOpts() error = nil, want an error")
+			}
+			if strings.Contains(err.Error(), secret) {
+				t.Errorf("ExtractValueFromBytesOpts() error leaks the secret: %v", err)
+			}
+
+			_, _, err = jsonxtractr.ExtractValuesFromBytesOpts([]byte(tt.raw), []jsonxtractr.Selector{tt.selector, "other"}, opts...)
+			if strings.Contains(err.Error(), secret) {
+				t.Errorf("ExtractValuesFromBytesOpts() error leaks the secret: %v", err)
+			}
+
+			_, _, err = jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(tt.raw), []jsonxtractr.Selector{tt.selector}, opts...)
+			if strings.Contains(err.Error(), secret) {
+				t.Errorf("ExtractValuesFromReaderOpts() error leaks the secret: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithErrorJSONRedactKeys_KeepsOtherValues(t *testing.T) {
+	raw := []byte(`{"password": "hunter2", "note": "password", "n": 42}`)
+
+	_, err := jsonxtractr.ExtractValueFromBytesOpts(raw, "missing", jsonxtractr.WithErrorJSONRedactKeys("password"))
+	context, _ := jsonxtractr.ErrValue[string](err, "condensed_json")
+	want := `{"password": "***", "note": "password", "n": 42}`
+	if context != want {
+		t.Errorf("condensed_json = %s, want %s", context, want)
+	}
+
+	// Without the option the JSON is unchanged
+	_, err = jsonxtractr.ExtractValueFromBytes(raw, "missing")
+	if context, _ := jsonxtractr.ErrValue[string](err, "condensed_json"); context != string(raw) {
+		t.Errorf("condensed_json = %s, want %s", context, raw)
+	}
+}
+
+func TestWithErrorJSONMaxLen(t *testing.T) {
+	raw := []byte(`{"items": [` + strings.Repeat(`{"id": 1}, `, 50) + `{"id": 2}]}`)
+
+	for _, maxLen := range []int{5, 40, 120, 300} {
+		t.Run(fmt.Sprintf("max=%d", maxLen), func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytesOpts(raw, "missing", jsonxtractr.WithErrorJSONMaxLen(maxLen))
+			context, ok := jsonxtractr.ErrValue[string](err, "condensed_json")
+			if !ok {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v, want condensed_json", err)
+			}
+			if len(context) > maxLen {
+				t.Errorf("condensed_json has %d bytes, want at most %d: %s", len(context), maxLen, context)
+			}
+		})
+	}
+
+	// The default stays at 200 bytes
+	_, err := jsonxtractr.ExtractValueFromBytes(raw, "missing")
+	if context, _ := jsonxtractr.ErrValue[string](err, "condensed_json"); len(context) > 200 || len(context) < 150 {
+		t.Errorf("condensed_json has %d bytes, want the default of about 200", len(context))
+	}
+}
+
+func TestWithTolerant(t *testing.T) {
+	config := `{
+		// Service settings
+		"name": "api", /* inline */
+		"url": "http://example.com//path", // a "//" inside a string is kept
+		"note": "a /* not a comment */ b",
+		"ports": [80, 443,],
+		"limits": {
+			"cpu": 2,
+			"mem": "1,]",
+		},
+	}`
+
+	tests := []struct {
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{selector: "name", want: "api"},
+		{selector: "url", want: "http://example.com//path"},
+		{selector: "note", want: "a /* not a comment */ b"},
+		{selector: "ports.-1", want: float64(443)},
+		{selector: "limits.mem", want: "1,]"},
+		{selector: "limits", want: map[string]any{"cpu": float64(2), "mem": "1,]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytesOpts([]byte(config), tt.selector, jsonxtractr.WithTolerant())
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytesOpts() = %v, want %v", got, tt.want)
+			}
+
+			valuesMap, _, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(config), []jsonxtractr.Selector{tt.selector}, jsonxtractr.WithTolerant())
+			if err != nil {
+				t.Fatalf("ExtractValuesFromReaderOpts() error = %v", err)
+			}
+			if !reflect.DeepEqual(valuesMap[tt.selector], tt.want) {
+				t.Errorf("ExtractValuesFromReaderOpts() = %v, want %v", valuesMap[tt.selector], tt.want)
+			}
+		})
+	}
+
+	// Several selectors share the stripped input
+	valuesMap, _, err := jsonxtractr.ExtractValuesFromBytesOpts([]byte(config), []jsonxtractr.Selector{"name", "ports.0"}, jsonxtractr.WithTolerant())
+	if err != nil || valuesMap["name"] != "api" || valuesMap["ports.0"] != float64(80) {
+		t.Errorf("ExtractValuesFromBytesOpts() = %v, %v", valuesMap, err)
+	}
+}
+
+func TestWithTolerant_StrictByDefault(t *testing.T) {
+	for _, raw := range []string{
+		`{"x": {"y": 1,}, "a": 1}`,
+		`{"x": [1, 2,], "a": 1}`,
+		"{\"x\": 1, // comment\n\"a\": 1}",
+		`{"a": /* comment */ 1}`,
+	} {
+		_, err := jsonxtractr.ExtractValueFromBytes([]byte(raw), "a")
+		if err == nil {
+			t.Errorf("ExtractValueFromBytes(%s) error = nil, want a syntax error", raw)
+		}
+	}
+}
+
+func TestWithTolerant_ErrorPositions(t *testing.T) {
+	// Blanking comments keeps lines and columns matching the input
+	raw := "{\n  /* one\n  two */ \"a\": {\"b\": 1,},\n  \"c\": 2\n}"
+
+	_, err := jsonxtractr.ExtractValueFromBytesOpts([]byte(raw), "a.x", jsonxtractr.WithTolerant())
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Fatalf("ExtractValueFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	line, ok := jsonxtractr.ErrValue[int](err, "line")
+	if !ok || line != 3 {
+		t.Errorf("line = %d, want 3", line)
+	}
+
+	// Unterminated comments and other syntax errors are still reported
+	_, err = jsonxtractr.ExtractValueFromBytesOpts([]byte(`{"a": /* open`), "a", jsonxtractr.WithTolerant())
+	if err == nil {
+		t.Error("ExtractValueFromBytesOpts() error = nil, want an error for an unterminated comment")
+	}
+	_, err = jsonxtractr.ExtractValueFromBytesOpts([]byte(`{"a": [1,,]}`), "a", jsonxtractr.WithTolerant())
+	if err == nil {
+		t.Error("ExtractValueFromBytesOpts() error = nil, want an error for a doubled comma")
+	}
+}
+
+func TestWithSmartNumbers(t *testing.T) {
+	jsonData := []byte(`{
+		"small": 42,
+		"negative": -7,
+		"zero": 0,
+		"max": 9223372036854775807,
+		"fraction": 1.5,
+		"whole_fraction": 2.0,
+		"exponent": 1e3,
+		"huge": 123456789012345678901234567890,
+		"huge_negative": -9223372036854775809,
+		"nested": {"list": [1, 2.5, "3"]}
+	}`)
+
+	tests := []struct {
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{selector: "small", want: int64(42)},
+		{selector: "negative", want: int64(-7)},
+		{selector: "zero", want: int64(0)},
+		{selector: "max", want: int64(9223372036854775807)},
+		{selector: "fraction", want: 1.5},
+		{selector: "whole_fraction", want: 2.0},
+		{selector: "exponent", want: 1000.0},
+		{selector: "huge", want: json.Number("123456789012345678901234567890")},
+		{selector: "huge_negative", want: json.Number("-9223372036854775809")},
+		{selector: "nested", want: map[string]any{"list": []any{int64(1), 2.5, "3"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, tt.selector, jsonxtractr.WithSmartNumbers())
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytesOpts() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	// Without the option numbers stay float64
+	got, err := jsonxtractr.ExtractValueFromBytes(jsonData, "small")
+	if err != nil || got != float64(42) {
+		t.Errorf("ExtractValueFromBytes() = %#v, %v, want float64(42)", got, err)
+	}
+
+	// WithNumbersAsString takes precedence
+	got, err = jsonxtractr.ExtractValueFromBytesOpts(jsonData, "small", jsonxtractr.WithSmartNumbers(), jsonxtractr.WithNumbersAsString())
+	if err != nil || got != "42" {
+		t.Errorf("ExtractValueFromBytesOpts() = %#v, %v, want \"42\"", got, err)
+	}
+}
+
+func TestWithTrimStrings(t *testing.T) {
+	jsonData := []byte(`{
+		"name": "  Alice \t\n",
+		"count": 3,
+		"user": {" padded key ": " value ", "tags": [" a", "b ", 1, null], "ok": true}
+	}`)
+
+	tests := []struct {
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{selector: "name", want: "Alice"},
+		{selector: "count", want: float64(3)},
+		{selector: "user.tags.0", want: "a"},
+		{selector: "user", want: map[string]any{
+			" padded key ": "value",
+			"tags":         []any{"a", "b", float64(1), nil},
+			"ok":           true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, tt.selector, jsonxtractr.WithTrimStrings())
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytesOpts() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	// Multiple selectors trim alike, and combine with other decoding options
+	values, _, err := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, []jsonxtractr.Selector{"name", "user.tags"},
+		jsonxtractr.WithTrimStrings(), jsonxtractr.WithSmartNumbers())
+	if err != nil {
+		t.Fatalf("ExtractValuesFromBytesOpts() error = %v", err)
+	}
+	want := jsonxtractr.ValuesMap{"name": "Alice", "user.tags": []any{"a", "b", int64(1), nil}}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromBytesOpts() = %#v, want %#v", values, want)
+	}
+
+	// Without the option strings keep their whitespace
+	got, err := jsonxtractr.ExtractValueFromBytes(jsonData, "name")
+	if err != nil || got != "  Alice \t\n" {
+		t.Errorf("ExtractValueFromBytes() = %#v, %v, want %q", got, err, "  Alice \t\n")
+	}
+}
+
+func TestWithEmptyAsNotFound(t *testing.T) {
+	jsonData := []byte(`{"xs": [], "obj": {}, "full": [1, 2]}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     int
+	}{
+		{name: "empty array", selector: "xs.*", want: 0},
+		{name: "empty object", selector: "obj.*", want: 0},
+		{name: "glob on empty object", selector: "obj.a*", want: 0},
+		{name: "non-empty array", selector: "full.*", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// By default an empty container gives an empty result
+			matches, err := jsonxtractr.ExtractMatches(jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractMatches() error = %v", err)
+			}
+			if matches == nil || len(matches) != tt.want {
+				t.Errorf("ExtractMatches() = %#v, want %d matches", matches, tt.want)
+			}
+
+			matches, err = jsonxtractr.ExtractMatchesOpts(jsonData, tt.selector, jsonxtractr.WithEmptyAsNotFound())
+			if tt.want > 0 {
+				if err != nil || len(matches) != tt.want {
+					t.Errorf("ExtractMatchesOpts() = %#v, %v, want %d matches", matches, err, tt.want)
+				}
+				return
+			}
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorNotFound) {
+				t.Errorf("ExtractMatchesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorNotFound)
+			}
+			if len(matches) != 0 {
+				t.Errorf("ExtractMatchesOpts() = %#v, want no matches", matches)
+			}
+		})
+	}
+}
+
+func TestWithMaxTokens(t *testing.T) {
+	// Many repetitions of the same small structure ahead of the target
+	jsonData := []byte(`{"xs": [` + strings.Repeat(`{"a": [0]},`, 5000) + `1], "obj": {` +
+		strings.Repeat(`"k": 0,`, 5000) + `"z": 1}}`)
+
+	tests := []struct {
+		name      string
+		selectors []jsonxtractr.Selector
+	}{
+		{name: "array index", selectors: []jsonxtractr.Selector{"xs.5000"}},
+		{name: "negative index", selectors: []jsonxtractr.Selector{"xs.-1"}},
+		{name: "object key", selectors: []jsonxtractr.Selector{"obj.z"}},
+		{name: "several selectors", selectors: []jsonxtractr.Selector{"xs.5000", "obj.z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, tt.selectors, jsonxtractr.WithMaxTokens(1000))
+			if !errors.Is(err, jsonxtractr.ErrJSONTooManyTokens) {
+				t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONTooManyTokens)
+			}
+			if limit, _ := jsonxtractr.ErrValue[int64](err, "max_tokens"); limit != 1000 {
+				t.Errorf("ErrValue(max_tokens) = %d, want 1000", limit)
+			}
+
+			// The default limit is never reached by such a document
+			values, _, err := jsonxtractr.ExtractValuesFromBytes(jsonData, tt.selectors)
+			if err != nil || len(values) != len(tt.selectors) {
+				t.Errorf("ExtractValuesFromBytes() = %v, %v, want %d values", values, err, len(tt.selectors))
+			}
+		})
+	}
+
+	_, err := jsonxtractr.ExtractMatchesOpts(jsonData, "xs.*.a", jsonxtractr.WithMaxTokens(1000))
+	if !errors.Is(err, jsonxtractr.ErrJSONTooManyTokens) {
+		t.Errorf("ExtractMatchesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONTooManyTokens)
+	}
+
+	// A limit the navigation stays within changes nothing
+	value, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, "xs.5", jsonxtractr.WithMaxTokens(1000))
+	if err != nil || !reflect.DeepEqual(value, map[string]any{"a": []any{float64(0)}}) {
+		t.Errorf("ExtractValueFromBytesOpts() = %v, %v", value, err)
+	}
+}
+
+func TestWithStrictUTF8(t *testing.T) {
+	// 0xc3 starts a two-byte sequence, but '(' is no continuation byte
+	invalid := []byte("{\"name\": \"ok\", \"bio\": \"caf\xc3(\", \"age\": 3}")
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+	}{
+		{name: "selected string", selector: "bio"},
+		{name: "value before the invalid string", selector: "name"},
+		{name: "value after the invalid string", selector: "age"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytesOpts(invalid, tt.selector, jsonxtractr.WithStrictUTF8())
+			if !errors.Is(err, jsonxtractr.ErrJSONInvalidUTF8) {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONInvalidUTF8)
+			}
+			if offset, _ := jsonxtractr.ErrValue[int64](err, "byte_offset"); offset != 26 {
+				t.Errorf("byte_offset = %d, want 26", offset)
+			}
+
+			_, _, err = jsonxtractr.ExtractValuesFromReaderOpts(bytes.NewReader(invalid), []jsonxtractr.Selector{tt.selector}, jsonxtractr.WithStrictUTF8())
+			if !errors.Is(err, jsonxtractr.ErrJSONInvalidUTF8) {
+				t.Errorf("ExtractValuesFromReaderOpts() error = %v, want %v", err, jsonxtractr.ErrJSONInvalidUTF8)
+			}
+		})
+	}
+
+	// Invalid UTF-8 in a key is rejected too
+	_, err := jsonxtractr.ExtractValueFromBytesOpts([]byte("{\"a\": 1, \"k\xff\": 2}"), "a", jsonxtractr.WithStrictUTF8())
+	if !errors.Is(err, jsonxtractr.ErrJSONInvalidUTF8) {
+		t.Errorf("ExtractValueFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONInvalidUTF8)
+	}
+
+	// Without the option, input that extraction never reads isn't checked
+	value, err := jsonxtractr.ExtractValueFromBytes(invalid, "name")
+	if err != nil || value != "ok" {
+		t.Errorf("ExtractValueFromBytes() = %v, %v, want ok", value, err)
+	}
+
+	// Valid multi-byte UTF-8 passes
+	value, err = jsonxtractr.ExtractValueFromBytesOpts([]byte(`{"bio": "café ☕"}`), "bio", jsonxtractr.WithStrictUTF8())
+	if err != nil || value != "café ☕" {
+		t.Errorf("ExtractValueFromBytesOpts() = %v, %v, want café ☕", value, err)
+	}
+}
+
+func TestWithFastSkip(t *testing.T) {
+	jsonData := []byte(`{"xs": [
+		{"s": "a ] } , [ {", "q": "\"]\\", "n": [1, [2, {"x": []}]]},
+		[],
+		"plain , ]",
+		-1.5e3 ,
+		{"name": "target", "items": [10, 20, 30]},
+		null
+	], "after": true}`)
+
+	selectors := []jsonxtractr.Selector{
+		"xs.0.s", "xs.0.n.1.1.x", "xs.1", "xs.2", "xs.3", "xs.4.name",
+		"xs.4.items.2", "xs.5", "xs.-2.name", "xs.4.items.-1", "after",
+	}
+	for _, selector := range selectors {
+		want, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+		if err != nil {
+			t.Fatalf("ExtractValueFromBytes(%s) error = %v", selector, err)
+		}
+		got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, selector, jsonxtractr.WithFastSkip())
+		if err != nil {
+			t.Errorf("ExtractValueFromBytesOpts(%s) error = %v", selector, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ExtractValueFromBytesOpts(%s) = %#v, want %#v", selector, got, want)
+		}
+	}
+
+	for _, selector := range []jsonxtractr.Selector{"xs.6", "xs.4.items.3"} {
+		_, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, selector, jsonxtractr.WithFastSkip())
+		if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+			t.Errorf("ExtractValueFromBytesOpts(%s) error = %v, want %v", selector, err, jsonxtractr.ErrJSONIndexOutOfRange)
+		}
+	}
+	_, err := jsonxtractr.ExtractValueFromBytesOpts([]byte(`{"xs": [ ]}`), "xs.0", jsonxtractr.WithFastSkip())
+	if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+		t.Errorf("ExtractValueFromBytesOpts(empty array) error = %v, want %v", err, jsonxtractr.ErrJSONIndexOutOfRange)
+	}
+}
+
+func TestWithFastSkip_SkippedElementsNotValidated(t *testing.T) {
+	jsonData := []byte(`[{"a": tru}, {"a": 1, "a": 2}, "ok"]`)
+
+	_, err := jsonxtractr.ExtractValueFromBytes(jsonData, "2")
+	if err == nil {
+		t.Fatal("ExtractValueFromBytes() error = nil, want a syntax error")
+	}
+
+	value, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, "2", jsonxtractr.WithFastSkip())
+	if err != nil || value != "ok" {
+		t.Errorf("ExtractValueFromBytesOpts() = %v, %v, want ok", value, err)
+	}
+}
+
+func TestWithFastSkip_MalformedStructure(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		selector jsonxtractr.Selector
+	}{
+		{name: "mismatched brackets", raw: `[{"a": [1}, 2]`, selector: "1"},
+		{name: "unterminated string", raw: `["abc, 2]`, selector: "1"},
+		{name: "unterminated array", raw: `[1, 2`, selector: "2"},
+		{name: "empty element", raw: `[1,,2]`, selector: "2"},
+		{name: "malformed target", raw: `[1, {"a": }]`, selector: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytesOpts([]byte(tt.raw), tt.selector, jsonxtractr.WithFastSkip())
+			if err == nil {
+				t.Fatal("ExtractValueFromBytesOpts() error = nil, want an error")
+			}
+			if errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+				t.Errorf("ExtractValueFromBytesOpts() error = %v, want a syntax error", err)
+			}
+		})
+	}
+}
+
+func TestWithMissingAsNil(t *testing.T) {
+	jsonData := `{"host": "localhost", "port": 8080, "debug": null, "tls": {"enabled": true}}`
+	selectors := []jsonxtractr.Selector{"host", "port", "debug", "timeout", "tls.cert", "tls.enabled.x"}
+
+	t.Run("bytes", func(t *testing.T) {
+		valuesMap, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts([]byte(jsonData), selectors[:5], jsonxtractr.WithMissingAsNil())
+		if err != nil {
+			t.Fatalf("ExtractValuesFromBytesOpts() error = %v", err)
+		}
+		want := jsonxtractr.ValuesMap{
+			"host":     "localhost",
+			"port":     float64(8080),
+			"debug":    nil,
+			"timeout":  jsonxtractr.Missing,
+			"tls.cert": jsonxtractr.Missing,
+		}
+		if !reflect.DeepEqual(valuesMap, want) {
+			t.Errorf("ExtractValuesFromBytesOpts() = %v, want %v", valuesMap, want)
+		}
+		if valuesMap["debug"] == jsonxtractr.Missing {
+			t.Error("ExtractValuesFromBytesOpts() recorded a JSON null as Missing")
+		}
+		if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"timeout", "tls.cert"}) {
+			t.Errorf("ExtractValuesFromBytesOpts() notFound = %v, want [timeout tls.cert]", notFound)
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		for _, selectors := range [][]jsonxtractr.Selector{{"timeout"}, {"host", "timeout"}} {
+			valuesMap, _, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(jsonData), selectors, jsonxtractr.WithMissingAsNil())
+			if err != nil {
+				t.Fatalf("ExtractValuesFromReaderOpts(%v) error = %v", selectors, err)
+			}
+			if valuesMap["timeout"] != jsonxtractr.Missing {
+				t.Errorf("ExtractValuesFromReaderOpts(%v) = %v, want timeout Missing", selectors, valuesMap)
+			}
+		}
+	})
+
+	t.Run("wrong-type path", func(t *testing.T) {
+		jsonData := []byte(`{"count":3,"a":{"b":1}}`)
+		selectors := []jsonxtractr.Selector{"count.x", "a.zz"}
+
+		valuesMap, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, selectors, jsonxtractr.WithMissingAsNil())
+		if err != nil {
+			t.Errorf("ExtractValuesFromBytesOpts() error = %v, want nil", err)
+		}
+		want := jsonxtractr.ValuesMap{"count.x": jsonxtractr.Missing, "a.zz": jsonxtractr.Missing}
+		if !reflect.DeepEqual(valuesMap, want) {
+			t.Errorf("ExtractValuesFromBytesOpts() = %v, want %v", valuesMap, want)
+		}
+		if !reflect.DeepEqual(notFound, selectors) {
+			t.Errorf("ExtractValuesFromBytesOpts() notFound = %v, want %v", notFound, selectors)
+		}
+	})
+
+	t.Run("genuine errors", func(t *testing.T) {
+		valuesMap, _, err := jsonxtractr.ExtractValuesFromBytesOpts([]byte(jsonData), append(selectors, `"tls`), jsonxtractr.WithMissingAsNil())
+		if !errors.Is(err, jsonxtractr.ErrJSONSelectorUnbalancedQuote) {
+			t.Errorf("ExtractValuesFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorUnbalancedQuote)
+		}
+		var multiErr *jsonxtractr.MultiError
+		if errors.As(err, &multiErr) && !reflect.DeepEqual(multiErr.Selectors(), []jsonxtractr.Selector{`"tls`}) {
+			t.Errorf("MultiError.Selectors() = %v, want only the genuine failure", multiErr.Selectors())
+		}
+		if valuesMap["timeout"] != jsonxtractr.Missing || valuesMap["tls.enabled.x"] != jsonxtractr.Missing {
+			t.Errorf("ExtractValuesFromBytesOpts() = %v, want timeout and tls.enabled.x Missing", valuesMap)
+		}
+
+		_, _, err = jsonxtractr.ExtractValuesFromBytesOpts([]byte(`{"host": `), []jsonxtractr.Selector{"host", "port"}, jsonxtractr.WithMissingAsNil())
+		if err == nil {
+			t.Error("ExtractValuesFromBytesOpts() error = nil, want malformed JSON reported")
+		}
+	})
+}
+
+// closeFlagReader is an io.ReadCloser recording whether it was closed.
+type closeFlagReader struct {
+	*strings.Reader
+	closed bool
+}
+
+func (r *closeFlagReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestWithCloseReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		selectors []jsonxtractr.Selector
+		opts      []jsonxtractr.Option
+		wantErr   bool
+		wantClose bool
+	}{
+		{name: "closed", json: `{"a": 1}`, selectors: []jsonxtractr.Selector{"a"}, opts: []jsonxtractr.Option{jsonxtractr.WithCloseReader()}, wantClose: true},
+		{name: "closed with several selectors", json: `{"a": 1, "b": 2}`, selectors: []jsonxtractr.Selector{"a", "b"}, opts: []jsonxtractr.Option{jsonxtractr.WithCloseReader()}, wantClose: true},
+		{name: "closed on error", json: `{"a": `, selectors: []jsonxtractr.Selector{"a"}, opts: []jsonxtractr.Option{jsonxtractr.WithCloseReader()}, wantErr: true, wantClose: true},
+		{name: "closed without selectors", json: `{"a": 1}`, opts: []jsonxtractr.Option{jsonxtractr.WithCloseReader()}, wantErr: true, wantClose: true},
+		{name: "left open by default", json: `{"a": 1}`, selectors: []jsonxtractr.Selector{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &closeFlagReader{Reader: strings.NewReader(tt.json)}
+			_, _, err := jsonxtractr.ExtractValuesFromReaderOpts(reader, tt.selectors, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractValuesFromReaderOpts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if reader.closed != tt.wantClose {
+				t.Errorf("reader closed = %v, want %v", reader.closed, tt.wantClose)
+			}
+		})
+	}
+
+	reader := &closeFlagReader{Reader: strings.NewReader(`{"a": 1}`)}
+	_, err := jsonxtractr.ExtractValueFromReaderOpts(reader, "a", jsonxtractr.WithCloseReader())
+	if err != nil {
+		t.Fatalf("ExtractValueFromReaderOpts() error = %v", err)
+	}
+	if !reader.closed {
+		t.Error("ExtractValueFromReaderOpts() left the reader open")
+	}
+}
+
+func TestWithAnySeparator(t *testing.T) {
+	jsonData := []byte(`{
+	"user": {"addresses": [{"city": "Paris"}, {"city": "Oslo"}], "a/b": 1},
+	"links": [{"url": "http://x/y", "rel": "self"}]
+}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{name: "slashes", selector: "user/addresses/1/city", want: "Oslo"},
+		{name: "mixed separators", selector: "user/addresses.0/city", want: "Paris"},
+		{name: "dots", selector: "user.addresses.1.city", want: "Oslo"},
+		{name: "quoted segment containing slash", selector: `user/"a/b"`, want: float64(1)},
+		{name: "escaped slash", selector: `user/a\/b`, want: float64(1)},
+		{name: "bracketed key containing slash", selector: `user['a/b']`, want: float64(1)},
+		{name: "filter containing slash", selector: `links/#(url=="http://x/y")/rel`, want: "self"},
+		{name: "negative index", selector: "user/addresses/-1/city", want: "Oslo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, tt.selector, jsonxtractr.WithAnySeparator())
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractValueFromBytesOpts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("results keyed by selector as passed", func(t *testing.T) {
+		valuesMap, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(jsonData,
+			[]jsonxtractr.Selector{"user/addresses/0/city", `user."a/b"`},
+			jsonxtractr.WithAnySeparator(),
+		)
+		if err != nil {
+			t.Fatalf("ExtractValuesFromBytesOpts() error = %v, notFound = %v", err, notFound)
+		}
+		if valuesMap["user/addresses/0/city"] != "Paris" || valuesMap[`user."a/b"`] != float64(1) {
+			t.Errorf("ExtractValuesFromBytesOpts() = %v", valuesMap)
+		}
+	})
+
+	t.Run("slash is a key character by default", func(t *testing.T) {
+		_, err := jsonxtractr.ExtractValueFromBytes(jsonData, "user/addresses")
+		if err == nil {
+			t.Error("ExtractValueFromBytes() error = nil, want an error")
+		}
+	})
+}
+
+func TestWithValidateRemainder(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantOffset int64
+	}{
+		{name: "trailing junk", json: `{"a": 1, "b": 2} junk`, wantOffset: 17},
+		{name: "second value", json: `{"a": 1} {"a": 2}`, wantOffset: 9},
+		{name: "second number", json: `{"a":1} 12345`, wantOffset: 8},
+		{name: "second value after newlines", json: "{\"a\": 1}\n\n\t\"x\"", wantOffset: 11},
+		{name: "malformed after the value", json: `{"a": 1, "b": [1, }`, wantOffset: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := jsonxtractr.ExtractValueFromBytes([]byte(tt.json), "a")
+			if err != nil || value != float64(1) {
+				t.Fatalf("ExtractValueFromBytes() = %v, %v, want 1", value, err)
+			}
+
+			_, err = jsonxtractr.ExtractValueFromBytesOpts([]byte(tt.json), "a", jsonxtractr.WithValidateRemainder())
+			if !errors.Is(err, jsonxtractr.ErrJSONStreamingParseFailed) {
+				t.Fatalf("ExtractValueFromBytesOpts() error = %v, want %v", err, jsonxtractr.ErrJSONStreamingParseFailed)
+			}
+			match := regexp.MustCompile(`byte_offset=(\d+)`).FindStringSubmatch(err.Error())
+			if match == nil || match[1] != fmt.Sprint(tt.wantOffset) {
+				t.Errorf("error %q does not report byte_offset=%d", err, tt.wantOffset)
+			}
+
+			_, _, err = jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(tt.json), []jsonxtractr.Selector{"a"}, jsonxtractr.WithValidateRemainder())
+			if !errors.Is(err, jsonxtractr.ErrJSONStreamingParseFailed) {
+				t.Errorf("ExtractValuesFromReaderOpts() error = %v, want %v", err, jsonxtractr.ErrJSONStreamingParseFailed)
+			}
+		})
+	}
+
+	// A well-formed document, with whitespace after it, passes
+	value, err := jsonxtractr.ExtractValueFromBytesOpts([]byte("{\"a\": 1}\n"), "a", jsonxtractr.WithValidateRemainder())
+	if err != nil || value != float64(1) {
+		t.Errorf("ExtractValueFromBytesOpts() = %v, %v, want 1", value, err)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	const size = 1 << 20
+	const every = 64 << 10
+
+	// A large document whose only selected value comes last
+	var b strings.Builder
+	b.WriteString(`{"padding": "`)
+	b.WriteString(strings.Repeat("x", size))
+	b.WriteString(`", "last": 1}`)
+	jsonData := b.String()
+
+	var calls []int64
+	report := func(bytesRead int64) {
+		calls = append(calls, bytesRead)
+	}
+
+	value, err := jsonxtractr.ExtractValueFromReaderOpts(strings.NewReader(jsonData), "last", jsonxtractr.WithProgress(report, every))
+	if err != nil || value != float64(1) {
+		t.Fatalf("ExtractValueFromReaderOpts() = %v, %v, want 1", value, err)
+	}
+
+	// One call per every bytes read, give or take the last partial chunk
+	want := len(jsonData) / every
+	if len(calls) < want-1 || len(calls) > want+1 {
+		t.Errorf("progress called %d times, want about %d", len(calls), want)
+	}
+	if !slices.IsSorted(calls) {
+		t.Errorf("progress reported %v, want increasing byte counts", calls)
+	}
+	if last := calls[len(calls)-1]; last > int64(len(jsonData)) {
+		t.Errorf("progress reported %d bytes, more than the %d in the input", last, len(jsonData))
+	}
+
+	t.Run("whole input read first", func(t *testing.T) {
+		calls = nil
+		_, _, err := jsonxtractr.ExtractValuesFromReaderOpts(strings.NewReader(jsonData), []jsonxtractr.Selector{"last"},
+			jsonxtractr.WithProgress(report, every),
+			jsonxtractr.WithStrictUTF8(),
+		)
+		if err != nil {
+			t.Fatalf("ExtractValuesFromReaderOpts() error = %v", err)
+		}
+		if len(calls) < want-1 || len(calls) > want+1 {
+			t.Errorf("progress called %d times, want about %d", len(calls), want)
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		calls = nil
+		_, err := jsonxtractr.ExtractValueFromReader(strings.NewReader(jsonData), "last")
+		if err != nil || len(calls) != 0 {
+			t.Errorf("ExtractValueFromReader() error = %v with %d progress calls, want none", err, len(calls))
+		}
+	})
+}
+
+func TestWithProgress_EveryRead(t *testing.T) {
+	var calls []int64
+	report := func(bytesRead int64) {
+		calls = append(calls, bytesRead)
+	}
+
+	reader := iotest.OneByteReader(strings.NewReader(`{"a": 1}`))
+	_, err := jsonxtractr.ExtractValueFromReaderOpts(reader, "a", jsonxtractr.WithProgress(report, 0))
+	if err != nil {
+		t.Fatalf("ExtractValueFromReaderOpts() error = %v", err)
+	}
+	if len(calls) < 7 || calls[0] != 1 {
+		t.Errorf("progress reported %v, want a call after each one-byte read", calls)
+	}
+}
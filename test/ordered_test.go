@@ -0,0 +1,68 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractOrdered(t *testing.T) {
+	var jsonData []byte
+	var selectors []jsonxtractr.Selector
+
+	// Enough selectors that map iteration order would almost surely differ
+	jsonData = append(jsonData, '{')
+	for i := range 50 {
+		if i > 0 {
+			jsonData = append(jsonData, ',')
+		}
+		jsonData = fmt.Appendf(jsonData, `"k%d": %d`, i, i)
+		selectors = append(selectors, jsonxtractr.Selector(fmt.Sprintf("k%d", 49-i)))
+	}
+	jsonData = append(jsonData, '}')
+
+	results, notFound, err := jsonxtractr.ExtractOrdered(jsonData, selectors)
+	if err != nil || len(notFound) != 0 {
+		t.Fatalf("ExtractOrdered() = %v, %v", notFound, err)
+	}
+	if len(results) != len(selectors) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(selectors))
+	}
+	for i, result := range results {
+		want := jsonxtractr.Result{Selector: selectors[i], Value: float64(49 - i)}
+		if result != want {
+			t.Errorf("results[%d] = %+v, want %+v", i, result, want)
+		}
+	}
+}
+
+func TestExtractOrdered_MissingAndDuplicates(t *testing.T) {
+	jsonData := []byte(`{"a": 1, "b": null, "c": {"d": "x"}}`)
+	selectors := []jsonxtractr.Selector{"c.d", "z", "b", "a", "c.d", "y"}
+
+	results, notFound, err := jsonxtractr.ExtractOrdered(jsonData, selectors)
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractOrdered() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	want := []jsonxtractr.Result{
+		{Selector: "c.d", Value: "x"},
+		{Selector: "b", Value: nil},
+		{Selector: "a", Value: float64(1)},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("ExtractOrdered() results = %+v, want %+v", results, want)
+	}
+	if wantNotFound := []jsonxtractr.Selector{"z", "y"}; !reflect.DeepEqual(notFound, wantNotFound) {
+		t.Errorf("ExtractOrdered() notFound = %v, want %v", notFound, wantNotFound)
+	}
+}
+
+func TestExtractOrdered_Errors(t *testing.T) {
+	results, _, err := jsonxtractr.ExtractOrdered(nil, []jsonxtractr.Selector{"a"})
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) || results != nil {
+		t.Errorf("ExtractOrdered(nil) = %v, %v, want nil, %v", results, err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+}
@@ -0,0 +1,329 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestPathError_AvailableKeys(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice", "email": "a@example.com", "age": 30}}`)
+
+	_, err := jsonxtractr.ExtractValueFromBytes(jsonData, "user.nmae")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Fatalf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+
+	var pathErr *jsonxtractr.PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("errors.As(%v) found no *PathError", err)
+	}
+
+	// Traversal stops at the closing brace of the searched object
+	offset := bytes.IndexByte(jsonData, '}')
+	want := &jsonxtractr.PathError{
+		Selector:        "user.nmae",
+		Segment:         "nmae",
+		SegmentPosition: 1,
+		PathProgress:    []string{"user"},
+		AvailableKeys:   []string{"name", "email", "age"},
+		Suggestion:      "name",
+		ByteOffset:      int64(offset),
+		Line:            1,
+		Column:          offset + 1,
+		Window:          string(jsonData[offset-50:]),
+		WindowPos:       50,
+	}
+	if !reflect.DeepEqual(pathErr, want) {
+		t.Errorf("PathError = %+v, want %+v", pathErr, want)
+	}
+}
+
+func TestPathError_Suggestion(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice", "email": "a@example.com", "age": 30}}`)
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     string
+	}{
+		{name: "close typo", selector: "user.nam", want: "name"},
+		{name: "transposed letters", selector: "user.emial", want: "email"},
+		{name: "wildly different", selector: "user.password", want: ""},
+		{name: "short and different", selector: "user.x", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, singleErr := jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+			_, _, multiErr := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{"user.age", tt.selector})
+			_, docErr := doc.Value(tt.selector)
+
+			for _, err := range []error{singleErr, multiErr, docErr} {
+				var pathErr *jsonxtractr.PathError
+				if !errors.As(err, &pathErr) {
+					t.Fatalf("errors.As(%v) found no *PathError", err)
+				}
+				if pathErr.Suggestion != tt.want {
+					t.Errorf("PathError.Suggestion = %q, want %q", pathErr.Suggestion, tt.want)
+				}
+				suggestion, ok := jsonxtractr.ErrValue[string](err, "suggestion")
+				if suggestion != tt.want || ok != (tt.want != "") {
+					t.Errorf("ErrValue(suggestion) = %q, %t, want %q", suggestion, ok, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPathError_ArrayLength(t *testing.T) {
+	jsonData := []byte(`{"items": [10, 20, 30]}`)
+
+	for _, selector := range []jsonxtractr.Selector{"items.5", "items.-4"} {
+		t.Run(string(selector), func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+			if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+				t.Fatalf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONIndexOutOfRange)
+			}
+
+			var pathErr *jsonxtractr.PathError
+			if !errors.As(err, &pathErr) {
+				t.Fatalf("errors.As(%v) found no *PathError", err)
+			}
+			if pathErr.ArrayLength != 3 {
+				t.Errorf("PathError.ArrayLength = %d, want 3", pathErr.ArrayLength)
+			}
+			if pathErr.AvailableKeys != nil {
+				t.Errorf("PathError.AvailableKeys = %v, want nil", pathErr.AvailableKeys)
+			}
+		})
+	}
+}
+
+func TestPathError_MultipleSelectors(t *testing.T) {
+	jsonData := []byte(`{"a": {"x": 1}, "b": {"y": 2, "z": 3}}`)
+
+	_, _, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{"a.x", "b.w"})
+
+	var pathErr *jsonxtractr.PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("errors.As(%v) found no *PathError", err)
+	}
+	if pathErr.Selector != "b.w" {
+		t.Errorf("PathError.Selector = %q, want %q", pathErr.Selector, "b.w")
+	}
+	if !reflect.DeepEqual(pathErr.AvailableKeys, []string{"y", "z"}) {
+		t.Errorf("PathError.AvailableKeys = %v, want [y z]", pathErr.AvailableKeys)
+	}
+}
+
+func TestPathError_Document(t *testing.T) {
+	doc, err := jsonxtractr.NewDocument([]byte(`{"a": {"b": 1, "c": 2}}`))
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	_, err = doc.Value("a.d")
+
+	var pathErr *jsonxtractr.PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("errors.As(%v) found no *PathError", err)
+	}
+	if !reflect.DeepEqual(pathErr.AvailableKeys, []string{"b", "c"}) {
+		t.Errorf("PathError.AvailableKeys = %v, want [b c]", pathErr.AvailableKeys)
+	}
+}
+
+func TestPathError_ByteOffset(t *testing.T) {
+	jsonData := []byte(`{
+  "config": {
+    "servers": [
+      {"name": "alpha", "ports": [80, 443]},
+      {"name": "beta", "ports": [8080]}
+    ]
+  }
+}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		wantLine int
+		wantCol  int
+		wantAt   string
+	}{
+		{
+			name:     "deeply nested missing key",
+			selector: "config.servers.1.host",
+			wantLine: 5,
+			wantCol:  39,
+			wantAt:   "}",
+		},
+		{
+			name:     "index out of range reports array start",
+			selector: "config.servers.1.ports.3",
+			wantLine: 5,
+			wantCol:  33,
+			wantAt:   "[8080]",
+		},
+		{
+			name:     "negative index out of range reports array start",
+			selector: "config.servers.-1.ports.-2",
+			wantLine: 5,
+			wantCol:  33,
+			wantAt:   "[8080]",
+		},
+		{
+			name:     "missing key below negative index",
+			selector: "config.servers.-2.host",
+			wantLine: 4,
+			wantCol:  43,
+			wantAt:   "}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+
+			var pathErr *jsonxtractr.PathError
+			if !errors.As(err, &pathErr) {
+				t.Fatalf("errors.As(%v) found no *PathError", err)
+			}
+			if pathErr.Line != tt.wantLine || pathErr.Column != tt.wantCol {
+				t.Errorf("PathError line:column = %d:%d, want %d:%d", pathErr.Line, pathErr.Column, tt.wantLine, tt.wantCol)
+			}
+			if !bytes.HasPrefix(jsonData[pathErr.ByteOffset:], []byte(tt.wantAt)) {
+				t.Errorf("PathError.ByteOffset = %d at %q, want %q", pathErr.ByteOffset, jsonData[pathErr.ByteOffset:], tt.wantAt)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprintf("byte_offset=%d", pathErr.ByteOffset)) {
+				t.Errorf("error %q does not report byte_offset=%d", err, pathErr.ByteOffset)
+			}
+		})
+	}
+}
+
+func TestPathError_ByteOffsetDocument(t *testing.T) {
+	jsonData := []byte(`{"a": {"b": [1, 2]}}`)
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	_, err = doc.Value("a.b.5")
+
+	var pathErr *jsonxtractr.PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("errors.As(%v) found no *PathError", err)
+	}
+	if want := int64(bytes.IndexByte(jsonData, '[')); pathErr.ByteOffset != want {
+		t.Errorf("PathError.ByteOffset = %d, want %d", pathErr.ByteOffset, want)
+	}
+}
+
+func TestPathError_Window(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i := range 200 {
+		_, _ = fmt.Fprintf(&buf, "\t\"k%d\": {\"id\": %d, \"token\": \"secret-%d\"},\n", i, i, i)
+	}
+	buf.WriteString("\t\"items\": [\"café\", \"ünïcödé\", 3]\n}")
+	jsonData := buf.Bytes()
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		opts     []jsonxtractr.Option
+		wantAt   string
+		wantLen  int
+		want     string
+		dontWant string
+	}{
+		{
+			name:     "missing key",
+			selector: "k150.name",
+			wantAt:   "}",
+			wantLen:  100,
+			want:     `"token": "secret-150"}`,
+		},
+		{
+			name:     "index out of range",
+			selector: "items.5",
+			wantAt:   "[",
+			wantLen:  100,
+			want:     `"items": ["café"`,
+		},
+		{
+			name:     "bounded by the error JSON limit",
+			selector: "k150.name",
+			opts:     []jsonxtractr.Option{jsonxtractr.WithErrorJSONMaxLen(40)},
+			wantAt:   "}",
+			wantLen:  20,
+			want:     `-150"}`,
+		},
+		{
+			name:     "redacted",
+			selector: "k150.name",
+			opts:     []jsonxtractr.Option{jsonxtractr.WithErrorJSONRedactKeys("token")},
+			wantAt:   "}",
+			wantLen:  100,
+			want:     `"token": "***"}`,
+			dontWant: "secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, tt.selector, tt.opts...)
+
+			var pathErr *jsonxtractr.PathError
+			if !errors.As(err, &pathErr) {
+				t.Fatalf("errors.As(%v) found no *PathError", err)
+			}
+			window := pathErr.Window
+			if len(window) == 0 || len(window) > tt.wantLen {
+				t.Errorf("len(PathError.Window) = %d, want 1 to %d", len(window), tt.wantLen)
+			}
+			if !strings.HasPrefix(window[pathErr.WindowPos:], tt.wantAt) {
+				t.Errorf("PathError.Window = %q at %d, want %q there", window, pathErr.WindowPos, tt.wantAt)
+			}
+			if !strings.Contains(window, tt.want) {
+				t.Errorf("PathError.Window = %q, want it to contain %q", window, tt.want)
+			}
+			if tt.dontWant != "" && strings.Contains(err.Error(), tt.dontWant) {
+				t.Errorf("error %q contains %q", err, tt.dontWant)
+			}
+			if strings.ContainsAny(window, "\n\t") || !utf8.ValidString(window) {
+				t.Errorf("PathError.Window = %q, want valid UTF-8 on one line", window)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprintf("json_window_pos=%d", pathErr.WindowPos)) {
+				t.Errorf("error %q does not report json_window_pos=%d", err, pathErr.WindowPos)
+			}
+		})
+	}
+}
+
+func TestPathError_WindowSplitsNoRune(t *testing.T) {
+	jsonData := []byte(`{"a": "` + strings.Repeat("é", 40) + `", "b": {}}`)
+
+	for limit := 8; limit < 60; limit++ {
+		_, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, "b.c", jsonxtractr.WithErrorJSONMaxLen(limit))
+
+		var pathErr *jsonxtractr.PathError
+		if !errors.As(err, &pathErr) {
+			t.Fatalf("errors.As(%v) found no *PathError", err)
+		}
+		if !utf8.ValidString(pathErr.Window) || !strings.HasPrefix(pathErr.Window[pathErr.WindowPos:], "}") {
+			t.Errorf("WithErrorJSONMaxLen(%d): PathError.Window = %q at %d", limit, pathErr.Window, pathErr.WindowPos)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractValueByPointer(t *testing.T) {
+	jsonData := `{
+		"user": {"name": "Alice"},
+		"scores": [100, 85, 92],
+		"a/b": 1,
+		"m~n": 2,
+		"~1": 3,
+		"": 4,
+		"*": 5,
+		"x.y": 6,
+		"n": {"01": "zero-one", "-": "dash"}
+	}`
+
+	tests := []struct {
+		name    string
+		pointer string
+		want    any
+		wantErr error
+	}{
+		{name: "nested member", pointer: "/user/name", want: "Alice"},
+		{name: "array element", pointer: "/scores/1", want: float64(85)},
+		{name: "escaped slash", pointer: "/a~1b", want: float64(1)},
+		{name: "escaped tilde", pointer: "/m~0n", want: float64(2)},
+		{name: "escaped tilde then one", pointer: "/~01", want: float64(3)},
+		{name: "empty-string key", pointer: "/", want: float64(4)},
+		{name: "asterisk is literal", pointer: "/*", want: float64(5)},
+		{name: "dot is literal", pointer: "/x.y", want: float64(6)},
+		{name: "missing member", pointer: "/user/email", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "non-numeric token on array", pointer: "/scores/first", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "index out of range", pointer: "/scores/3", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "index past the end", pointer: "/scores/-", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "index beyond int", pointer: "/scores/99999999999999999999", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "negative index", pointer: "/scores/-1", wantErr: jsonxtractr.ErrJSONPointerInvalid},
+		{name: "index with leading zero", pointer: "/scores/01", wantErr: jsonxtractr.ErrJSONPointerInvalid},
+		{name: "index with sign", pointer: "/scores/+1", wantErr: jsonxtractr.ErrJSONPointerInvalid},
+		{name: "index zero", pointer: "/scores/0", want: float64(100)},
+		{name: "numeric member", pointer: "/n/01", want: "zero-one"},
+		{name: "dash member", pointer: "/n/-", want: "dash"},
+		{name: "missing leading slash", pointer: "user/name", wantErr: jsonxtractr.ErrJSONPointerInvalid},
+		{name: "invalid escape", pointer: "/m~2n", wantErr: jsonxtractr.ErrJSONPointerInvalid},
+		{name: "dangling escape", pointer: "/m~", wantErr: jsonxtractr.ErrJSONPointerInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueByPointer([]byte(jsonData), tt.pointer)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ExtractValueByPointer() error %v is not errors.Is(...) to %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractValueByPointer() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractValueByPointer() got %#v (%T), want %#v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValueByPointer_WholeDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want any
+	}{
+		{"object", `{"a": [1, 2]}`, map[string]any{"a": []any{float64(1), float64(2)}}},
+		{"array", `[true, null]`, []any{true, nil}},
+		{"scalar", `"text"`, "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueByPointer([]byte(tt.raw), "")
+			if err != nil {
+				t.Fatalf("ExtractValueByPointer() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractValueByPointer() got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
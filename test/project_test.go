@@ -0,0 +1,138 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestProject(t *testing.T) {
+	jsonData := []byte(`{
+		"id": 7,
+		"user": {"name": "Alice", "password": "s3cret", "address": {"city": "Paris", "zip": "75001"}},
+		"price": 1.50,
+		"items": [{"id": 1, "sku": "a"}, {"id": 2, "sku": "b"}, {"id": 3, "sku": "c"}]
+	}`)
+
+	tests := []struct {
+		name      string
+		selectors []jsonxtractr.Selector
+		want      string
+	}{
+		{name: "two nested fields", selectors: []jsonxtractr.Selector{"user.name", "user.address.city"}, want: `{"user":{"name":"Alice","address":{"city":"Paris"}}}`},
+		{name: "document order", selectors: []jsonxtractr.Selector{"user.name", "id"}, want: `{"id":7,"user":{"name":"Alice"}}`},
+		{name: "exact numbers", selectors: []jsonxtractr.Selector{"price"}, want: `{"price":1.50}`},
+		{name: "whole subtree", selectors: []jsonxtractr.Selector{"user.address"}, want: `{"user":{"address":{"city":"Paris","zip":"75001"}}}`},
+		{name: "array element", selectors: []jsonxtractr.Selector{"items.1.sku"}, want: `{"items":[{"sku":"b"}]}`},
+		{name: "negative index", selectors: []jsonxtractr.Selector{"items.-1.id"}, want: `{"items":[{"id":3}]}`},
+		{name: "wildcard", selectors: []jsonxtractr.Selector{"items.*.id"}, want: `{"items":[{"id":1},{"id":2},{"id":3}]}`},
+		{name: "filter", selectors: []jsonxtractr.Selector{`items.#(id>=2)#.sku`}, want: `{"items":[{"sku":"b"},{"sku":"c"}]}`},
+		{name: "index list", selectors: []jsonxtractr.Selector{"items.[2,0]"}, want: `{"items":[{"id":1,"sku":"a"},{"id":3,"sku":"c"}]}`},
+		{name: "overlapping", selectors: []jsonxtractr.Selector{"user.address", "user.address.city"}, want: `{"user":{"address":{"city":"Paris","zip":"75001"}}}`},
+		{name: "missing path omitted", selectors: []jsonxtractr.Selector{"id", "user.age", "nope.x"}, want: `{"id":7}`},
+		{name: "nothing found", selectors: []jsonxtractr.Selector{"nope"}, want: `{}`},
+		{name: "key on scalar", selectors: []jsonxtractr.Selector{"id.x"}, want: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.Project(jsonData, tt.selectors)
+			if err != nil {
+				t.Fatalf("Project() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Project() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProject_Root(t *testing.T) {
+	got, err := jsonxtractr.Project([]byte(`{"a": [1, 2], "b": "x"}`), []jsonxtractr.Selector{jsonxtractr.RootSelector})
+	if err != nil || string(got) != `{"a":[1,2],"b":"x"}` {
+		t.Errorf("Project() = %s, %v, want the whole document", got, err)
+	}
+
+	got, err = jsonxtractr.Project([]byte(`[{"a": 1}, {"b": 2}]`), []jsonxtractr.Selector{"1.b"})
+	if err != nil || string(got) != `[{"b":2}]` {
+		t.Errorf("Project() = %s, %v, want [{\"b\":2}]", got, err)
+	}
+}
+
+func TestProject_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		selectors []jsonxtractr.Selector
+		wantErr   error
+	}{
+		{name: "empty input", json: ``, selectors: []jsonxtractr.Selector{"a"}, wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+		{name: "invalid selector", json: `{"a": 1}`, selectors: []jsonxtractr.Selector{`"a`}, wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote},
+		{name: "malformed json", json: `{"a": {"b": 1,}}`, selectors: []jsonxtractr.Selector{"a.b"}, wantErr: jsonxtractr.ErrJSONTokenReadFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.Project([]byte(tt.json), tt.selectors)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Project() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	jsonData := []byte(`{
+		"user": {"name": "Alice", "password": "s3cret", "tokens": {"api": "k1", "refresh": "k2"}},
+		"price": 1.50,
+		"items": [10, 20, 30, 40]
+	}`)
+
+	tests := []struct {
+		name      string
+		selectors []jsonxtractr.Selector
+		opts      []jsonxtractr.Option
+		want      string
+	}{
+		{name: "nested key", selectors: []jsonxtractr.Selector{"user.password"}, want: `{"user":{"name":"Alice","tokens":{"api":"k1","refresh":"k2"}},"price":1.50,"items":[10,20,30,40]}`},
+		{name: "replaced with null", selectors: []jsonxtractr.Selector{"user.password"}, opts: []jsonxtractr.Option{jsonxtractr.WithRedactAsNull()}, want: `{"user":{"name":"Alice","password":null,"tokens":{"api":"k1","refresh":"k2"}},"price":1.50,"items":[10,20,30,40]}`},
+		{name: "array element renumbers", selectors: []jsonxtractr.Selector{"items.1"}, want: `{"user":{"name":"Alice","password":"s3cret","tokens":{"api":"k1","refresh":"k2"}},"price":1.50,"items":[10,30,40]}`},
+		{name: "indexes refer to original", selectors: []jsonxtractr.Selector{"items.0", "items.1", "items.-1"}, want: `{"user":{"name":"Alice","password":"s3cret","tokens":{"api":"k1","refresh":"k2"}},"price":1.50,"items":[30]}`},
+		{name: "array element null", selectors: []jsonxtractr.Selector{"items.1"}, opts: []jsonxtractr.Option{jsonxtractr.WithRedactAsNull()}, want: `{"user":{"name":"Alice","password":"s3cret","tokens":{"api":"k1","refresh":"k2"}},"price":1.50,"items":[10,null,30,40]}`},
+		{name: "wildcard", selectors: []jsonxtractr.Selector{"user.tokens.*"}, want: `{"user":{"name":"Alice","password":"s3cret","tokens":{}},"price":1.50,"items":[10,20,30,40]}`},
+		{name: "whole subtree", selectors: []jsonxtractr.Selector{"user"}, want: `{"price":1.50,"items":[10,20,30,40]}`},
+		{name: "missing path is no-op", selectors: []jsonxtractr.Selector{"user.age", "nope.x", "items.9", "price.x"}, want: `{"user":{"name":"Alice","password":"s3cret","tokens":{"api":"k1","refresh":"k2"}},"price":1.50,"items":[10,20,30,40]}`},
+		{name: "root", selectors: []jsonxtractr.Selector{jsonxtractr.RootSelector}, want: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.RedactOpts(jsonData, tt.selectors, tt.opts...)
+			if err != nil {
+				t.Fatalf("RedactOpts() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RedactOpts() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Without options the values are removed
+	got, err := jsonxtractr.Redact([]byte(`{"a": 1, "b": 2}`), []jsonxtractr.Selector{"a"})
+	if err != nil || string(got) != `{"b":2}` {
+		t.Errorf("Redact() = %s, %v, want {\"b\":2}", got, err)
+	}
+}
+
+func TestRedact_Errors(t *testing.T) {
+	_, err := jsonxtractr.Redact(nil, []jsonxtractr.Selector{"a"})
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) {
+		t.Errorf("Redact() error = %v, want %v", err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+
+	_, err = jsonxtractr.Redact([]byte(`{"a": [1, 2}`), []jsonxtractr.Selector{"b"})
+	if !errors.Is(err, jsonxtractr.ErrJSONTokenReadFailed) {
+		t.Errorf("Redact() error = %v, want %v", err, jsonxtractr.ErrJSONTokenReadFailed)
+	}
+}
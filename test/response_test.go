@@ -0,0 +1,115 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// trackingBody is a response body that records whether it was closed.
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// newResponse returns a response with the given status, headers and body.
+func newResponse(status int, body []byte, header http.Header) (*http.Response, *trackingBody) {
+	tracked := &trackingBody{Reader: bytes.NewReader(body)}
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     header,
+		Body:       tracked,
+	}, tracked
+}
+
+func TestExtractValueFromResponse(t *testing.T) {
+	resp, body := newResponse(http.StatusOK, []byte(`{"data": {"id": 7, "name": "widget"}}`), nil)
+
+	value, err := jsonxtractr.ExtractValueFromResponse(resp, "data.name")
+	if err != nil {
+		t.Fatalf("ExtractValueFromResponse() error = %v", err)
+	}
+	if value != "widget" {
+		t.Errorf("ExtractValueFromResponse() = %v, want widget", value)
+	}
+	if !body.closed {
+		t.Error("ExtractValueFromResponse() left the body open")
+	}
+}
+
+func TestExtractValueFromResponse_Gzip(t *testing.T) {
+	compressed := gzipBytes(t, `{"data": {"id": 7}}`)
+	resp, body := newResponse(http.StatusOK, compressed, http.Header{"Content-Encoding": {"gzip"}})
+
+	value, err := jsonxtractr.ExtractValueFromResponse(resp, "data.id")
+	if err != nil {
+		t.Fatalf("ExtractValueFromResponse() error = %v", err)
+	}
+	if value != float64(7) {
+		t.Errorf("ExtractValueFromResponse() = %v, want 7", value)
+	}
+	if !body.closed {
+		t.Error("ExtractValueFromResponse() left the body open")
+	}
+
+	// A body claiming gzip that isn't fails as such
+	resp, _ = newResponse(http.StatusOK, []byte(`{"data": {"id": 7}}`), http.Header{"Content-Encoding": {"gzip"}})
+	_, err = jsonxtractr.ExtractValueFromResponse(resp, "data.id")
+	if !errors.Is(err, jsonxtractr.ErrJSONGzipReadFailed) {
+		t.Errorf("ExtractValueFromResponse() error = %v, want %v", err, jsonxtractr.ErrJSONGzipReadFailed)
+	}
+}
+
+func TestExtractValueFromResponse_Status(t *testing.T) {
+	page := "<html><body><h1>Internal Server Error</h1>" + strings.Repeat("<p>trace</p>", 100) + "</body></html>"
+	resp, body := newResponse(http.StatusInternalServerError, []byte(page), nil)
+
+	value, err := jsonxtractr.ExtractValueFromResponse(resp, "data.id")
+	if !errors.Is(err, jsonxtractr.ErrJSONHTTPStatus) {
+		t.Fatalf("ExtractValueFromResponse() error = %v, want %v", err, jsonxtractr.ErrJSONHTTPStatus)
+	}
+	if errors.Is(err, jsonxtractr.ErrJSONTokenReadFailed) {
+		t.Errorf("ExtractValueFromResponse() error = %v, want the body left unparsed", err)
+	}
+	if value != nil {
+		t.Errorf("ExtractValueFromResponse() = %v, want nil", value)
+	}
+	if code, _ := jsonxtractr.ErrValue[int](err, "status_code"); code != http.StatusInternalServerError {
+		t.Errorf("status_code = %d, want %d", code, http.StatusInternalServerError)
+	}
+	snippet, _ := jsonxtractr.ErrValue[string](err, "body_snippet")
+	if !strings.HasPrefix(snippet, "<html><body><h1>Internal Server Error") || len(snippet) > 200 {
+		t.Errorf("body_snippet = %q, want the start of the page", snippet)
+	}
+	if !body.closed {
+		t.Error("ExtractValueFromResponse() left the body open")
+	}
+
+	// Gzipped error pages are decompressed for the snippet
+	resp, _ = newResponse(http.StatusNotFound, gzipBytes(t, `{"error": "no such item"}`), http.Header{"Content-Encoding": {"gzip"}})
+	_, err = jsonxtractr.ExtractValueFromResponse(resp, "data.id")
+	if snippet, _ := jsonxtractr.ErrValue[string](err, "body_snippet"); snippet != `{"error": "no such item"}` {
+		t.Errorf("body_snippet = %q, want the decompressed body", snippet)
+	}
+}
+
+func TestExtractValueFromResponse_Nil(t *testing.T) {
+	_, err := jsonxtractr.ExtractValueFromResponse(nil, "a")
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) {
+		t.Errorf("ExtractValueFromResponse() error = %v, want %v", err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+}
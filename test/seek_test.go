@@ -0,0 +1,108 @@
+package test
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestSeekTo(t *testing.T) {
+	jsonData := `{"meta": {"count": 3}, "data": {"items": [{"id": 1}, {"id": 2}, {"id": 3}]}, "tail": true}`
+
+	decoder, err := jsonxtractr.SeekTo(strings.NewReader(jsonData), "data.items")
+	if err != nil {
+		t.Fatalf("SeekTo() error = %v", err)
+	}
+	if decoder.PeekKind() != '[' {
+		t.Fatalf("PeekKind() = %v, want '['", decoder.PeekKind())
+	}
+	if _, err = decoder.ReadToken(); err != nil {
+		t.Fatalf("ReadToken() error = %v", err)
+	}
+
+	var ids []int
+	for decoder.PeekKind() != ']' {
+		var item struct {
+			ID int `json:"id"`
+		}
+		err = jsonv2.UnmarshalDecode(decoder, &item)
+		if err != nil {
+			t.Fatalf("UnmarshalDecode() error = %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("elements = %v, want [1 2 3]", ids)
+	}
+
+	// The array's closing bracket is next
+	token, err := decoder.ReadToken()
+	if err != nil || token.Kind() != ']' {
+		t.Errorf("ReadToken() = %v, %v, want ']'", token, err)
+	}
+}
+
+func TestSeekTo_Values(t *testing.T) {
+	jsonData := `{"list": [10, 20, 30], "user": {"name": "Alice"}}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     string
+	}{
+		{name: "scalar", selector: "user.name", want: `"Alice"`},
+		{name: "negative index", selector: "list.-1", want: `30`},
+		{name: "root", selector: jsonxtractr.RootSelector, want: jsonData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder, err := jsonxtractr.SeekTo(strings.NewReader(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("SeekTo() error = %v", err)
+			}
+			var value jsontext.Value
+			value, err = decoder.ReadValue()
+			if err != nil {
+				t.Fatalf("ReadValue() error = %v", err)
+			}
+			if string(value) != tt.want {
+				t.Errorf("ReadValue() = %s, want %s", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeekTo_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "missing key", selector: "user.email", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "index past end", selector: "list.5", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "wildcard", selector: "list.*", wantErr: jsonxtractr.ErrJSONSelectorMultiMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder, err := jsonxtractr.SeekTo(strings.NewReader(`{"list": [1], "user": {}}`), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SeekTo() error = %v, want %v", err, tt.wantErr)
+			}
+			if decoder != nil {
+				t.Error("SeekTo() returned a decoder along with an error")
+			}
+		})
+	}
+
+	_, err := jsonxtractr.SeekTo(nil, "a")
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) {
+		t.Errorf("SeekTo(nil) error = %v, want %v", err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+}
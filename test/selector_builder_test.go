@@ -0,0 +1,110 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestSelectorBuilder(t *testing.T) {
+	tests := []struct {
+		name         string
+		builder      *jsonxtractr.SelectorBuilder
+		want         jsonxtractr.Selector
+		wantSegments []string
+	}{
+		{
+			name:         "keys and index",
+			builder:      jsonxtractr.NewSelectorBuilder().Key("user").Key("addresses").Index(0).Key("city"),
+			want:         "user.addresses.0.city",
+			wantSegments: []string{"user", "addresses", "0", "city"},
+		},
+		{
+			name:         "key containing a dot",
+			builder:      jsonxtractr.NewSelectorBuilder().Key("config").Key("app.name"),
+			want:         `config."app.name"`,
+			wantSegments: []string{"config", "app.name"},
+		},
+		{
+			name:         "keys needing quotes",
+			builder:      jsonxtractr.NewSelectorBuilder().Key("0").Key("*").Key(`say "hi"`).Key("").Key("$").Key("/x/").Key("#(a==1)"),
+			want:         `"0"."*"."say \"hi\"".""."$"."/x/"."#(a==1)"`,
+			wantSegments: []string{"0", "*", `say "hi"`, "", "$", "/x/", "#(a==1)"},
+		},
+		{
+			name:         "negative index",
+			builder:      jsonxtractr.NewSelectorBuilder().Key("items").Index(-1),
+			want:         "items.-1",
+			wantSegments: []string{"items", "-1"},
+		},
+		{
+			name:         "path",
+			builder:      jsonxtractr.NewSelectorBuilder().Key("a.b").Path(`c."d.e"`).Index(2),
+			want:         `"a.b".c."d.e".2`,
+			wantSegments: []string{"a.b", "c", "d.e", "2"},
+		},
+		{
+			name:         "empty",
+			builder:      jsonxtractr.NewSelectorBuilder(),
+			want:         jsonxtractr.RootSelector,
+			wantSegments: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.builder.Build()
+			if got != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+			if must := tt.builder.MustBuild(); must != got {
+				t.Errorf("MustBuild() = %s, want %s", must, got)
+			}
+
+			// The built selector round-trips through Segments
+			segments, err := got.Segments()
+			if err != nil {
+				t.Fatalf("Segments() error = %v", err)
+			}
+			if !reflect.DeepEqual(segments, tt.wantSegments) {
+				t.Errorf("Segments() = %#v, want %#v", segments, tt.wantSegments)
+			}
+		})
+	}
+}
+
+func TestSelectorBuilder_DeepPathExtracts(t *testing.T) {
+	jsonData := []byte(`{"a": {"b.c": [{"d": [[0, {"e": {"": {"f": "deep"}}}]]}]}}`)
+
+	b := jsonxtractr.NewSelectorBuilder().Key("a").Key("b.c").Index(0).Key("d").Index(-1).Index(1)
+	for _, key := range []string{"e", "", "f"} {
+		b = b.Key(key)
+	}
+
+	value, err := jsonxtractr.ExtractValueFromBytes(jsonData, b.Build())
+	if err != nil {
+		t.Fatalf("ExtractValueFromBytes(%s) error = %v", b.Build(), err)
+	}
+	if value != "deep" {
+		t.Errorf("ExtractValueFromBytes(%s) = %v, want deep", b.Build(), value)
+	}
+}
+
+func TestSelectorBuilder_MustBuildPanics(t *testing.T) {
+	b := jsonxtractr.NewSelectorBuilder().Key("a").Path(`b."c`)
+
+	if got := b.Build(); !strings.HasPrefix(string(got), "a.") || got.Validate() == nil {
+		t.Errorf("Build() = %s, want an invalid selector", got)
+	}
+
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, jsonxtractr.ErrJSONSelectorUnbalancedQuote) {
+			t.Errorf("MustBuild() panicked with %v, want %v", err, jsonxtractr.ErrJSONSelectorUnbalancedQuote)
+		}
+	}()
+	b.MustBuild()
+}
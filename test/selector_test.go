@@ -0,0 +1,689 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractValueFromBytes_QuotedSegments(t *testing.T) {
+	jsonData := `{
+		"a.b": {"c": 1},
+		"a": {"b": {"c": 2}},
+		"": {"x": "empty"},
+		"*": "star",
+		"1": "one",
+		"say\"hi\"": "quoted",
+		"back\\slash": "slash",
+		"items": [{"v.w": true}]
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{name: "dotted key", selector: `"a.b".c`, want: float64(1)},
+		{name: "unquoted dots still traverse", selector: "a.b.c", want: float64(2)},
+		{name: "quoted plain keys", selector: `"a"."b"."c"`, want: float64(2)},
+		{name: "empty key", selector: `"".x`, want: "empty"},
+		{name: "quoted star is a literal key", selector: `"*"`, want: "star"},
+		{name: "quoted number is an object key", selector: `"1"`, want: "one"},
+		{name: "escaped quotes", selector: `"say\"hi\""`, want: "quoted"},
+		{name: "unquoted key containing quotes", selector: `say"hi"`, want: "quoted"},
+		{name: "escaped backslash", selector: `"back\\slash"`, want: "slash"},
+		{name: "dotted key below index", selector: `items.0."v.w"`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValueFromBytes_QuotedSegmentErrors(t *testing.T) {
+	jsonData := `{"a.b": 1, "list": [1, 2]}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "unbalanced quote", selector: `"a.b`, wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote},
+		{name: "trailing escape", selector: `"a.b\"`, wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote},
+		{name: "text after closing quote", selector: `"a"b`, wantErr: jsonxtractr.ErrJSONSelectorInvalid},
+		{name: "quoted index on array", selector: `list."0"`, wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "quoted key not found", selector: `"a"`, wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytes([]byte(jsonData), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractValuesFromBytes_QuotedSegments(t *testing.T) {
+	jsonData := []byte(`{"a.b": {"c": 1}, "a": {"b": {"c": 2}}, "list": [1, 2]}`)
+	selectors := []jsonxtractr.Selector{`"a.b".c`, "a.b.c", `"a".b.c`, `list."0"`, `"bad`}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+
+	want := jsonxtractr.ValuesMap{
+		`"a.b".c`: float64(1),
+		"a.b.c":   float64(2),
+		`"a".b.c`: float64(2),
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromBytes() values = %v, want %v", values, want)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{`list."0"`}) {
+		t.Errorf("ExtractValuesFromBytes() notFound = %v, want [%s]", notFound, `list."0"`)
+	}
+	if !errors.Is(err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment) {
+		t.Errorf("ExtractValuesFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment)
+	}
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorUnbalancedQuote) {
+		t.Errorf("ExtractValuesFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorUnbalancedQuote)
+	}
+}
+
+func TestExtractMatches_QuotesDottedKeys(t *testing.T) {
+	jsonData := []byte(`{"hosts": {"example.com": 1, "local": 2, "7": 3}}`)
+
+	got, err := jsonxtractr.ExtractMatches(jsonData, "hosts.*")
+	if err != nil {
+		t.Fatalf("ExtractMatches() error = %v", err)
+	}
+
+	want := []jsonxtractr.Match{
+		{Path: `hosts."example.com"`, Value: float64(1)},
+		{Path: "hosts.local", Value: float64(2)},
+		{Path: `hosts."7"`, Value: float64(3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ExtractMatches() = %v, want %v", got, want)
+	}
+
+	// Every reported path selects its own value again
+	for _, match := range got {
+		value, err := jsonxtractr.ExtractValueFromBytes(jsonData, match.Path)
+		if err != nil {
+			t.Errorf("ExtractValueFromBytes(%s) error = %v", match.Path, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, match.Value) {
+			t.Errorf("ExtractValueFromBytes(%s) = %v, want %v", match.Path, value, match.Value)
+		}
+	}
+}
+
+func TestExtractValueFromBytes_EscapedDots(t *testing.T) {
+	jsonData := `{
+		"a.b": {"c": 1, "d.e": 3},
+		"a": {"b": {"c": 2}},
+		"back\\slash": "slash",
+		"*": "star",
+		"list": [{"x.y": [10, 20]}]
+	}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{name: "escaped dot", selector: `a\.b.c`, want: float64(1)},
+		{name: "unescaped dots", selector: `a.b.c`, want: float64(2)},
+		{name: "escaped dots in two segments", selector: `a\.b.d\.e`, want: float64(3)},
+		{name: "escaped backslash", selector: `back\\slash`, want: "slash"},
+		{name: "escaped star is a literal key", selector: `\*`, want: "star"},
+		{name: "escaped dot between indexes", selector: `list.0.x\.y.1`, want: float64(20)},
+		{name: "escaped and quoted agree", selector: `"a.b".d\.e`, want: float64(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes([]byte(jsonData), tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValueFromBytes_DanglingEscape(t *testing.T) {
+	jsonData := []byte(`{"a": {"b": 1}}`)
+
+	for _, selector := range []jsonxtractr.Selector{`a\`, `a.b\`, `\`} {
+		t.Run(string(selector), func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorDanglingEscape) {
+				t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorDanglingEscape)
+			}
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorInvalid) {
+				t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorInvalid)
+			}
+			if errors.Is(err, jsonxtractr.ErrJSONPathTraversalFailed) {
+				t.Errorf("ExtractValueFromBytes() error = %v, want a parse error before traversal", err)
+			}
+		})
+	}
+}
+
+func TestExtractValueFromBytes_RootSelector(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want any
+	}{
+		{name: "object", raw: `{"a": 1, "b": [true]}`, want: map[string]any{"a": float64(1), "b": []any{true}}},
+		{name: "array", raw: `[1, "two", null]`, want: []any{float64(1), "two", nil}},
+		{name: "scalar", raw: `"just a string"`, want: "just a string"},
+		{name: "number", raw: `42`, want: float64(42)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes([]byte(tt.raw), jsonxtractr.RootSelector)
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValuesFromBytes_RootSelector(t *testing.T) {
+	jsonData := []byte(`{"a": {"b": 1}, "$": "dollar"}`)
+	selectors := []jsonxtractr.Selector{"$", "a.b", `"$"`, `\$`}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+	if err != nil {
+		t.Fatalf("ExtractValuesFromBytes() error = %v", err)
+	}
+	if len(notFound) > 0 {
+		t.Errorf("ExtractValuesFromBytes() notFound = %v", notFound)
+	}
+	want := jsonxtractr.ValuesMap{
+		"$":   map[string]any{"a": map[string]any{"b": float64(1)}, "$": "dollar"},
+		"a.b": float64(1),
+		`"$"`: "dollar",
+		`\$`:  "dollar",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromBytes() = %v, want %v", values, want)
+	}
+}
+
+func TestRootSelector_EmptyStillErrors(t *testing.T) {
+	_, err := jsonxtractr.ExtractValueFromBytes([]byte(`{"a": 1}`), "")
+	if !errors.Is(err, jsonxtractr.ErrJSONValueSelectorCannotBeEmpty) {
+		t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONValueSelectorCannotBeEmpty)
+	}
+}
+
+func TestRootSelector_Document(t *testing.T) {
+	doc, err := jsonxtractr.NewDocument([]byte(`[1, 2]`))
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+	got, err := doc.Value(jsonxtractr.RootSelector)
+	if err != nil {
+		t.Fatalf("Document.Value() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []any{float64(1), float64(2)}) {
+		t.Errorf("Document.Value() = %v, want [1 2]", got)
+	}
+}
+
+func TestExtractMatches_RootSelector(t *testing.T) {
+	jsonData := []byte(`{"$": 1}`)
+
+	root, err := jsonxtractr.ExtractMatches(jsonData, jsonxtractr.RootSelector)
+	if err != nil {
+		t.Fatalf("ExtractMatches() error = %v", err)
+	}
+	if !reflect.DeepEqual(root, []jsonxtractr.Match{{Path: "$", Value: map[string]any{"$": float64(1)}}}) {
+		t.Errorf("ExtractMatches() = %v", root)
+	}
+
+	// A member literally named "$" is quoted so it isn't mistaken for the root
+	members, err := jsonxtractr.ExtractMatches(jsonData, "*")
+	if err != nil {
+		t.Fatalf("ExtractMatches() error = %v", err)
+	}
+	if !reflect.DeepEqual(members, []jsonxtractr.Match{{Path: `"$"`, Value: float64(1)}}) {
+		t.Errorf("ExtractMatches() = %v", members)
+	}
+}
+
+func TestSelector_Validate(t *testing.T) {
+	valid := []jsonxtractr.Selector{
+		"a",
+		"a.b.c",
+		"items.0.name",
+		"items.-1",
+		"users.*.name",
+		`items./^id_\d+$/.value`,
+		`"a.b".c`,
+		`a\.b.c`,
+		`""`,
+		jsonxtractr.RootSelector,
+		"a..b",
+		"..key",
+		"$..key",
+		"users..*",
+	}
+	for _, selector := range valid {
+		t.Run(string(selector), func(t *testing.T) {
+			err := selector.Validate()
+			if err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+
+	invalid := []struct {
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{selector: "", wantErr: jsonxtractr.ErrJSONValueSelectorCannotBeEmpty},
+		{selector: "a...b", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{selector: "a..", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{selector: "a..[0]", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{selector: ".a", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{selector: "a.", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{selector: `"a.b`, wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote},
+		{selector: `a.b\`, wantErr: jsonxtractr.ErrJSONSelectorDanglingEscape},
+		{selector: `"a"b`, wantErr: jsonxtractr.ErrJSONSelectorInvalid},
+		{selector: `items./id_(/.value`, wantErr: jsonxtractr.ErrJSONSelectorRegexInvalid},
+		{selector: `items./id_`, wantErr: jsonxtractr.ErrJSONSelectorRegexInvalid},
+		{selector: `items./id_/x`, wantErr: jsonxtractr.ErrJSONSelectorRegexInvalid},
+	}
+	for _, tt := range invalid {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			err := tt.selector.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorInvalid) {
+				t.Errorf("Validate() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorInvalid)
+			}
+
+			// Extraction rejects the selector for the same reason
+			_, err = jsonxtractr.ExtractValueFromBytes([]byte(`{"a": {"b": 1}}`), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelector_Segments(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     []string
+	}{
+		{name: "plain", selector: "a.b.0", want: []string{"a", "b", "0"}},
+		{name: "single", selector: "a", want: []string{"a"}},
+		{name: "quoted dotted key", selector: `"a.b".c`, want: []string{"a.b", "c"}},
+		{name: "quoted empty key", selector: `"".x`, want: []string{"", "x"}},
+		{name: "escaped quote in quotes", selector: `"say \"hi\""`, want: []string{`say "hi"`}},
+		{name: "escaped dot", selector: `a\.b.c`, want: []string{"a.b", "c"}},
+		{name: "escaped backslash", selector: `back\\slash`, want: []string{`back\slash`}},
+		{name: "wildcard", selector: "users.*.name", want: []string{"users", "*", "name"}},
+		{name: "root", selector: jsonxtractr.RootSelector, want: []string{}},
+		{name: "descent", selector: "users..email", want: []string{"users", "", "email"}},
+		{name: "descent from the root", selector: "..email", want: []string{"", "", "email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selector.Segments()
+			if err != nil {
+				t.Fatalf("Segments() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Segments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelector_SegmentsErrors(t *testing.T) {
+	tests := []struct {
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{selector: "", wantErr: jsonxtractr.ErrJSONValueSelectorCannotBeEmpty},
+		{selector: "a...b", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{selector: `"a.b`, wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote},
+		{selector: `a\`, wantErr: jsonxtractr.ErrJSONSelectorDanglingEscape},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			got, err := tt.selector.Segments()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Segments() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("Segments() = %q, want nil", got)
+			}
+		})
+	}
+}
+
+func TestSelector_HasWildcard(t *testing.T) {
+	jsonData := []byte(`{"users": [{"name": "Alice", "nick": "al", "role": "admin"}]}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     bool
+	}{
+		{name: "plain", selector: "users.0.name", want: false},
+		{name: "root", selector: jsonxtractr.RootSelector, want: false},
+		{name: "quoted star", selector: `users.0."*"`, want: false},
+		{name: "escaped star", selector: `users.0.\*`, want: false},
+		{name: "index list", selector: "users.[0,0]", want: false},
+		{name: "first-match filter", selector: `users.#(role=="admin").name`, want: false},
+		{name: "wildcard", selector: "users.*.name", want: true},
+		{name: "glob", selector: "users.0.n*", want: true},
+		{name: "regex", selector: "users.0./^n/", want: true},
+		{name: "all-matches filter", selector: `users.#(role=="admin")#.name`, want: true},
+		{name: "descent", selector: "users..name", want: true},
+		{name: "descent from the root", selector: "..name", want: true},
+		{name: "descent from the dollar root", selector: "$..name", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selector.HasWildcard()
+			if err != nil {
+				t.Fatalf("HasWildcard() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HasWildcard() = %v, want %v", got, tt.want)
+			}
+
+			// Single-value extraction rejects exactly the selectors it reports
+			_, err = jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+			if errors.Is(err, jsonxtractr.ErrJSONSelectorMultiMatch) != tt.want {
+				t.Errorf("ExtractValueFromBytes() error = %v, want ErrJSONSelectorMultiMatch %v", err, tt.want)
+			}
+		})
+	}
+
+	_, err := jsonxtractr.Selector("a...b").HasWildcard()
+	if !errors.Is(err, jsonxtractr.ErrJSONPathContainsEmptySegment) {
+		t.Errorf("HasWildcard() error = %v, want %v", err, jsonxtractr.ErrJSONPathContainsEmptySegment)
+	}
+}
+
+func TestSelector_Composition(t *testing.T) {
+	tests := []struct {
+		name string
+		got  jsonxtractr.Selector
+		want jsonxtractr.Selector
+	}{
+		{name: "child", got: jsonxtractr.Selector("a").Child("b"), want: "a.b"},
+		{name: "child with dot is quoted", got: jsonxtractr.Selector("hosts").Child("example.com"), want: `hosts."example.com"`},
+		{name: "numeric child is quoted", got: jsonxtractr.Selector("a").Child("0"), want: `a."0"`},
+		{name: "star child is quoted", got: jsonxtractr.Selector("a").Child("*"), want: `a."*"`},
+		{name: "child of root", got: jsonxtractr.RootSelector.Child("a"), want: "a"},
+		{name: "index", got: jsonxtractr.Selector("items").Index(2), want: "items.2"},
+		{name: "negative index", got: jsonxtractr.Selector("items").Index(-1), want: "items.-1"},
+		{name: "chained", got: jsonxtractr.RootSelector.Child("users").Index(0).Child("first name"), want: "users.0.first name"},
+		{name: "parent", got: jsonxtractr.Selector("a.b.c").Parent(), want: "a.b"},
+		{name: "parent of quoted", got: jsonxtractr.Selector(`a."b.c"`).Parent(), want: "a"},
+		{name: "parent keeps quoting", got: jsonxtractr.Selector(`"a.b".c`).Parent(), want: `"a.b"`},
+		{name: "parent of single segment", got: jsonxtractr.Selector("a").Parent(), want: jsonxtractr.RootSelector},
+		{name: "parent of root", got: jsonxtractr.RootSelector.Parent(), want: jsonxtractr.RootSelector},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelector_CompositionRoundTrip(t *testing.T) {
+	keys := []string{"a", "b.c", "", "*", "$", `say "hi"`, `back\slash`, "7", "#1"}
+
+	selector := jsonxtractr.RootSelector
+	for _, key := range keys {
+		selector = selector.Child(key)
+	}
+	selector = selector.Index(3)
+
+	got, err := selector.Segments()
+	if err != nil {
+		t.Fatalf("Segments() error = %v", err)
+	}
+	want := append(keys, "3")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Segments() = %q, want %q", got, want)
+	}
+
+	for range want {
+		selector = selector.Parent()
+	}
+	if selector != jsonxtractr.RootSelector {
+		t.Errorf("Parent() after removing every segment = %s, want %s", selector, jsonxtractr.RootSelector)
+	}
+}
+
+func TestSelector_ChildSelectsDottedKey(t *testing.T) {
+	jsonData := []byte(`{"hosts": {"example.com": {"port": 443}}}`)
+
+	selector := jsonxtractr.Selector("hosts").Child("example.com").Child("port")
+	got, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+	if err != nil {
+		t.Fatalf("ExtractValueFromBytes(%s) error = %v", selector, err)
+	}
+	if got != float64(443) {
+		t.Errorf("ExtractValueFromBytes(%s) = %v, want 443", selector, got)
+	}
+}
+
+func TestExtractValue_ObjectOrdinal(t *testing.T) {
+	jsonData := []byte(`{
+		"steps": {"fetch": {"ms": 12}, "parse": {"ms": 3}, "store": {"ms": 40}},
+		"empty": {},
+		"list": [{"a": 1}],
+		"#1": "literal"
+	}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     any
+		wantErr  error
+	}{
+		{name: "first entry", selector: "steps.#0.ms", want: float64(12)},
+		{name: "middle entry", selector: "steps.#1.ms", want: float64(3)},
+		{name: "last entry", selector: "steps.#2", want: map[string]any{"ms": float64(40)}},
+		{name: "root ordinal", selector: "#0.#2.ms", want: float64(40)},
+		{name: "leading zeros", selector: "steps.#01.ms", want: float64(3)},
+		{name: "after an index", selector: "list.0.#0", want: float64(1)},
+		{name: "quoted is a key", selector: `"#1"`, want: "literal"},
+		{name: "escaped is a key", selector: `\#1`, want: "literal"},
+		{name: "out of range", selector: "steps.#3", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "empty object", selector: "empty.#0", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "not an object", selector: "list.#0", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "not an ordinal", selector: "steps.#x", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+	}
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("ExtractValueFromBytes() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromBytes() = %v, want %v", got, tt.want)
+			}
+
+			// The streaming, multi-selector and indexed paths agree
+			got, err = jsonxtractr.ExtractValueFromReader(strings.NewReader(string(jsonData)), tt.selector)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromReader() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+
+			valuesMap, _, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{tt.selector, "steps.fetch.ms"})
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(valuesMap[tt.selector], tt.want) {
+				t.Errorf("ExtractValuesFromBytes() = %v, %v, want %v, %v", valuesMap[tt.selector], err, tt.want, tt.wantErr)
+			}
+			if valuesMap["steps.fetch.ms"] != float64(12) {
+				t.Errorf("ExtractValuesFromBytes() steps.fetch.ms = %v, want 12", valuesMap["steps.fetch.ms"])
+			}
+
+			got, err = doc.Value(tt.selector)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.Value() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractValue_ObjectOrdinalOutOfRange(t *testing.T) {
+	_, err := jsonxtractr.ExtractValueFromBytes([]byte(`{"o": {"a": 1, "b": 2}}`), "o.#5")
+
+	var pathErr *jsonxtractr.PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("errors.As(%v) found no *PathError", err)
+	}
+	if !reflect.DeepEqual(pathErr.AvailableKeys, []string{"a", "b"}) {
+		t.Errorf("PathError.AvailableKeys = %v, want [a b]", pathErr.AvailableKeys)
+	}
+	if !strings.Contains(err.Error(), "object_length=2") {
+		t.Errorf("error %q does not report object_length=2", err)
+	}
+}
+
+func TestExtractMatches_ObjectOrdinal(t *testing.T) {
+	jsonData := []byte(`{"runs": [
+		{"steps": {"fetch": 1, "parse": 2}},
+		{"steps": {}},
+		{"steps": {"load.all": 3}}
+	]}`)
+
+	got, err := jsonxtractr.ExtractMatches(jsonData, "runs.*.steps.#0")
+	if err != nil {
+		t.Fatalf("ExtractMatches() error = %v", err)
+	}
+	want := []jsonxtractr.Match{
+		{Path: "runs.0.steps.fetch", Value: float64(1)},
+		{Path: `runs.2.steps."load.all"`, Value: float64(3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractMatches() mismatch:\n  got:  %#v\n  want: %#v", got, want)
+	}
+}
+
+func TestExtractValue_BracketNotation(t *testing.T) {
+	jsonData := []byte(`{
+		"items": [{"name": "first", "tags": ["a", "b"]}, {"name": "second", "tags": ["c"]}],
+		"user": {"a.b": 1, "it's": 2, "x[0]": 3, "name": "alice"},
+		"grid": [[1, 2], [3, 4]]
+	}`)
+
+	tests := []struct {
+		name    string
+		bracket jsonxtractr.Selector
+		dotted  jsonxtractr.Selector
+	}{
+		{name: "index", bracket: "items[0].name", dotted: "items.0.name"},
+		{name: "negative index", bracket: "items[-1].name", dotted: "items.-1.name"},
+		{name: "single quoted key", bracket: "user['name']", dotted: "user.name"},
+		{name: "double quoted key", bracket: `user["name"]`, dotted: "user.name"},
+		{name: "key with dot", bracket: "user['a.b']", dotted: `user."a.b"`},
+		{name: "escaped quote", bracket: `user['it\'s']`, dotted: "user.it's"},
+		{name: "key with bracket", bracket: "user['x[0]']", dotted: `user."x[0]"`},
+		{name: "chained", bracket: "items[1]['tags'][0]", dotted: "items.1.tags.0"},
+		{name: "nested arrays", bracket: "grid[1][0]", dotted: "grid.1.0"},
+		{name: "leading bracket", bracket: "['user'].name", dotted: "user.name"},
+		{name: "after quoted", bracket: `"items"[0].name`, dotted: "items.0.name"},
+		{name: "index list", bracket: "items[1,0]", dotted: "items.[1,0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.bracket)
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytes(%q) error = %v", tt.bracket, err)
+			}
+			want, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.dotted)
+			if err != nil {
+				t.Fatalf("ExtractValueFromBytes(%q) error = %v", tt.dotted, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ExtractValueFromBytes(%q) = %v, want %v", tt.bracket, got, want)
+			}
+
+			// The multi-selector path agrees with the single-selector one
+			values, _, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{tt.bracket, tt.dotted})
+			if err != nil {
+				t.Fatalf("ExtractValuesFromBytes() error = %v", err)
+			}
+			if !reflect.DeepEqual(values[tt.bracket], values[tt.dotted]) {
+				t.Errorf("ExtractValuesFromBytes() = %v, want %v", values[tt.bracket], values[tt.dotted])
+			}
+		})
+	}
+}
+
+func TestSelector_BracketInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+	}{
+		{name: "empty", selector: "items[]"},
+		{name: "wildcard", selector: "items[*]"},
+		{name: "unquoted key", selector: "user[name]"},
+		{name: "missing closing bracket", selector: "items[0"},
+		{name: "unclosed quote", selector: "user['name]"},
+		{name: "text after quote", selector: "user['name'x]"},
+		{name: "text after bracket", selector: "items[0]name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.selector.Validate()
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorBracketInvalid) {
+				t.Errorf("Validate() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorBracketInvalid)
+			}
+			if !errors.Is(err, jsonxtractr.ErrJSONSelectorInvalid) {
+				t.Errorf("Validate() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestSetValue(t *testing.T) {
+	jsonData := []byte(`{
+	"id": 7,
+	"user": {"name": "Alice", "tags": ["a", "b"]},
+	"price": 1.50
+}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		value    any
+		want     string
+	}{
+		{name: "scalar", selector: "id", value: 8, want: `{
+	"id": 8,
+	"user": {"name": "Alice", "tags": ["a", "b"]},
+	"price": 1.50
+}`},
+		{name: "nested scalar", selector: "user.name", value: "Bob", want: `{
+	"id": 7,
+	"user": {"name": "Bob", "tags": ["a", "b"]},
+	"price": 1.50
+}`},
+		{name: "array element", selector: "user.tags.-1", value: nil, want: `{
+	"id": 7,
+	"user": {"name": "Alice", "tags": ["a", null]},
+	"price": 1.50
+}`},
+		{name: "subtree", selector: "user", value: map[string]any{"name": "Carol"}, want: `{
+	"id": 7,
+	"user": {"name":"Carol"},
+	"price": 1.50
+}`},
+		{name: "scalar with subtree", selector: "price", value: []int{1, 2}, want: `{
+	"id": 7,
+	"user": {"name": "Alice", "tags": ["a", "b"]},
+	"price": [1,2]
+}`},
+		{name: "root", selector: jsonxtractr.RootSelector, value: true, want: `true`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.SetValue(jsonData, tt.selector, tt.value)
+			if err != nil {
+				t.Fatalf("SetValue() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SetValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetValue_CreateMissing(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		selector jsonxtractr.Selector
+		want     string
+	}{
+		{name: "key", json: `{"a": 1}`, selector: "b", want: `{"a": 1,"b":"x"}`},
+		{name: "key in empty object", json: `{ }`, selector: "b", want: `{"b":"x" }`},
+		{name: "intermediate objects", json: `{"a": {"b": 1}}`, selector: "a.c.d", want: `{"a": {"b": 1,"c":{"d":"x"}}}`},
+		{name: "quoted key", json: `{}`, selector: `"a.b"`, want: `{"a.b":"x"}`},
+		{name: "append element", json: `{"a": [1, 2]}`, selector: "a.2", want: `{"a": [1, 2,"x"]}`},
+		{name: "padded element", json: "[\n  1\n]", selector: "3", want: "[\n  1,null,null,\"x\"\n]"},
+		{name: "element of empty array", json: `{"a": []}`, selector: "a.0.b", want: `{"a": [{"b":"x"}]}`},
+		{name: "new array", json: `{}`, selector: "a.1", want: `{"a":[null,"x"]}`},
+		{name: "padding at the limit", json: `[]`, selector: "1024", want: "[" + strings.Repeat("null,", 1024) + `"x"]`},
+		{name: "existing value", json: `{"a": {"b": 1}}`, selector: "a.b", want: `{"a": {"b": "x"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.SetValueOpts([]byte(tt.json), tt.selector, "x", jsonxtractr.WithCreateMissing())
+			if err != nil {
+				t.Fatalf("SetValueOpts() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SetValueOpts() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetValue_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		selector jsonxtractr.Selector
+		opts     []jsonxtractr.Option
+		wantErr  error
+	}{
+		{name: "missing key", json: `{"a": 1}`, selector: "b", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "missing intermediate", json: `{"a": {}}`, selector: "a.b.c", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "index past end", json: `[1]`, selector: "1", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "negative index past start", json: `[1]`, selector: "-2", opts: []jsonxtractr.Option{jsonxtractr.WithCreateMissing()}, wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "key on scalar", json: `{"a": 1}`, selector: "a.b", opts: []jsonxtractr.Option{jsonxtractr.WithCreateMissing()}, wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{name: "filter not created", json: `[]`, selector: `#(id==1)`, opts: []jsonxtractr.Option{jsonxtractr.WithCreateMissing()}, wantErr: jsonxtractr.ErrJSONSelectorNotFound},
+		{name: "index far past end", json: `{"a": [1]}`, selector: "a.1000000000", opts: []jsonxtractr.Option{jsonxtractr.WithCreateMissing()}, wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "index far into new array", json: `{}`, selector: "a.1000000000", opts: []jsonxtractr.Option{jsonxtractr.WithCreateMissing()}, wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "index far into nested new array", json: `{}`, selector: "a.b.0.1025", opts: []jsonxtractr.Option{jsonxtractr.WithCreateMissing()}, wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "wildcard", json: `{"a": 1}`, selector: "*", wantErr: jsonxtractr.ErrJSONSelectorMultiMatch},
+		{name: "index list", json: `[1, 2]`, selector: "[0,1]", wantErr: jsonxtractr.ErrJSONSelectorMultiMatch},
+		{name: "empty body", json: ``, selector: "a", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.SetValueOpts([]byte(tt.json), tt.selector, "x", tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetValueOpts() error = %v, want %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, jsonxtractr.ErrFailedToSetValueInJSON) {
+				t.Errorf("SetValueOpts() error = %v, want %v", err, jsonxtractr.ErrFailedToSetValueInJSON)
+			}
+			if errors.Is(err, jsonxtractr.ErrFailedToExtractValueFromJSON) {
+				t.Errorf("SetValueOpts() error = %v, should not be %v", err, jsonxtractr.ErrFailedToExtractValueFromJSON)
+			}
+		})
+	}
+
+	_, err := jsonxtractr.SetValue([]byte(`{"a": 1}`), "a", func() {})
+	if !errors.Is(err, jsonxtractr.ErrJSONMarshalFailed) {
+		t.Errorf("SetValue() error = %v, want %v", err, jsonxtractr.ErrJSONMarshalFailed)
+	}
+}
+
+func TestAppendValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		selector jsonxtractr.Selector
+		value    any
+		want     string
+	}{
+		{name: "nonempty array", json: `{"tags": ["a", "b"], "n": 1}`, selector: "tags", value: "c", want: `{"tags": ["a", "b", "c"], "n": 1}`},
+		{name: "compact array", json: `[1,2]`, selector: jsonxtractr.RootSelector, value: 3, want: `[1,2,3]`},
+		{name: "one element per line", json: "{\n  \"a\": [\n    1,\n    2\n  ]\n}", selector: "a", value: 3, want: "{\n  \"a\": [\n    1,\n    2,\n    3\n  ]\n}"},
+		{name: "empty array", json: `{"a": {"list": []}}`, selector: "a.list", value: map[string]int{"id": 1}, want: `{"a": {"list": [{"id":1}]}}`},
+		{name: "empty array with whitespace", json: `[ ]`, selector: jsonxtractr.RootSelector, value: true, want: `[true ]`},
+		{name: "nested array element", json: `[[1], [2]]`, selector: "-1", value: nil, want: `[[1], [2,null]]`},
+		{name: "array of strings with brackets", json: `["]", "[,"]`, selector: jsonxtractr.RootSelector, value: "x", want: `["]", "[,", "x"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.AppendValue([]byte(tt.json), tt.selector, tt.value)
+			if err != nil {
+				t.Fatalf("AppendValue() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("AppendValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendValue_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "object", json: `{"a": {"b": 1}}`, selector: "a", wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment},
+		{name: "scalar", json: `{"a": "x"}`, selector: "a", wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment},
+		{name: "missing key", json: `{"a": []}`, selector: "b", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "empty body", json: ``, selector: "a", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.AppendValue([]byte(tt.json), tt.selector, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AppendValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, jsonxtractr.ErrFailedToSetValueInJSON) {
+				t.Errorf("AppendValue() error = %v, want %v", err, jsonxtractr.ErrFailedToSetValueInJSON)
+			}
+		})
+	}
+}
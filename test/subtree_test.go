@@ -0,0 +1,117 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestExtractSubtree(t *testing.T) {
+	jsonData := []byte(`{
+		"user": {"name": "Alice", "age": 30, "address": {"city": "Paris", "zip": "75001"}},
+		"items": [{"id": 1}, {"id": 2}],
+		"active": true
+	}`)
+
+	tests := []struct {
+		name      string
+		selectors []jsonxtractr.Selector
+		want      map[string]any
+	}{
+		{
+			name:      "disjoint paths",
+			selectors: []jsonxtractr.Selector{"active", "user.name"},
+			want:      map[string]any{"active": true, "user": map[string]any{"name": "Alice"}},
+		},
+		{
+			name:      "nested paths",
+			selectors: []jsonxtractr.Selector{"user.name", "user.age", "user.address.city"},
+			want: map[string]any{"user": map[string]any{
+				"name":    "Alice",
+				"age":     float64(30),
+				"address": map[string]any{"city": "Paris"},
+			}},
+		},
+		{
+			name:      "whole object",
+			selectors: []jsonxtractr.Selector{"user.address"},
+			want:      map[string]any{"user": map[string]any{"address": map[string]any{"city": "Paris", "zip": "75001"}}},
+		},
+		{
+			name:      "array indexes",
+			selectors: []jsonxtractr.Selector{"items.1.id", "items[0].id"},
+			want:      map[string]any{"items": map[string]any{"0": map[string]any{"id": float64(1)}, "1": map[string]any{"id": float64(2)}}},
+		},
+		{
+			name:      "root",
+			selectors: []jsonxtractr.Selector{jsonxtractr.RootSelector},
+			want: map[string]any{
+				"user":   map[string]any{"name": "Alice", "age": float64(30), "address": map[string]any{"city": "Paris", "zip": "75001"}},
+				"items":  []any{map[string]any{"id": float64(1)}, map[string]any{"id": float64(2)}},
+				"active": true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notFound, err := jsonxtractr.ExtractSubtree(jsonData, tt.selectors)
+			if err != nil || len(notFound) > 0 {
+				t.Fatalf("ExtractSubtree() notFound = %v, error = %v", notFound, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractSubtree() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractSubtree_NotFound(t *testing.T) {
+	got, notFound, err := jsonxtractr.ExtractSubtree([]byte(`{"a": {"b": 1}}`), []jsonxtractr.Selector{"a.b", "a.c"})
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractSubtree() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"a.c"}) {
+		t.Errorf("ExtractSubtree() notFound = %v, want [a.c]", notFound)
+	}
+	want := map[string]any{"a": map[string]any{"b": float64(1)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractSubtree() = %#v, want %#v", got, want)
+	}
+}
+
+func TestExtractSubtree_Conflict(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice"}, "n": 1}`)
+
+	tests := []struct {
+		name      string
+		selectors []jsonxtractr.Selector
+		wantErr   error
+	}{
+		{name: "scalar then deeper", selectors: []jsonxtractr.Selector{"n", "n.x"}, wantErr: jsonxtractr.ErrJSONSelectorConflict},
+		{name: "deeper then parent", selectors: []jsonxtractr.Selector{"user.name", "user"}, wantErr: jsonxtractr.ErrJSONSelectorConflict},
+		{name: "root with another", selectors: []jsonxtractr.Selector{"n", jsonxtractr.RootSelector}, wantErr: jsonxtractr.ErrJSONSelectorConflict},
+		{name: "same path spelled differently", selectors: []jsonxtractr.Selector{"user.name", `"user".name`}, wantErr: nil},
+		{name: "filter", selectors: []jsonxtractr.Selector{`user.#(name=="Alice")`}, wantErr: jsonxtractr.ErrJSONSelectorInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := jsonxtractr.ExtractSubtree(jsonData, tt.selectors)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("ExtractSubtree() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && got != nil {
+				t.Errorf("ExtractSubtree() = %#v, want nil", got)
+			}
+		})
+	}
+
+	// A root value that is no object has nothing to nest
+	_, _, err := jsonxtractr.ExtractSubtree([]byte(`[1]`), []jsonxtractr.Selector{jsonxtractr.RootSelector})
+	if !errors.Is(err, jsonxtractr.ErrJSONSelectorConflict) {
+		t.Errorf("ExtractSubtree() error = %v, want %v", err, jsonxtractr.ErrJSONSelectorConflict)
+	}
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestTypeOf(t *testing.T) {
+	jsonData := []byte(`{
+		"user": {"name": "Alice", "age": 30, "active": true, "manager": null},
+		"tags": ["a", "b"],
+		"ratio": -1.5e3,
+		"deleted": false
+	}`)
+
+	tests := []struct {
+		selector jsonxtractr.Selector
+		want     jsonxtractr.Kind
+	}{
+		{selector: jsonxtractr.RootSelector, want: jsonxtractr.KindObject},
+		{selector: "user", want: jsonxtractr.KindObject},
+		{selector: "tags", want: jsonxtractr.KindArray},
+		{selector: "user.name", want: jsonxtractr.KindString},
+		{selector: "tags.0", want: jsonxtractr.KindString},
+		{selector: "user.age", want: jsonxtractr.KindNumber},
+		{selector: "ratio", want: jsonxtractr.KindNumber},
+		{selector: "user.active", want: jsonxtractr.KindBool},
+		{selector: "deleted", want: jsonxtractr.KindBool},
+		{selector: "user.manager", want: jsonxtractr.KindNull},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			got, err := jsonxtractr.TypeOf(jsonData, tt.selector)
+			if err != nil {
+				t.Fatalf("TypeOf() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TypeOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeOf_Errors(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice"}, "tags": ["a"], "bad": @}`)
+
+	tests := []struct {
+		name     string
+		raw      []byte
+		selector jsonxtractr.Selector
+		wantErr  error
+	}{
+		{name: "missing key", raw: jsonData, selector: "user.email", wantErr: jsonxtractr.ErrJSONPathSegmentNotFound},
+		{name: "index out of range", raw: jsonData, selector: "tags.3", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{name: "malformed value", raw: jsonData, selector: "bad", wantErr: jsonxtractr.ErrJSONTokenReadFailed},
+		{name: "empty body", raw: nil, selector: "user", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.TypeOf(tt.raw, tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TypeOf() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != jsonxtractr.KindInvalid {
+				t.Errorf("TypeOf() = %v, want %v", got, jsonxtractr.KindInvalid)
+			}
+		})
+	}
+}
+
+func TestKind_String(t *testing.T) {
+	if got := jsonxtractr.KindNull.String(); got != "null" {
+		t.Errorf("KindNull.String() = %q, want %q", got, "null")
+	}
+	if got := jsonxtractr.KindInvalid.String(); got != "invalid" {
+		t.Errorf("KindInvalid.String() = %q, want %q", got, "invalid")
+	}
+}
+
+func TestExtractValueTyped(t *testing.T) {
+	jsonData := []byte(`{"obj": {"a": 1}, "arr": [1], "str": "s", "num": 2.5, "yes": true, "no": false, "nil": null}`)
+
+	tests := []struct {
+		selector jsonxtractr.Selector
+		expect   jsonxtractr.Kind
+		want     any
+	}{
+		{selector: "obj", expect: jsonxtractr.KindObject, want: map[string]any{"a": float64(1)}},
+		{selector: "arr", expect: jsonxtractr.KindArray, want: []any{float64(1)}},
+		{selector: "str", expect: jsonxtractr.KindString, want: "s"},
+		{selector: "num", expect: jsonxtractr.KindNumber, want: 2.5},
+		{selector: "yes", expect: jsonxtractr.KindBool, want: true},
+		{selector: "no", expect: jsonxtractr.KindBool, want: false},
+		{selector: "nil", expect: jsonxtractr.KindNull, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueTyped(jsonData, tt.selector, tt.expect)
+			if err != nil {
+				t.Fatalf("ExtractValueTyped() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueTyped() = %v, want %v", got, tt.want)
+			}
+
+			// Any other kind is a mismatch
+			other := jsonxtractr.KindString
+			if tt.expect == jsonxtractr.KindString {
+				other = jsonxtractr.KindNumber
+			}
+			got, err = jsonxtractr.ExtractValueTyped(jsonData, tt.selector, other)
+			if !errors.Is(err, jsonxtractr.ErrJSONTypeMismatch) || got != nil {
+				t.Errorf("ExtractValueTyped(%s) = %v, %v, want %v", other, got, err, jsonxtractr.ErrJSONTypeMismatch)
+			}
+		})
+	}
+
+	_, err := jsonxtractr.ExtractValueTyped(jsonData, "str", jsonxtractr.KindNumber)
+	for _, meta := range []string{"expected_type=number", "actual_type=string"} {
+		if !strings.Contains(err.Error(), meta) {
+			t.Errorf("ExtractValueTyped() error = %v, want it to report %s", err, meta)
+		}
+	}
+
+	_, err = jsonxtractr.ExtractValueTyped(jsonData, "missing", jsonxtractr.KindNumber)
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValueTyped() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+}
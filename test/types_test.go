@@ -0,0 +1,100 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+func TestSelectors_Unique(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors jsonxtractr.Selectors
+		want      jsonxtractr.Selectors
+	}{
+		{name: "no duplicates", selectors: jsonxtractr.Selectors{"a", "b", "c"}, want: jsonxtractr.Selectors{"a", "b", "c"}},
+		{name: "adjacent duplicates", selectors: jsonxtractr.Selectors{"a", "a", "b"}, want: jsonxtractr.Selectors{"a", "b"}},
+		{name: "keeps first-seen order", selectors: jsonxtractr.Selectors{"b", "a", "b", "c", "a"}, want: jsonxtractr.Selectors{"b", "a", "c"}},
+		{name: "empty", selectors: jsonxtractr.Selectors{}, want: jsonxtractr.Selectors{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := slices.Clone(tt.selectors)
+			got := tt.selectors.Unique()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unique() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.selectors, original) {
+				t.Errorf("Unique() modified its receiver to %v", tt.selectors)
+			}
+		})
+	}
+}
+
+func TestSelectors_Validate(t *testing.T) {
+	selectors := jsonxtractr.Selectors{
+		"user.name",
+		`"unbalanced`,
+		"items[0]['id']",
+		"a...b",
+		"/[/",
+		jsonxtractr.RootSelector,
+	}
+
+	err := selectors.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want an error")
+	}
+	for _, want := range []error{
+		jsonxtractr.ErrJSONSelectorInvalid,
+		jsonxtractr.ErrJSONSelectorUnbalancedQuote,
+		jsonxtractr.ErrJSONPathContainsEmptySegment,
+		jsonxtractr.ErrJSONSelectorRegexInvalid,
+	} {
+		if !errors.Is(err, want) {
+			t.Errorf("Validate() error = %v, want it to wrap %v", err, want)
+		}
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Validate() error = %T, want one combining several", err)
+	}
+	var indexes []int
+	for _, invalid := range joined.Unwrap() {
+		index, ok := jsonxtractr.ErrValue[int](invalid, "selector_index")
+		if !ok {
+			t.Errorf("error %v has no selector_index", invalid)
+			continue
+		}
+		selector, _ := jsonxtractr.ErrValue[jsonxtractr.Selector](invalid, "selector")
+		if selector != selectors[index] {
+			t.Errorf("error at selector_index %d names selector %q, want %q", index, selector, selectors[index])
+		}
+		indexes = append(indexes, index)
+	}
+	if !reflect.DeepEqual(indexes, []int{1, 3, 4}) {
+		t.Errorf("selector_index values = %v, want [1 3 4]", indexes)
+	}
+
+	// A single invalid selector is reported on its own
+	err = jsonxtractr.Selectors{"ok", "a..b", "a..."}.Validate()
+	if index, _ := jsonxtractr.ErrValue[int](err, "selector_index"); index != 2 || !errors.Is(err, jsonxtractr.ErrJSONPathContainsEmptySegment) {
+		t.Errorf("Validate() error = %v, want selector_index 2 wrapping %v", err, jsonxtractr.ErrJSONPathContainsEmptySegment)
+	}
+	err = jsonxtractr.Selectors{"ok", "a.."}.Validate()
+	if index, _ := jsonxtractr.ErrValue[int](err, "selector_index"); index != 1 || !errors.Is(err, jsonxtractr.ErrJSONPathContainsEmptySegment) {
+		t.Errorf("Validate() error = %v, want selector_index 1 wrapping %v", err, jsonxtractr.ErrJSONPathContainsEmptySegment)
+	}
+
+	if err = (jsonxtractr.Selectors{"a", "b.0", "..key", jsonxtractr.RootSelector}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err = jsonxtractr.Selectors(nil).Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
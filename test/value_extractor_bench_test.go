@@ -0,0 +1,316 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// largeDocument builds an object of roughly targetSize bytes made of records
+// keyed "r0", "r1", ... and returns it with the number of records written.
+func largeDocument(targetSize int) (doc []byte, records int) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for buf.Len() < targetSize {
+		if records > 0 {
+			buf.WriteString(",")
+		}
+		_, _ = fmt.Fprintf(&buf,
+			`"r%d":{"id":%d,"name":"record-%d","tags":["a","b","c"],"meta":{"score":%d.5,"active":true}}`,
+			records, records, records, records%100,
+		)
+		records++
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), records
+}
+
+// largeArray builds an object whose "xs" member is an array of n records
+// shaped like those of largeDocument, so index i selects the record with id i.
+func largeArray(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"xs":[`)
+	for i := range n {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		_, _ = fmt.Fprintf(&buf,
+			`{"id":%d,"name":"record-%d","tags":["a","b","c"],"meta":{"score":%d.5,"active":true}}`,
+			i, i, i%100,
+		)
+	}
+	buf.WriteString("]}")
+	return buf.Bytes()
+}
+
+// deepDocument builds objects nested depth levels deep, each with a few
+// sibling members ahead of the next level, and returns it with the selector
+// of the innermost value.
+func deepDocument(depth int) (doc []byte, selector jsonxtractr.Selector) {
+	var buf bytes.Buffer
+	path := make([]string, 0, depth+1)
+	for i := range depth {
+		_, _ = fmt.Fprintf(&buf, `{"id":%d,"name":"level-%d","tags":["a","b"],"l%d":`, i, i, i)
+		path = append(path, fmt.Sprintf("l%d", i))
+	}
+	buf.WriteString(`{"value":"bottom"}`)
+	path = append(path, "value")
+	buf.WriteString(strings.Repeat("}", depth))
+	return buf.Bytes(), jsonxtractr.Selector(strings.Join(path, "."))
+}
+
+// spreadSelectors returns n selectors spread evenly across the records of a
+// document produced by largeDocument.
+func spreadSelectors(records, n int) []jsonxtractr.Selector {
+	selectors := make([]jsonxtractr.Selector, n)
+	for i := range selectors {
+		record := i * records / n
+		switch i % 3 {
+		case 0:
+			selectors[i] = jsonxtractr.Selector(fmt.Sprintf("r%d.name", record))
+		case 1:
+			selectors[i] = jsonxtractr.Selector(fmt.Sprintf("r%d.meta.score", record))
+		default:
+			selectors[i] = jsonxtractr.Selector(fmt.Sprintf("r%d.tags.2", record))
+		}
+	}
+	return selectors
+}
+
+// BenchmarkExtractValues_1MB_50Selectors compares resolving 50 selectors one
+// at a time (a fresh decoder walk per selector) against the single-pass walk.
+func BenchmarkExtractValues_1MB_50Selectors(b *testing.B) {
+	doc, records := largeDocument(1 << 20)
+	selectors := spreadSelectors(records, 50)
+
+	b.Run("per_selector", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		for b.Loop() {
+			for _, selector := range selectors {
+				_, err := jsonxtractr.ExtractValueFromBytes(doc, selector)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("single_pass", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		for b.Loop() {
+			_, notFound, err := jsonxtractr.ExtractValuesFromBytes(doc, selectors)
+			if err != nil || len(notFound) > 0 {
+				b.Fatal(err, notFound)
+			}
+		}
+	})
+}
+
+// BenchmarkExtractValueFromReader_EarlyKey extracts a key near the top of a
+// large document, which streaming resolves without reading the rest.
+func BenchmarkExtractValueFromReader_EarlyKey(b *testing.B) {
+	doc, _ := largeDocument(4 << 20)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err := jsonxtractr.ExtractValueFromReader(bytes.NewReader(doc), "r0.name")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkExtractValuesFromReader_Pooled reads a modest document repeatedly,
+// the hot-loop case the buffer pools exist for.
+func BenchmarkExtractValuesFromReader_Pooled(b *testing.B) {
+	doc, records := largeDocument(64 << 10)
+	selectors := spreadSelectors(records, 10)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(doc)))
+	for b.Loop() {
+		_, notFound, err := jsonxtractr.ExtractValuesFromReader(bytes.NewReader(doc), selectors)
+		if err != nil || len(notFound) > 0 {
+			b.Fatal(err, notFound)
+		}
+	}
+}
+
+// BenchmarkExtractValues_Concurrency resolves many selectors split across
+// different numbers of goroutines.
+func BenchmarkExtractValues_Concurrency(b *testing.B) {
+	doc, records := largeDocument(1 << 20)
+	selectors := spreadSelectors(records, 200)
+
+	for _, n := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				_, notFound, err := jsonxtractr.ExtractValuesFromBytesOpts(doc, selectors, jsonxtractr.WithConcurrency(n))
+				if err != nil || len(notFound) > 0 {
+					b.Fatal(err, notFound)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkExtractValue_LateArrayIndex selects an element near the end of a
+// large array, which means skipping every element ahead of it.
+func BenchmarkExtractValue_LateArrayIndex(b *testing.B) {
+	doc := largeArray(10000)
+
+	for _, selector := range []jsonxtractr.Selector{"xs.9999.name", "xs.-1.name"} {
+		b.Run(string(selector), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				value, err := jsonxtractr.ExtractValueFromBytes(doc, selector)
+				if err != nil || value != "record-9999" {
+					b.Fatal(value, err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkExtractValues_ManySelectors resolves growing numbers of selectors
+// from one large object in a single pass.
+func BenchmarkExtractValues_ManySelectors(b *testing.B) {
+	doc, records := largeDocument(1 << 20)
+
+	for _, n := range []int{10, 100, 1000} {
+		selectors := spreadSelectors(records, n)
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				_, notFound, err := jsonxtractr.ExtractValuesFromBytes(doc, selectors)
+				if err != nil || len(notFound) > 0 {
+					b.Fatal(err, notFound)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkExtractValue_DeepPath follows a selector through many levels of
+// nested objects.
+func BenchmarkExtractValue_DeepPath(b *testing.B) {
+	for _, depth := range []int{10, 100, 500} {
+		doc, selector := deepDocument(depth)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				value, err := jsonxtractr.ExtractValueFromBytes(doc, selector)
+				if err != nil || value != "bottom" {
+					b.Fatal(value, err)
+				}
+			}
+		})
+	}
+}
+
+// TestExtractValue_LateArrayIndexAllocs guards against skipping array
+// elements starting to allocate per element: reaching the last of 10000
+// elements must cost about what reaching the last of 10 does.
+func TestExtractValue_LateArrayIndexAllocs(t *testing.T) {
+	allocs := func(n int) float64 {
+		doc := largeArray(n)
+		selector := jsonxtractr.Selector(fmt.Sprintf("xs.%d.name", n-1))
+		return testing.AllocsPerRun(10, func() {
+			_, err := jsonxtractr.ExtractValueFromBytes(doc, selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	small, large := allocs(10), allocs(10000)
+	if large > small+10 {
+		t.Errorf("allocs for index 9999 = %v, want about the %v for index 9", large, small)
+	}
+}
+
+// BenchmarkExtractValue_SkipStrategy compares decoding each skipped element
+// with WithFastSkip's structural scan on an array of large objects.
+func BenchmarkExtractValue_SkipStrategy(b *testing.B) {
+	record, _ := largeDocument(64 << 10)
+	doc := []byte(`{"xs":[` + strings.Repeat(string(record)+",", 63) + `{"name":"last"}]}`)
+
+	for _, bm := range []struct {
+		name string
+		opts []jsonxtractr.Option
+	}{
+		{name: "decode"},
+		{name: "fast_skip", opts: []jsonxtractr.Option{jsonxtractr.WithFastSkip()}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				value, err := jsonxtractr.ExtractValueFromBytesOpts(doc, "xs.63.name", bm.opts...)
+				if err != nil || value != "last" {
+					b.Fatal(value, err)
+				}
+			}
+		})
+	}
+}
+
+// siblingDocument returns a document whose "user" object, preceded by a large
+// unrelated member, holds n fields, along with a selector for each field.
+func siblingDocument(n int) (doc []byte, selectors []jsonxtractr.Selector) {
+	var sb strings.Builder
+
+	filler, _ := largeDocument(1 << 18)
+	sb.WriteString(`{"before": `)
+	sb.Write(filler)
+	sb.WriteString(`, "user": {`)
+	for i := range n {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `"field%d": "value %d"`, i, i)
+		selectors = append(selectors, jsonxtractr.Selector(fmt.Sprintf("user.field%d", i)))
+	}
+	sb.WriteString(`}}`)
+	return []byte(sb.String()), selectors
+}
+
+// BenchmarkExtractValues_SiblingSelectors resolves many selectors under one
+// parent, which the single pass navigates to once rather than once for each.
+func BenchmarkExtractValues_SiblingSelectors(b *testing.B) {
+	doc, selectors := siblingDocument(50)
+
+	b.Run("per_selector", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		for b.Loop() {
+			for _, selector := range selectors {
+				_, err := jsonxtractr.ExtractValueFromBytes(doc, selector)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("shared_parent", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		for b.Loop() {
+			_, notFound, err := jsonxtractr.ExtractValuesFromBytes(doc, selectors)
+			if err != nil || len(notFound) > 0 {
+				b.Fatal(err, notFound)
+			}
+		}
+	})
+}
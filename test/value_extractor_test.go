@@ -1,10 +1,16 @@
 package test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/mikeschinkel/go-jsonxtractr"
 )
@@ -61,11 +67,11 @@ func TestStreamingExtractValue(t *testing.T) {
 			},
 		},
 		{
-			name:     "expects array but found object",
+			name:     "numeric key missing from object",
 			raw:      `{"obj":{"k":1}}`,
 			selector: "obj.0",
 			wantErrIsAny: []error{
-				jsonxtractr.ErrJSONPathExpectedArrayAtSegment,
+				jsonxtractr.ErrJSONPathSegmentNotFound,
 			},
 		},
 		{
@@ -79,19 +85,67 @@ func TestStreamingExtractValue(t *testing.T) {
 		{
 			name:     "empty segment in path",
 			raw:      `{"a":{"b":1}}`,
-			selector: "a..b",
+			selector: "a...b",
 			wantErrIsAny: []error{
 				jsonxtractr.ErrJSONPathContainsEmptySegment,
 			},
 		},
+		{
+			name:     "recursive descent",
+			raw:      `{"users":[{"email":"a@x"},{"email":"b@x"}]}`,
+			selector: "users..email",
+			wantErrIsAny: []error{
+				jsonxtractr.ErrJSONSelectorMultiMatch,
+			},
+		},
 		{
 			name:     "negative index",
 			raw:      `{"xs":[0,1]}`,
 			selector: "xs.-1",
+			want:     float64(1),
+		},
+		{
+			name:     "negative index second to last",
+			raw:      `{"xs":[10,20,30]}`,
+			selector: "xs.-2",
+			want:     float64(20),
+		},
+		{
+			name:     "negative index first element",
+			raw:      `{"xs":[10,20,30]}`,
+			selector: "xs.-3",
+			want:     float64(10),
+		},
+		{
+			name:     "negative index then key",
+			raw:      `{"xs":[{"k":"a"},{"k":"b"}]}`,
+			selector: "xs.-1.k",
+			want:     "b",
+		},
+		{
+			name:     "negative index out of range",
+			raw:      `{"xs":[10,20,30]}`,
+			selector: "xs.-5",
+			wantErrIsAny: []error{
+				jsonxtractr.ErrJSONIndexOutOfRange,
+			},
+		},
+		{
+			name:     "negative index on empty array",
+			raw:      `{"xs":[]}`,
+			selector: "xs.-1",
 			wantErrIsAny: []error{
 				jsonxtractr.ErrJSONIndexOutOfRange,
 			},
 		},
+		{
+			name:     "negative numeric key missing from object",
+			raw:      `{"xs":{"k":1}}`,
+			selector: "xs.-1",
+			wantErrIsAny: []error{
+				jsonxtractr.ErrJSONPathSegmentNotFound,
+			},
+		},
 		{
 			name:     "empty body",
 			raw:      ``,
@@ -234,6 +288,23 @@ func TestStreamingExtractValue_ErrorContext(t *testing.T) {
 	}
 }
 
+func TestStreamingExtractValue_NegativeIndexErrorContext(t *testing.T) {
+	jsonData := `{"xs": [10, 20, 30]}`
+
+	_, err := jsonxtractr.ExtractValueFromBytes([]byte(jsonData), "xs.-5")
+	if err == nil {
+		t.Fatal("Expected error for out of range negative index")
+	}
+
+	errStr := err.Error()
+	if !strings.Contains(errStr, "target_index=-5") {
+		t.Errorf("Error should contain target index: %v", err)
+	}
+	if !strings.Contains(errStr, "array_length=3") {
+		t.Errorf("Error should contain array length: %v", err)
+	}
+}
+
 func TestExtractValuesFromBytes_MultipleSelectors(t *testing.T) {
 	jsonData := `{
 		"user": {"name": "Alice", "age": 30},
@@ -346,6 +417,32 @@ func TestExtractValuesFromReader_MultipleSelectors(t *testing.T) {
 	}
 }
 
+func TestExtractValuesFromBytes_NotFoundReturn(t *testing.T) {
+	jsonData := []byte(`{"a": 1, "b": {"c": 2}}`)
+	selectors := []jsonxtractr.Selector{"a", "x", "b.c", "b.y"}
+
+	valuesMap, notFound, _ := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+
+	// The second return lists the selectors that were not found, never those
+	// that were
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"x", "b.y"}) {
+		t.Errorf("ExtractValuesFromBytes() notFound = %v, want [x b.y]", notFound)
+	}
+	for _, selector := range notFound {
+		if _, ok := valuesMap[selector]; ok {
+			t.Errorf("ExtractValuesFromBytes() returned %q both as a value and as not found", selector)
+		}
+	}
+	if len(valuesMap)+len(notFound) != len(selectors) {
+		t.Errorf("ExtractValuesFromBytes() = %v, %v, want every selector either found or not", valuesMap, notFound)
+	}
+
+	_, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{"a", "b.c"})
+	if err != nil || len(notFound) != 0 {
+		t.Errorf("ExtractValuesFromBytes() notFound = %v, %v, want none when every selector is found", notFound, err)
+	}
+}
+
 func TestExtractValuesFromBytes_ErrorCollection(t *testing.T) {
 	jsonData := `{"valid": "value"}`
 
@@ -387,3 +484,937 @@ func TestExtractValuesFromBytes_ErrorCollection(t *testing.T) {
 		t.Errorf("Error should mention missing3: %v", err)
 	}
 }
+
+func TestExtractValuesFromBytes_SharedPrefixes(t *testing.T) {
+	jsonData := `{"user": {"name": "Alice", "tags": ["x", "y"]}, "scores": [100, 85]}`
+
+	selectors := []jsonxtractr.Selector{
+		"user",
+		"user.name",
+		"user.tags.1",
+		"user.missing",
+		"scores.1",
+		"scores.1",
+		"scores.-1",
+		"scores.-2",
+		"scores.5",
+		"scores.-3",
+	}
+
+	valuesMap, notFound, err := jsonxtractr.ExtractValuesFromBytes([]byte(jsonData), selectors)
+	if err == nil {
+		t.Fatal("Expected error for missing selectors")
+	}
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("Error should be errors.Is(..., ErrJSONPathSegmentNotFound): %v", err)
+	}
+	if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+		t.Errorf("Error should be errors.Is(..., ErrJSONIndexOutOfRange): %v", err)
+	}
+
+	expectedValuesMap := jsonxtractr.ValuesMap{
+		"user":        map[string]any{"name": "Alice", "tags": []any{"x", "y"}},
+		"user.name":   "Alice",
+		"user.tags.1": "y",
+		"scores.1":    float64(85),
+		"scores.-1":   float64(85),
+		"scores.-2":   float64(100),
+	}
+	if !reflect.DeepEqual(valuesMap, expectedValuesMap) {
+		t.Errorf("ValuesMap mismatch:\n  got:  %#v\n  want: %#v", valuesMap, expectedValuesMap)
+	}
+
+	expectedNotFound := []jsonxtractr.Selector{"user.missing", "scores.5", "scores.-3"}
+	if !reflect.DeepEqual(notFound, expectedNotFound) {
+		t.Errorf("NotFound selectors mismatch:\n  got:  %v\n  want: %v", notFound, expectedNotFound)
+	}
+
+	errStr := err.Error()
+	if !strings.Contains(errStr, "available_keys=[name tags]") {
+		t.Errorf("Error should list available keys: %v", err)
+	}
+	if !strings.Contains(errStr, "array_length=2") {
+		t.Errorf("Error should contain array length: %v", err)
+	}
+}
+
+// blockingReader returns its data and then blocks, as a stalled network
+// stream would, until unblock is closed.
+type blockingReader struct {
+	data    *strings.Reader
+	unblock chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	if r.data.Len() > 0 {
+		return r.data.Read(p)
+	}
+	<-r.unblock
+	return 0, errors.New("reader unblocked")
+}
+
+func TestExtractValuesFromReaderContext_CanceledWhileReading(t *testing.T) {
+	reader := &blockingReader{
+		data:    strings.NewReader(`{"a": 1, "b": `),
+		unblock: make(chan struct{}),
+	}
+	defer close(reader.unblock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := jsonxtractr.ExtractValuesFromReaderContext(ctx, reader, []jsonxtractr.Selector{"a", "b"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, jsonxtractr.ErrJSONExtractionCanceled) {
+			t.Errorf("ExtractValuesFromReaderContext() error = %v, want %v", err, jsonxtractr.ErrJSONExtractionCanceled)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ExtractValuesFromReaderContext() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExtractValuesFromReaderContext() did not return after cancellation")
+	}
+}
+
+func TestExtractValuesFromReaderContext_CanceledBeforeDecoding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromReaderContext(ctx, strings.NewReader(`{"a": 1}`), []jsonxtractr.Selector{"a"})
+	if !errors.Is(err, jsonxtractr.ErrJSONExtractionCanceled) {
+		t.Errorf("ExtractValuesFromReaderContext() error = %v, want %v", err, jsonxtractr.ErrJSONExtractionCanceled)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExtractValuesFromReaderContext() error = %v, want %v", err, context.Canceled)
+	}
+	if values != nil || notFound != nil {
+		t.Errorf("ExtractValuesFromReaderContext() = %v, %v, want no partial results", values, notFound)
+	}
+}
+
+func TestExtractValuesFromReaderContext_DeadlineExceeded(t *testing.T) {
+	reader := &blockingReader{
+		data:    strings.NewReader(`{"a": `),
+		unblock: make(chan struct{}),
+	}
+	defer close(reader.unblock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := jsonxtractr.ExtractValuesFromReaderContext(ctx, reader, []jsonxtractr.Selector{"a"})
+	if !errors.Is(err, jsonxtractr.ErrJSONExtractionCanceled) {
+		t.Errorf("ExtractValuesFromReaderContext() error = %v, want %v", err, jsonxtractr.ErrJSONExtractionCanceled)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ExtractValuesFromReaderContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestExtractValuesFromReaderContext_Completes(t *testing.T) {
+	values, notFound, err := jsonxtractr.ExtractValuesFromReaderContext(
+		context.Background(),
+		strings.NewReader(`{"a": 1, "b": {"c": "x"}}`),
+		[]jsonxtractr.Selector{"a", "b.c", "d"},
+	)
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValuesFromReaderContext() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	want := jsonxtractr.ValuesMap{"a": float64(1), "b.c": "x"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractValuesFromReaderContext() values = %v, want %v", values, want)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"d"}) {
+		t.Errorf("ExtractValuesFromReaderContext() notFound = %v, want [d]", notFound)
+	}
+}
+
+func TestExtractValueOr(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "Alice", "nickname": null, "tags": ["a"]}}`)
+
+	tests := []struct {
+		name     string
+		raw      []byte
+		selector jsonxtractr.Selector
+		want     any
+		wantErr  error
+	}{
+		{name: "present", raw: jsonData, selector: "user.name", want: "Alice"},
+		{name: "present null is not absent", raw: jsonData, selector: "user.nickname", want: nil},
+		{name: "missing key", raw: jsonData, selector: "user.email", want: "default"},
+		{name: "missing parent", raw: jsonData, selector: "account.id", want: "default"},
+		{name: "index out of range", raw: jsonData, selector: "user.tags.3", want: "default"},
+		{name: "negative index out of range", raw: jsonData, selector: "user.tags.-2", want: "default"},
+		{name: "key through a scalar", raw: jsonData, selector: "user.name.first", want: "default"},
+		{name: "index through a scalar", raw: jsonData, selector: "user.name.0", want: "default"},
+		{name: "malformed JSON", raw: []byte(`{"user": {"name": `), selector: "user.email", wantErr: jsonxtractr.ErrJSONTokenReadFailed},
+		{name: "empty body", raw: nil, selector: "user.email", wantErr: jsonxtractr.ErrJSONBodyCannotBeEmpty},
+		{name: "empty segment", raw: jsonData, selector: "user...email", wantErr: jsonxtractr.ErrJSONPathContainsEmptySegment},
+		{name: "unbalanced quote", raw: jsonData, selector: `"user`, wantErr: jsonxtractr.ErrJSONSelectorUnbalancedQuote},
+		{name: "wildcard", raw: jsonData, selector: "user.*", wantErr: jsonxtractr.ErrJSONSelectorMultiMatch},
+		{name: "recursive descent", raw: jsonData, selector: "user..email", wantErr: jsonxtractr.ErrJSONSelectorMultiMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueOr(tt.raw, tt.selector, "default")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ExtractValueOr() error = %v, want %v", err, tt.wantErr)
+				}
+				if got == "default" {
+					t.Errorf("ExtractValueOr() = %v, want no default on error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractValueOr() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueOr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// errAfterReader yields its data and then fails every later read, standing in
+// for a stream whose remainder must never be needed.
+type errAfterReader struct {
+	data *strings.Reader
+}
+
+var errReadPastTarget = errors.New("read past target")
+
+func (r *errAfterReader) Read(p []byte) (int, error) {
+	if r.data.Len() > 0 {
+		return r.data.Read(p)
+	}
+	return 0, errReadPastTarget
+}
+
+func TestExtractValueFromReader_StopsAtTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{name: "first key", data: `{"id": 42, "rest": `, selector: "id", want: float64(42)},
+		{name: "nested", data: `{"meta": {"version": "v2"}, "items": [`, selector: "meta.version", want: "v2"},
+		{name: "array element", data: `[{"a": 1}, {"a": 2}, `, selector: "1.a", want: float64(2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromReader(&errAfterReader{data: strings.NewReader(tt.data)}, tt.selector)
+			if err != nil {
+				t.Fatalf("ExtractValueFromReader() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValueFromReader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValuesFromReader_StopsOnceAllFound(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		selectors []jsonxtractr.Selector
+		want      jsonxtractr.ValuesMap
+	}{
+		{
+			name:      "sibling keys",
+			data:      `{"id": 42, "name": "Alice", "rest": `,
+			selectors: []jsonxtractr.Selector{"name", "id"},
+			want:      jsonxtractr.ValuesMap{"id": float64(42), "name": "Alice"},
+		},
+		{
+			name:      "nested paths",
+			data:      `{"meta": {"version": "v2", "tags": ["a", "b"]}, "count": 3, "items": [`,
+			selectors: []jsonxtractr.Selector{"meta.version", "meta.tags.1", "count"},
+			want:      jsonxtractr.ValuesMap{"meta.version": "v2", "meta.tags.1": "b", "count": float64(3)},
+		},
+		{
+			name:      "array elements",
+			data:      `[{"a": 1}, {"a": 2}, `,
+			selectors: []jsonxtractr.Selector{"0.a", "1"},
+			want:      jsonxtractr.ValuesMap{"0.a": float64(1), "1": map[string]any{"a": float64(2)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notFound, err := jsonxtractr.ExtractValuesFromReader(&errAfterReader{data: strings.NewReader(tt.data)}, tt.selectors)
+			if err != nil {
+				t.Fatalf("ExtractValuesFromReader() error = %v", err)
+			}
+			if len(notFound) > 0 {
+				t.Errorf("ExtractValuesFromReader() notFound = %v, want none", notFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractValuesFromReader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValuesFromReader_UnresolvedAtEnd(t *testing.T) {
+	got, notFound, err := jsonxtractr.ExtractValuesFromReader(strings.NewReader(`{"a": 1, "b": {"c": 2}}`), []jsonxtractr.Selector{"a", "b.d", "e"})
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Errorf("ExtractValuesFromReader() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"b.d", "e"}) {
+		t.Errorf("ExtractValuesFromReader() notFound = %v, want [b.d e]", notFound)
+	}
+	if !reflect.DeepEqual(got, jsonxtractr.ValuesMap{"a": float64(1)}) {
+		t.Errorf("ExtractValuesFromReader() = %v, want map[a:1]", got)
+	}
+
+	// A selector still pending when the stream fails reports the read failure
+	_, _, err = jsonxtractr.ExtractValuesFromReader(&errAfterReader{data: strings.NewReader(`{"a": 1, "b": `)}, []jsonxtractr.Selector{"a", "c"})
+	if !errors.Is(err, jsonxtractr.ErrJSONReadFailed) || !errors.Is(err, errReadPastTarget) {
+		t.Errorf("ExtractValuesFromReader() error = %v, want %v", err, jsonxtractr.ErrJSONReadFailed)
+	}
+}
+
+func TestExtractValueFromReader_ReadFailureBeforeTarget(t *testing.T) {
+	reader := &errAfterReader{data: strings.NewReader(`{"a": 1, "b": `)}
+
+	_, err := jsonxtractr.ExtractValueFromReader(reader, "c")
+	if !errors.Is(err, jsonxtractr.ErrJSONReadFailed) {
+		t.Errorf("ExtractValueFromReader() error = %v, want %v", err, jsonxtractr.ErrJSONReadFailed)
+	}
+	if !errors.Is(err, errReadPastTarget) {
+		t.Errorf("ExtractValueFromReader() error = %v, want %v", err, errReadPastTarget)
+	}
+}
+
+func TestExtractValueFromReader_StreamingErrorContext(t *testing.T) {
+	_, err := jsonxtractr.ExtractValueFromReader(strings.NewReader("{\n  \"a\": {\"b\": 1}\n}"), "a.c")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+		t.Fatalf("ExtractValueFromReader() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+
+	var pathErr *jsonxtractr.PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("ExtractValueFromReader() error = %v, want a *PathError", err)
+	}
+	if pathErr.Line != 2 {
+		t.Errorf("PathError.Line = %d, want 2", pathErr.Line)
+	}
+	if !strings.Contains(err.Error(), `"b": 1`) {
+		t.Errorf("ExtractValueFromReader() error = %v, want the consumed JSON in context", err)
+	}
+}
+
+func TestExtractValuesFromReader_ConcurrentPooledBuffers(t *testing.T) {
+	const goroutines = 16
+	const iterations = 50
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	kept := make([][]jsonxtractr.ValuesMap, goroutines)
+
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				id := fmt.Sprintf("g%d-i%d", g, i)
+				jsonData := fmt.Sprintf(`{"id": %q, "nested": {"name": %q}, "list": [%q]}`, id, id, id)
+
+				values, _, err := jsonxtractr.ExtractValuesFromReader(strings.NewReader(jsonData), []jsonxtractr.Selector{"id", "nested.name", "list.0", "missing"})
+				if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+					errs <- fmt.Errorf("%s: error = %v", id, err)
+					return
+				}
+				want := jsonxtractr.ValuesMap{"id": id, "nested.name": id, "list.0": id}
+				if !reflect.DeepEqual(values, want) {
+					errs <- fmt.Errorf("%s: values = %v, want %v", id, values, want)
+					return
+				}
+				if !strings.Contains(err.Error(), id) {
+					errs <- fmt.Errorf("%s: error context = %v", id, err)
+					return
+				}
+				kept[g] = append(kept[g], values)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Values kept from earlier calls must not share memory with buffers that
+	// later calls reused
+	for g, results := range kept {
+		for i, values := range results {
+			id := fmt.Sprintf("g%d-i%d", g, i)
+			for selector, value := range values {
+				if value != id {
+					t.Errorf("%s: %s = %v after later calls", id, selector, value)
+				}
+			}
+		}
+	}
+}
+
+func TestExtractValues_DuplicateSelectors(t *testing.T) {
+	jsonData := `{"a": 1, "c": 3}`
+	selectors := []jsonxtractr.Selector{"a", "a", "b", "c", "b"}
+
+	check := func(t *testing.T, values jsonxtractr.ValuesMap, notFound []jsonxtractr.Selector, err error) {
+		t.Helper()
+		if !reflect.DeepEqual(values, jsonxtractr.ValuesMap{"a": float64(1), "c": float64(3)}) {
+			t.Errorf("values = %v, want map[a:1 c:3]", values)
+		}
+		if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{"b"}) {
+			t.Errorf("notFound = %v, want [b]", notFound)
+		}
+		if n := strings.Count(err.Error(), "missing_key"); n != 1 {
+			t.Errorf("error reports the missing selector %d times, want once: %v", n, err)
+		}
+	}
+
+	t.Run("bytes", func(t *testing.T) {
+		values, notFound, err := jsonxtractr.ExtractValuesFromBytes([]byte(jsonData), selectors)
+		check(t, values, notFound, err)
+	})
+	t.Run("reader", func(t *testing.T) {
+		values, notFound, err := jsonxtractr.ExtractValuesFromReader(strings.NewReader(jsonData), selectors)
+		check(t, values, notFound, err)
+	})
+	t.Run("document", func(t *testing.T) {
+		doc, err := jsonxtractr.NewDocument([]byte(jsonData))
+		if err != nil {
+			t.Fatalf("NewDocument() error = %v", err)
+		}
+		values, notFound, err := doc.Values(selectors)
+		check(t, values, notFound, err)
+	})
+
+	// A selector repeated on its own resolves like the single selector
+	values, notFound, err := jsonxtractr.ExtractValuesFromReader(strings.NewReader(jsonData), []jsonxtractr.Selector{"a", "a"})
+	if err != nil || len(notFound) > 0 || !reflect.DeepEqual(values, jsonxtractr.ValuesMap{"a": float64(1)}) {
+		t.Errorf("ExtractValuesFromReader() = %v, %v, %v", values, notFound, err)
+	}
+}
+
+func TestExtractValueFromString(t *testing.T) {
+	jsonData := `{"user": {"name": "Alice", "tags": ["a", "b"]}}`
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+	}{
+		{name: "string value", selector: "user.name"},
+		{name: "array element", selector: "user.tags.1"},
+		{name: "missing key", selector: "user.email"},
+		{name: "index out of range", selector: "user.tags.5"},
+		{name: "empty selector", selector: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromString(jsonData, tt.selector)
+			want, wantErr := jsonxtractr.ExtractValueFromBytes([]byte(jsonData), tt.selector)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ExtractValueFromString() = %v, want %v", got, want)
+			}
+			if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+				t.Errorf("ExtractValueFromString() error = %v, want %v", err, wantErr)
+			}
+		})
+	}
+
+	_, err := jsonxtractr.ExtractValueFromString("", "a")
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) {
+		t.Errorf("ExtractValueFromString() error = %v, want %v", err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+}
+
+func TestExtractValuesFromString(t *testing.T) {
+	jsonData := `{"a": 1, "b": {"c": "x"}}`
+	selectors := []jsonxtractr.Selector{"a", "b.c", "d"}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromString(jsonData, selectors)
+	wantValues, wantNotFound, wantErr := jsonxtractr.ExtractValuesFromBytes([]byte(jsonData), selectors)
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("ExtractValuesFromString() values = %v, want %v", values, wantValues)
+	}
+	if !reflect.DeepEqual(notFound, wantNotFound) {
+		t.Errorf("ExtractValuesFromString() notFound = %v, want %v", notFound, wantNotFound)
+	}
+	if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+		t.Errorf("ExtractValuesFromString() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestExtractValue_TopLevelArray(t *testing.T) {
+	jsonData := []byte(`[10, 20, 30]`)
+
+	tests := []struct {
+		selector jsonxtractr.Selector
+		want     any
+		wantErr  error
+	}{
+		{selector: "0", want: float64(10)},
+		{selector: "1", want: float64(20)},
+		{selector: "2", want: float64(30)},
+		{selector: "-1", want: float64(30)},
+		{selector: "3", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{selector: "-4", wantErr: jsonxtractr.ErrJSONIndexOutOfRange},
+		{selector: "a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{selector: `"0"`, wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+		{selector: "0.a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment},
+	}
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selector), func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+			docGot, docErr := doc.Value(tt.selector)
+			values, _, multiErr := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{tt.selector, "0"})
+
+			if tt.wantErr != nil {
+				for name, err := range map[string]error{"ExtractValueFromBytes": err, "Document.Value": docErr, "ExtractValuesFromBytes": multiErr} {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("%s(%s) error = %v, want %v", name, tt.selector, err, tt.wantErr)
+					}
+				}
+				return
+			}
+			if err != nil || docErr != nil || multiErr != nil {
+				t.Fatalf("errors = %v, %v, %v", err, docErr, multiErr)
+			}
+			if got != tt.want || docGot != tt.want || values[tt.selector] != tt.want {
+				t.Errorf("values = %v, %v, %v, want %v", got, docGot, values[tt.selector], tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractValue_TopLevelScalar(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		selector jsonxtractr.Selector
+		wantErr  error
+		wantType string
+	}{
+		{name: "key on string", json: `"hello"`, selector: "a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantType: "string"},
+		{name: "index on string", json: `"hello"`, selector: "0", wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment, wantType: "string"},
+		{name: "key on number", json: `42`, selector: "a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantType: "number"},
+		{name: "index on number", json: `42`, selector: "0", wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment, wantType: "number"},
+		{name: "key on null", json: `null`, selector: "a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantType: "null"},
+		{name: "index on null", json: `null`, selector: "0", wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment, wantType: "null"},
+		{name: "key on bool", json: `true`, selector: "a.b", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantType: "bool"},
+		{name: "key on array", json: `[1]`, selector: "a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantType: "array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytes([]byte(tt.json), tt.selector)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExtractValueFromBytes(%s) error = %v, want %v", tt.selector, err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), "actual_type="+tt.wantType+" ") &&
+				!strings.HasSuffix(err.Error(), "actual_type="+tt.wantType) {
+				t.Errorf("ExtractValueFromBytes(%s) error = %v, want actual_type=%s", tt.selector, err, tt.wantType)
+			}
+
+			doc, err := jsonxtractr.NewDocument([]byte(tt.json))
+			if err != nil {
+				t.Fatalf("NewDocument() error = %v", err)
+			}
+			if _, err = doc.Value(tt.selector); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Document.Value(%s) error = %v, want %v", tt.selector, err, tt.wantErr)
+			}
+		})
+	}
+
+	// The root selector still selects a top-level scalar
+	value, err := jsonxtractr.ExtractValueFromBytes([]byte(`null`), jsonxtractr.RootSelector)
+	if err != nil || value != nil {
+		t.Errorf("ExtractValueFromBytes(null, RootSelector) = %v, %v, want nil, nil", value, err)
+	}
+}
+
+func TestExtractValue_ActualValuePreview(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	jsonData := []byte(`{
+		"config": "listen on port 8080",
+		"items": {"first": 1},
+		"long": "` + long + `",
+		"token": "s3cret",
+		"nested": [{"token": "s3cret", "n": 1}]
+	}`)
+
+	tests := []struct {
+		name        string
+		selector    jsonxtractr.Selector
+		wantErr     error
+		wantPreview string
+	}{
+		{name: "string for object", selector: "config.port", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantPreview: `"listen on port 8080"`},
+		{name: "string for array", selector: "config.0", wantErr: jsonxtractr.ErrJSONPathExpectedArrayAtSegment, wantPreview: `"listen on port 8080"`},
+		{name: "bounded", selector: "long.a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantPreview: `"` + long[:59] + "...[more]"},
+		{name: "redacted value", selector: "token.a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantPreview: `"***"`},
+		{name: "redacted member", selector: "nested.a", wantErr: jsonxtractr.ErrJSONPathExpectedObjectAtSegment, wantPreview: `[{"token": "***", "n": 1}]`},
+	}
+
+	opts := []jsonxtractr.Option{jsonxtractr.WithErrorJSONRedactKeys("token")}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytesOpts(jsonData, tt.selector, opts...)
+			_, _, multiErr := jsonxtractr.ExtractValuesFromBytesOpts(jsonData, []jsonxtractr.Selector{"items.first", tt.selector}, opts...)
+			_, readerErr := jsonxtractr.ExtractValueFromReaderOpts(bytes.NewReader(jsonData), tt.selector, opts...)
+
+			for i, err := range []error{err, multiErr, readerErr} {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				preview, _ := jsonxtractr.ErrValue[string](err, "actual_value_preview")
+				// A stream previews only as much of the value as it has read
+				if preview != tt.wantPreview && (i != 2 || preview == "" ||
+					!strings.HasPrefix(tt.wantPreview, preview)) {
+					t.Errorf("ErrValue(actual_value_preview) = %q, want %q", preview, tt.wantPreview)
+				}
+				if strings.Contains(err.Error(), "s3cret") {
+					t.Errorf("error = %v, want %q redacted", err, "s3cret")
+				}
+			}
+		})
+	}
+
+	// Documents preview the value alike
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+	_, err = doc.Value("config.port")
+	if preview, _ := jsonxtractr.ErrValue[string](err, "actual_value_preview"); preview != `"listen on port 8080"` {
+		t.Errorf("Document.Value() actual_value_preview = %q, want %q", preview, `"listen on port 8080"`)
+	}
+}
+
+func TestExtractValues_SharedPrefixMatchesIndividual(t *testing.T) {
+	jsonData := []byte(`{
+		"user": {"name": "Alice", "email": "a@example.com", "age": 30, "tags": ["x", "y"]},
+		"other": 1
+	}`)
+	selectors := []jsonxtractr.Selector{
+		"user.name", "user.email", "user.age", "user.tags.1",
+		"user.missing", "user.name.first", "user.tags.5", "other",
+	}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+	var multiErr *jsonxtractr.MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("ExtractValuesFromBytes() error = %v, want *MultiError", err)
+	}
+
+	failures := multiErr.Failures()
+	var wantNotFound []jsonxtractr.Selector
+	for _, selector := range selectors {
+		want, wantErr := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+		if wantErr != nil {
+			if jsonxtractr.IsAbsent(wantErr) {
+				wantNotFound = append(wantNotFound, selector)
+			}
+			for _, sentinel := range []error{
+				jsonxtractr.ErrJSONPathSegmentNotFound,
+				jsonxtractr.ErrJSONPathExpectedObjectAtSegment,
+				jsonxtractr.ErrJSONIndexOutOfRange,
+			} {
+				if errors.Is(wantErr, sentinel) != errors.Is(failures[selector], sentinel) {
+					t.Errorf("%s: error = %v, want %v", selector, failures[selector], wantErr)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(values[selector], want) {
+			t.Errorf("%s: value = %v, want %v", selector, values[selector], want)
+		}
+	}
+	if !reflect.DeepEqual(notFound, wantNotFound) {
+		t.Errorf("notFound = %v, want %v", notFound, wantNotFound)
+	}
+}
+
+func TestExtractValueWithPath(t *testing.T) {
+	jsonData := []byte(`{"User": {"Name": "Alice", "roles": ["admin", "dev", "ops"]}, "items": [{"id": 1}, {"id": 2}]}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		opts     []jsonxtractr.Option
+		want     any
+		wantPath jsonxtractr.Selector
+	}{
+		{name: "exact", selector: "User.Name", want: "Alice", wantPath: "User.Name"},
+		{name: "exact index", selector: "User.roles.1", want: "dev", wantPath: "User.roles.1"},
+		{name: "case-insensitive", selector: "user.name", opts: []jsonxtractr.Option{jsonxtractr.WithCaseInsensitiveKeys()}, want: "Alice", wantPath: "User.Name"},
+		{name: "ordinal", selector: "#0.#1", want: []any{"admin", "dev", "ops"}, wantPath: "User.roles"},
+		{name: "negative index", selector: "User.roles.-1", want: "ops", wantPath: "User.roles.2"},
+		{name: "filter", selector: "items.#(id==2).id", want: float64(2), wantPath: "items.1.id"},
+		{name: "bracket notation", selector: "items[0]['id']", want: float64(1), wantPath: "items.0.id"},
+		{name: "root", selector: jsonxtractr.RootSelector, want: nil, wantPath: jsonxtractr.RootSelector},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, path, err := jsonxtractr.ExtractValueWithPathOpts(jsonData, tt.selector, tt.opts...)
+			if err != nil {
+				t.Fatalf("ExtractValueWithPathOpts() error = %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("ExtractValueWithPathOpts() path = %q, want %q", path, tt.wantPath)
+			}
+			if tt.want != nil && !reflect.DeepEqual(value, tt.want) {
+				t.Errorf("ExtractValueWithPathOpts() value = %v, want %v", value, tt.want)
+			}
+		})
+	}
+
+	_, path, err := jsonxtractr.ExtractValueWithPath(jsonData, "User.email")
+	if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) || path != "" {
+		t.Errorf("ExtractValueWithPath() = %q, %v, want %v", path, err, jsonxtractr.ErrJSONPathSegmentNotFound)
+	}
+}
+
+func TestExtractValueFromReaderN(t *testing.T) {
+	first := `{"id": 1, "user": {"name": "Alice"}, "tail": [1, 2, 3]}`
+	second := `{"user": {"name": "Bob"}}`
+	input := first + "\n" + second
+
+	reader := bytes.NewReader([]byte(input))
+	value, bytesRead, err := jsonxtractr.ExtractValueFromReaderN(reader, "user.name")
+	if err != nil {
+		t.Fatalf("ExtractValueFromReaderN() error = %v", err)
+	}
+	if value != "Alice" {
+		t.Errorf("ExtractValueFromReaderN() value = %v, want Alice", value)
+	}
+	valueEnd := int64(strings.Index(input, `"Alice"`) + len(`"Alice"`))
+	if bytesRead < valueEnd {
+		t.Errorf("ExtractValueFromReaderN() bytesRead = %d, want at least %d", bytesRead, valueEnd)
+	}
+	if bytesRead > int64(len(first)) {
+		t.Errorf("ExtractValueFromReaderN() bytesRead = %d, past the first document's end at %d", bytesRead, len(first))
+	}
+
+	t.Run("whole document", func(t *testing.T) {
+		reader := bytes.NewReader([]byte(input))
+		_, bytesRead, err := jsonxtractr.ExtractValueFromReaderN(reader, jsonxtractr.RootSelector)
+		if err != nil {
+			t.Fatalf("ExtractValueFromReaderN() error = %v", err)
+		}
+		if bytesRead != int64(len(first)) {
+			t.Fatalf("ExtractValueFromReaderN() bytesRead = %d, want %d", bytesRead, len(first))
+		}
+
+		// Resume at the next document
+		_, err = reader.Seek(bytesRead, io.SeekStart)
+		if err != nil {
+			t.Fatalf("Seek() error = %v", err)
+		}
+		value, _, err := jsonxtractr.ExtractValueFromReaderN(reader, "user.name")
+		if err != nil {
+			t.Fatalf("ExtractValueFromReaderN() error = %v", err)
+		}
+		if value != "Bob" {
+			t.Errorf("ExtractValueFromReaderN() value = %v, want Bob", value)
+		}
+	})
+
+	t.Run("failure reports bytes read", func(t *testing.T) {
+		_, bytesRead, err := jsonxtractr.ExtractValueFromReaderN(strings.NewReader(first), "missing")
+		if !errors.Is(err, jsonxtractr.ErrJSONPathSegmentNotFound) {
+			t.Errorf("ExtractValueFromReaderN() error = %v, want %v", err, jsonxtractr.ErrJSONPathSegmentNotFound)
+		}
+		if bytesRead != int64(len(first)) {
+			t.Errorf("ExtractValueFromReaderN() bytesRead = %d, want %d", bytesRead, len(first))
+		}
+	})
+}
+
+func TestExtractValue_SegmentKindFollowsData(t *testing.T) {
+	jsonData := []byte(`{
+	"byKey": {"0": "k0", "10": "k10", "-1": "kneg"},
+	"byIndex": ["i0", "i1"],
+	"nested": [{"0": "n0"}, {"1": "n1"}],
+	"user": {"name": "Alice"}
+}`)
+
+	tests := []struct {
+		name     string
+		selector jsonxtractr.Selector
+		want     any
+	}{
+		{name: "numeric key on object", selector: "byKey.10", want: "k10"},
+		{name: "negative numeric key on object", selector: "byKey.-1", want: "kneg"},
+		{name: "index on array", selector: "byIndex.1", want: "i1"},
+		{name: "negative index on array", selector: "byIndex.-1", want: "i1"},
+		{name: "index then numeric key", selector: "nested.1.1", want: "n1"},
+		{name: "key", selector: "user.name", want: "Alice"},
+	}
+
+	doc, err := jsonxtractr.NewDocument(jsonData)
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	selectors := make([]jsonxtractr.Selector, 0, len(tests))
+	for _, tt := range tests {
+		selectors = append(selectors, tt.selector)
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonxtractr.ExtractValueFromBytes(jsonData, tt.selector)
+			if err != nil || got != tt.want {
+				t.Errorf("ExtractValueFromBytes() = %v, %v, want %v", got, err, tt.want)
+			}
+			got, err = jsonxtractr.ExtractValueFromReader(bytes.NewReader(jsonData), tt.selector)
+			if err != nil || got != tt.want {
+				t.Errorf("ExtractValueFromReader() = %v, %v, want %v", got, err, tt.want)
+			}
+			got, err = doc.Value(tt.selector)
+			if err != nil || got != tt.want {
+				t.Errorf("Document.Value() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("several selectors", func(t *testing.T) {
+		valuesMap, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, selectors)
+		if err != nil {
+			t.Fatalf("ExtractValuesFromBytes() error = %v, notFound = %v", err, notFound)
+		}
+		for _, tt := range tests {
+			if valuesMap[tt.selector] != tt.want {
+				t.Errorf("ExtractValuesFromBytes()[%s] = %v, want %v", tt.selector, valuesMap[tt.selector], tt.want)
+			}
+		}
+	})
+
+	t.Run("key on array", func(t *testing.T) {
+		const reason = `reason=expected index but got "first"`
+
+		_, err := jsonxtractr.ExtractValueFromBytes(jsonData, "byIndex.first")
+		if !errors.Is(err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment) || !strings.Contains(err.Error(), reason) {
+			t.Errorf("ExtractValueFromBytes() error = %v, want %v with %s", err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment, reason)
+		}
+		_, _, err = jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{"byIndex.first", "user.name"})
+		if !errors.Is(err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment) || !strings.Contains(err.Error(), reason) {
+			t.Errorf("ExtractValuesFromBytes() error = %v, want %v with %s", err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment, reason)
+		}
+		_, err = doc.Value("byIndex.first")
+		if !errors.Is(err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment) || !strings.Contains(err.Error(), reason) {
+			t.Errorf("Document.Value() error = %v, want %v with %s", err, jsonxtractr.ErrJSONPathExpectedObjectAtSegment, reason)
+		}
+	})
+
+	t.Run("resolved path", func(t *testing.T) {
+		_, path, err := jsonxtractr.ExtractValueWithPath([]byte(`{"-1": ["x", "y"]}`), "-1.-1")
+		if err != nil {
+			t.Fatalf("ExtractValueWithPath() error = %v", err)
+		}
+		if path != `"-1".1` {
+			t.Errorf("ExtractValueWithPath() path = %s, want %s", path, `"-1".1`)
+		}
+	})
+
+	t.Run("created key", func(t *testing.T) {
+		got, err := jsonxtractr.SetValueOpts([]byte(`{"0": "a"}`), "1", "b", jsonxtractr.WithCreateMissing())
+		if err != nil {
+			t.Fatalf("SetValueOpts() error = %v", err)
+		}
+		if string(got) != `{"0": "a","1":"b"}` {
+			t.Errorf("SetValueOpts() = %s, want %s", got, `{"0": "a","1":"b"}`)
+		}
+	})
+}
+
+func TestExtractValue_MalformedSibling(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		selector jsonxtractr.Selector
+		wantMeta []string
+	}{
+		{name: "member before the target key", json: `{"a": {"x": [1, 2,, 3]}, "target": 1}`, selector: "target", wantMeta: []string{"skipping_key=a", "byte_offset=18"}},
+		{name: "element before the target index", json: `[{"a": tru}, 5]`, selector: "1", wantMeta: []string{"skip_index=0", "byte_offset=10"}},
+		{name: "element before a negative index", json: `[1, {"a" 1}, 5]`, selector: "-1", wantMeta: []string{"skip_index=1", "byte_offset=9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonxtractr.ExtractValueFromBytes([]byte(tt.json), tt.selector)
+			if !errors.Is(err, jsonxtractr.ErrJSONSkipFailed) {
+				t.Fatalf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONSkipFailed)
+			}
+			for _, meta := range tt.wantMeta {
+				if !strings.Contains(err.Error(), meta) {
+					t.Errorf("ExtractValueFromBytes() error = %v, want it to report %s", err, meta)
+				}
+			}
+
+			_, err = jsonxtractr.ExtractValueFromReader(strings.NewReader(tt.json), tt.selector)
+			if !errors.Is(err, jsonxtractr.ErrJSONSkipFailed) {
+				t.Errorf("ExtractValueFromReader() error = %v, want %v", err, jsonxtractr.ErrJSONSkipFailed)
+			}
+		})
+	}
+
+	t.Run("several selectors", func(t *testing.T) {
+		_, _, err := jsonxtractr.ExtractValuesFromBytes([]byte(`{"a": [1,, 2], "b": 1, "c": 2}`), []jsonxtractr.Selector{"b", "c"})
+		if !errors.Is(err, jsonxtractr.ErrJSONSkipFailed) || !strings.Contains(err.Error(), "skipping_key=a") {
+			t.Errorf("ExtractValuesFromBytes() error = %v, want %v skipping a", err, jsonxtractr.ErrJSONSkipFailed)
+		}
+	})
+
+	// A path that doesn't match, or a malformed target, isn't a failed skip
+	_, err := jsonxtractr.ExtractValueFromBytes([]byte(`{"a": 1}`), "b")
+	if errors.Is(err, jsonxtractr.ErrJSONSkipFailed) {
+		t.Errorf("ExtractValueFromBytes() error = %v, want no %v", err, jsonxtractr.ErrJSONSkipFailed)
+	}
+	_, err = jsonxtractr.ExtractValueFromBytes([]byte(`{"a": 1, "b": [1,, 2]}`), "b")
+	if err == nil || errors.Is(err, jsonxtractr.ErrJSONSkipFailed) {
+		t.Errorf("ExtractValueFromBytes() error = %v, want an error other than %v", err, jsonxtractr.ErrJSONSkipFailed)
+	}
+}
+
+func TestExtractValue_MinIntIndex(t *testing.T) {
+	jsonData := []byte(`{"xs": [10, 20, 30]}`)
+	selector := jsonxtractr.Selector("xs.-9223372036854775808")
+
+	_, err := jsonxtractr.ExtractValueFromBytes(jsonData, selector)
+	if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+		t.Errorf("ExtractValueFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONIndexOutOfRange)
+	}
+
+	values, notFound, err := jsonxtractr.ExtractValuesFromBytes(jsonData, []jsonxtractr.Selector{"xs.0", selector})
+	if !errors.Is(err, jsonxtractr.ErrJSONIndexOutOfRange) {
+		t.Errorf("ExtractValuesFromBytes() error = %v, want %v", err, jsonxtractr.ErrJSONIndexOutOfRange)
+	}
+	if !reflect.DeepEqual(values, jsonxtractr.ValuesMap{"xs.0": float64(10)}) {
+		t.Errorf("ExtractValuesFromBytes() values = %v, want map[xs.0:10]", values)
+	}
+	if !reflect.DeepEqual(notFound, []jsonxtractr.Selector{selector}) {
+		t.Errorf("ExtractValuesFromBytes() notFound = %v, want [%s]", notFound, selector)
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mikeschinkel/go-jsonxtractr"
+)
+
+// walkVisit records one Walk callback.
+type walkVisit struct {
+	path  jsonxtractr.Selector
+	kind  jsonxtractr.Kind
+	value any
+}
+
+func TestWalk(t *testing.T) {
+	jsonData := []byte(`{"name": "shop", "items": [{"id": 1, "tags": []}, null], "open": true, "a.b": {}}`)
+
+	var got []walkVisit
+	err := jsonxtractr.Walk(jsonData, func(path jsonxtractr.Selector, kind jsonxtractr.Kind, value any) error {
+		got = append(got, walkVisit{path: path, kind: kind, value: value})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	want := []walkVisit{
+		{path: jsonxtractr.RootSelector, kind: jsonxtractr.KindObject},
+		{path: "name", kind: jsonxtractr.KindString, value: "shop"},
+		{path: "items", kind: jsonxtractr.KindArray},
+		{path: "items.0", kind: jsonxtractr.KindObject},
+		{path: "items.0.id", kind: jsonxtractr.KindNumber, value: float64(1)},
+		{path: "items.0.tags", kind: jsonxtractr.KindArray},
+		{path: "items.1", kind: jsonxtractr.KindNull},
+		{path: "open", kind: jsonxtractr.KindBool, value: true},
+		{path: `"a.b"`, kind: jsonxtractr.KindObject},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() visited:\n  got:  %v\n  want: %v", got, want)
+	}
+}
+
+func TestWalk_SkipSubtree(t *testing.T) {
+	jsonData := []byte(`{"keep": {"a": 1}, "skip": {"b": [2, 3], "c": tru}, "after": [4]}`)
+
+	var got []jsonxtractr.Selector
+	err := jsonxtractr.Walk(jsonData, func(path jsonxtractr.Selector, kind jsonxtractr.Kind, value any) error {
+		got = append(got, path)
+		if path == "skip" || path == "after.0" {
+			// Skipping a scalar has no effect
+			return jsonxtractr.SkipSubtree
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Walk() error = nil, want an error for the malformed skipped value")
+	}
+
+	// Skipping still validates the skipped value
+	if !errors.Is(err, jsonxtractr.ErrJSONTokenReadFailed) {
+		t.Errorf("Walk() error = %v, want %v", err, jsonxtractr.ErrJSONTokenReadFailed)
+	}
+
+	got = nil
+	jsonData = []byte(`{"keep": {"a": 1}, "skip": {"b": [2, 3]}, "after": [4]}`)
+	err = jsonxtractr.Walk(jsonData, func(path jsonxtractr.Selector, kind jsonxtractr.Kind, value any) error {
+		got = append(got, path)
+		if path == "skip" || path == "after.0" {
+			return jsonxtractr.SkipSubtree
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	want := []jsonxtractr.Selector{jsonxtractr.RootSelector, "keep", "keep.a", "skip", "after", "after.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() visited %v, want %v", got, want)
+	}
+
+	// Skipping the root visits nothing else
+	got = nil
+	err = jsonxtractr.Walk(jsonData, func(path jsonxtractr.Selector, kind jsonxtractr.Kind, value any) error {
+		got = append(got, path)
+		return jsonxtractr.SkipSubtree
+	})
+	if err != nil || len(got) != 1 {
+		t.Errorf("Walk() visited %v with error %v, want only the root", got, err)
+	}
+}
+
+func TestWalk_StopsOnCallbackError(t *testing.T) {
+	errStop := fmt.Errorf("stop here")
+	jsonData := []byte(`[1, 2, 3, {"a": 4}]`)
+
+	var visited int
+	err := jsonxtractr.Walk(jsonData, func(path jsonxtractr.Selector, kind jsonxtractr.Kind, value any) error {
+		visited++
+		if path == "1" {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("Walk() error = %v, want the callback's error unchanged", err)
+	}
+	if visited != 3 {
+		t.Errorf("Walk() visited %d nodes, want 3", visited)
+	}
+}
+
+func TestWalk_Errors(t *testing.T) {
+	noop := func(jsonxtractr.Selector, jsonxtractr.Kind, any) error { return nil }
+
+	err := jsonxtractr.Walk(nil, noop)
+	if !errors.Is(err, jsonxtractr.ErrJSONBodyCannotBeEmpty) {
+		t.Errorf("Walk() error = %v, want %v", err, jsonxtractr.ErrJSONBodyCannotBeEmpty)
+	}
+
+	err = jsonxtractr.Walk([]byte(`{"a": [1, }`), noop)
+	if !errors.Is(err, jsonxtractr.ErrFailedToExtractValueFromJSON) {
+		t.Errorf("Walk() error = %v, want %v", err, jsonxtractr.ErrFailedToExtractValueFromJSON)
+	}
+}
@@ -0,0 +1,73 @@
+package jsonxtractr
+
+// stripJSONExtensions returns a copy of raw with line and block comments and
+// trailing commas before a closing '}' or ']' replaced by spaces, so strict
+// JSON decoding accepts it. Text inside strings is left alone. Comments and
+// commas are blanked rather than removed, and newlines within block comments
+// are kept, so offsets, lines and columns in errors still match the input.
+func stripJSONExtensions(raw []byte) []byte {
+	var inString bool
+	var lastComma int
+
+	stripped := make([]byte, len(raw))
+	copy(stripped, raw)
+
+	lastComma = -1
+	for pos := 0; pos < len(stripped); pos++ {
+		c := stripped[pos]
+		if inString {
+			switch c {
+			case '\\':
+				pos++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			lastComma = -1
+		case c == '/' && pos+1 < len(stripped) && stripped[pos+1] == '/':
+			pos = blankComment(stripped, pos, "\n") - 1
+		case c == '/' && pos+1 < len(stripped) && stripped[pos+1] == '*':
+			pos = blankComment(stripped, pos, "*/") - 1
+		case c == ',':
+			lastComma = pos
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				stripped[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			// Whitespace doesn't separate a trailing comma from its bracket
+		default:
+			lastComma = -1
+		}
+	}
+	return stripped
+}
+
+// blankComment replaces the comment starting at pos with spaces, up to and
+// including terminator unless it's a newline, and returns the offset just past
+// the comment. Newlines are kept. An unterminated comment runs to the end.
+func blankComment(data []byte, pos int, terminator string) (end int) {
+	end = len(data)
+	for i := pos + 2; i < len(data); i++ {
+		if string(data[i:min(i+len(terminator), len(data))]) != terminator {
+			continue
+		}
+		end = i
+		if terminator != "\n" {
+			end += len(terminator)
+		}
+		break
+	}
+	for i := pos; i < end; i++ {
+		if data[i] != '\n' {
+			data[i] = ' '
+		}
+	}
+	return end
+}
@@ -0,0 +1,155 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+)
+
+// Kind is the JSON type of a selected value.
+type Kind int
+
+const (
+	// KindInvalid is returned alongside an error
+	KindInvalid Kind = iota
+	KindObject
+	KindArray
+	KindString
+	KindNumber
+	KindBool
+	KindNull
+)
+
+// String returns the kind's name as used in JSON, e.g. "object" or "null".
+func (k Kind) String() (s string) {
+	switch k {
+	case KindObject:
+		s = "object"
+	case KindArray:
+		s = "array"
+	case KindString:
+		s = "string"
+	case KindNumber:
+		s = "number"
+	case KindBool:
+		s = "bool"
+	case KindNull:
+		s = "null"
+	default:
+		s = "invalid"
+	}
+	return s
+}
+
+// kindOf maps a jsontext kind to its Kind.
+func kindOf(kind jsontext.Kind) (k Kind) {
+	switch kind {
+	case '{':
+		k = KindObject
+	case '[':
+		k = KindArray
+	case '"':
+		k = KindString
+	case '0':
+		k = KindNumber
+	case 't', 'f':
+		k = KindBool
+	case 'n':
+		k = KindNull
+	}
+	return k
+}
+
+// TypeOf returns the JSON type of the value selector resolves to in JSON
+// bytes. The type is read from the value's first byte, so the value itself is
+// neither decoded nor validated. A missing path returns the same errors as
+// ExtractValueFromBytes.
+func TypeOf(jsonBytes []byte, selector Selector) (kind Kind, err error) {
+	var state *extractState
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	kind = kindOf(state.decoder.PeekKind())
+	if kind == KindInvalid {
+		// PeekKind hides the syntax error that reading the token reports
+		_, err = state.decoder.ReadToken()
+		err = state.enrichError(
+			ErrJSONStreamingParseFailed,
+			ErrJSONTokenReadFailed,
+			err,
+		)
+		goto end
+	}
+
+end:
+	if err != nil {
+		kind = KindInvalid
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return kind, err
+}
+
+// ExtractValueTyped is ExtractValueFromBytes for a value that must be of the
+// JSON type expect, e.g. KindNumber for a setting used as a number. A value of
+// any other type returns ErrJSONTypeMismatch, giving the two types as
+// "expected_type" and "actual_type", without being decoded.
+func ExtractValueTyped(jsonBytes []byte, selector Selector, expect Kind) (value any, err error) {
+	var state *extractState
+	var kind Kind
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	state, err = navigateSelector(bytes.NewReader(jsonBytes), selector, jsonBytes, options{})
+	if err != nil {
+		goto end
+	}
+
+	kind = kindOf(state.decoder.PeekKind())
+	if kind != KindInvalid && kind != expect {
+		err = state.enrichError(
+			ErrJSONTypeMismatch,
+			"expected_type", expect.String(),
+			"actual_type", kind.String(),
+			"actual_value_preview", state.valuePreview(state.unreadInput()),
+		)
+		goto end
+	}
+
+	// A value of no kind at all is malformed, which decoding reports
+	value, err = state.decodeValue()
+
+end:
+	if err != nil {
+		value = nil
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return value, err
+}
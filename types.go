@@ -1,5 +1,7 @@
 package jsonxtractr
 
+import "slices"
+
 type Selectors []Selector
 
 func (ss Selectors) Strings() (strings []string) {
@@ -10,6 +12,64 @@ func (ss Selectors) Strings() (strings []string) {
 	return strings
 }
 
+// Unique returns the selectors with duplicates removed, keeping the first
+// occurrence of each in order. The receiver is returned as-is when it has no
+// duplicates.
+func (ss Selectors) Unique() (unique Selectors) {
+	seen := make(map[Selector]struct{}, len(ss))
+	for i, s := range ss {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			continue
+		}
+		// Copy on the first duplicate so the common case doesn't allocate
+		unique = slices.Clone(ss[:i])
+		for _, s := range ss[i+1:] {
+			if _, ok := seen[s]; ok {
+				continue
+			}
+			seen[s] = struct{}{}
+			unique = append(unique, s)
+		}
+		goto end
+	}
+	unique = ss
+
+end:
+	return unique
+}
+
+// Validate checks every selector's syntax without any JSON input, as
+// Selector.Validate does, returning nil when all are valid. Otherwise each
+// invalid selector contributes an error giving its position in ss as
+// "selector_index" along with the selector, wrapping the error Selector.Validate
+// returns for it, and these are combined into one, so errors.Is finds the
+// sentinel of any of them.
+func (ss Selectors) Validate() error {
+	var errs []error
+	for i, s := range ss {
+		err := s.Validate()
+		if err == nil {
+			continue
+		}
+		errs = append(errs, NewErr(
+			ErrJSONSelectorInvalid,
+			"selector_index", i,
+			"selector", s,
+			err,
+		))
+	}
+	return CombineErrs(errs)
+}
+
+// Selector is a dot-separated path into a JSON document. The first segment
+// applies to the top-level value whatever its type: a numeric segment indexes
+// a top-level array, e.g. "1" selects 20 in [10,20,30], and any segment,
+// numeric or not, names a key of a top-level object. A segment that doesn't fit the value it
+// applies to, including any segment applied to a top-level string, number,
+// bool or null, fails with ErrJSONPathExpectedArrayAtSegment or
+// ErrJSONPathExpectedObjectAtSegment, whose "actual_type" is the value's
+// Kind name. Only RootSelector selects a top-level scalar.
 type Selector string
 
 func ToSelectors[S ~string](ss []S) (ids []Selector) {
@@ -2,21 +2,57 @@ package jsonxtractr
 
 import (
 	"bytes"
+	"context"
 	"encoding/json/jsontext"
 	jsonv2 "encoding/json/v2"
+	"errors"
 	"io"
+	"unsafe"
 )
 
 type ValuesMap map[Selector]any
 
+// MissingValue is the type of Missing.
+type MissingValue struct{}
+
+// Missing is the value WithMissingAsNil records in a ValuesMap for a selector
+// whose path doesn't exist, so it can be told apart from a JSON null, which
+// extracts as nil.
+var Missing MissingValue
+
 // ExtractValuesFromReader processes multiple selectors in a single pass through JSON.
-// Returns values for found selectors, list of selectors that were found, and any errors.
+// Returns values for found selectors, the selectors that were not found, and any errors.
 // Continues processing all selectors even when some fail to provide comprehensive error reporting.
+// When selectors fail, the error is a *MultiError giving the reason for each.
+// Only selectors whose paths are absent, as IsAbsent reports, are returned as
+// not found; one whose value is present but can't be extracted is reported
+// only by the error, and MultiError.Failed returns those alone.
+// Reading stops once every selector is resolved, so the rest of the stream is
+// left unread and unchecked.
 func ExtractValuesFromReader(reader io.Reader, selectors []Selector) (valuesMap ValuesMap, notFound []Selector, err error) {
-	var buffer bytes.Buffer
-	var teeReader io.Reader
-	var errs []error
-	var rawBytes []byte
+	return ExtractValuesFromReaderContext(context.Background(), reader, selectors)
+}
+
+// ExtractValuesFromReaderContext is ExtractValuesFromReader with cancellation.
+// If ctx is canceled while reading or decoding, it returns an error wrapping
+// both ErrJSONExtractionCanceled and ctx.Err(), discarding any partial results.
+func ExtractValuesFromReaderContext(ctx context.Context, reader io.Reader, selectors []Selector) (ValuesMap, []Selector, error) {
+	return extractValuesFromReader(ctx, reader, selectors, options{})
+}
+
+// ExtractValuesFromReaderOpts is ExtractValuesFromReader with options.
+func ExtractValuesFromReaderOpts(reader io.Reader, selectors []Selector, opts ...Option) (ValuesMap, []Selector, error) {
+	return extractValuesFromReader(context.Background(), reader, selectors, newOptions(opts))
+}
+
+// extractValuesFromReader implements ExtractValuesFromReaderContext with the
+// given options. A lone selector is decoded straight from reader, and several
+// are resolved in a single pass straight from reader where the selectors and
+// options allow, either of which stops reading once every value is found.
+// Otherwise the whole input is read first.
+func extractValuesFromReader(ctx context.Context, reader io.Reader, selectors []Selector, opts options) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var buffer *bytes.Buffer
+	var trie *selectorTrie
 
 	if reader == nil {
 		err = NewErr(
@@ -27,6 +63,10 @@ func ExtractValuesFromReader(reader io.Reader, selectors []Selector) (valuesMap
 		goto end
 	}
 
+	if closer, ok := reader.(io.Closer); ok && opts.closeReader {
+		defer closeReader(closer)
+	}
+
 	if len(selectors) == 0 {
 		err = NewErr(
 			ErrJSONPathTraversalFailed,
@@ -35,9 +75,37 @@ func ExtractValuesFromReader(reader io.Reader, selectors []Selector) (valuesMap
 		goto end
 	}
 
-	// Set up streaming with TeeReader to capture raw bytes
-	teeReader = io.TeeReader(reader, &buffer)
-	rawBytes, err = readAllBytes(teeReader)
+	// Each selector is resolved and reported once, however often it's passed
+	selectors = Selectors(selectors).Unique()
+	reader = opts.progressReader(opts.limitReader(reader))
+
+	// Comments, trailing commas and invalid UTF-8 are only dealt with once the
+	// input is read in full
+	if len(selectors) == 1 && !opts.readsWholeInput() {
+		valuesMap, notFound, err = streamSingleValue(ctx, reader, selectors[0], opts)
+		goto end
+	}
+	if len(selectors) > 1 && !opts.readsWholeInput() {
+		trie = newSelectorTrie(selectors, nil, opts)
+		if trie.streams() {
+			valuesMap, notFound, err = streamValues(ctx, reader, trie)
+			goto end
+		}
+	}
+
+	buffer, err = readAllBytesContext(ctx, reader)
+	if buffer != nil {
+		// Everything extracted is copied out of the buffer before this returns
+		defer putBuffer(buffer)
+	}
+	if ctx.Err() != nil {
+		err = NewErr(
+			ErrJSONExtractionCanceled,
+			"selectors", selectors,
+			ctx.Err(),
+		)
+		goto end
+	}
 	if err != nil {
 		err = NewErr(
 			ErrJSONStreamingParseFailed,
@@ -47,45 +115,89 @@ func ExtractValuesFromReader(reader io.Reader, selectors []Selector) (valuesMap
 		goto end
 	}
 
-	valuesMap = make(ValuesMap, len(selectors))
-	notFound = make([]Selector, 0, len(selectors))
+	valuesMap, notFound, err = extractValuesFromBytes(ctx, buffer.Bytes(), selectors, opts)
 
-	// Process each selector individually
-	for _, selector := range selectors {
-		var value any
-		var selectorErr error
+end:
+	return valuesMap, notFound, err
+}
 
-		// Create fresh reader for each selector
-		selectorReader := bytes.NewReader(rawBytes)
-		value, selectorErr = extractSingleValue(selectorReader, selector, rawBytes)
-		if selectorErr != nil {
-			errs = append(errs, selectorErr)
-			continue
-		}
+// extractValuesFromBytes resolves selectors against JSON already held in
+// memory, navigating a lone selector directly and several in a single pass.
+func extractValuesFromBytes(ctx context.Context, rawBytes []byte, selectors []Selector, opts options) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var failed []Selector
+	var errs []error
+	var singleErr error
+
+	if len(selectors) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONValueSelectorCannotBeEmpty,
+		)
+		goto end
+	}
 
-		valuesMap[selector] = value
+	// Each selector is resolved and reported once, however often it's passed
+	selectors = Selectors(selectors).Unique()
+
+	if opts.tolerant {
+		rawBytes = stripJSONExtensions(rawBytes)
 	}
 
-	// Join all collected errors
-	if len(errs) > 0 {
-		err = CombineErrs(errs)
+	err = opts.checkUTF8(rawBytes)
+	if err == nil {
+		err = opts.checkRemainder(rawBytes)
+	}
+	if err != nil {
+		goto end
 	}
 
-	// Not create the list of selectors not found.
-	for _, s := range selectors {
-		_, ok := valuesMap[s]
-		if ok {
-			continue
+	valuesMap = make(ValuesMap, len(selectors))
+
+	if len(selectors) == 1 {
+		// A lone selector is navigated directly without building a trie
+		var value any
+		value, singleErr = extractSingleValue(newContextReader(ctx, rawBytes), selectors[0], rawBytes, opts)
+		if singleErr != nil {
+			failed = append(failed, selectors[0])
+			errs = append(errs, singleErr)
+		} else {
+			valuesMap[selectors[0]] = value
 		}
-		notFound = append(notFound, s)
+	} else {
+		// Resolve every selector in a single pass through the JSON
+		values, found, selectorErrs := resolveSelectors(ctx, rawBytes, selectors, opts)
+		failed, errs = collectValues(valuesMap, selectors, values, found, selectorErrs)
 	}
 
+	if ctx.Err() != nil {
+		// Partial results are discarded once the context is canceled
+		valuesMap = nil
+		notFound = nil
+		err = NewErr(
+			ErrJSONExtractionCanceled,
+			"selectors", selectors,
+			ctx.Err(),
+		)
+		goto end
+	}
+
+	// Each selector that failed is reported along with why
+	notFound, err = opts.failuresError(valuesMap, failed, errs)
+
 end:
 	return valuesMap, notFound, err
 }
 
-// ExtractValuesFromBytes is a convenience wrapper for ExtractValuesFromReader
-func ExtractValuesFromBytes(jsonBytes []byte, selectors []Selector) (valuesMap ValuesMap, found []Selector, err error) {
+// ExtractValuesFromBytes is a convenience wrapper for ExtractValuesFromReader,
+// returning the values of the selectors found and the selectors not found.
+func ExtractValuesFromBytes(jsonBytes []byte, selectors []Selector) (valuesMap ValuesMap, notFound []Selector, err error) {
+	return ExtractValuesFromBytesOpts(jsonBytes, selectors)
+}
+
+// ExtractValuesFromBytesOpts is ExtractValuesFromBytes with options.
+func ExtractValuesFromBytesOpts(jsonBytes []byte, selectors []Selector, opts ...Option) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var o options
+
 	if len(jsonBytes) == 0 {
 		err = NewErr(
 			ErrJSONPathTraversalFailed,
@@ -95,25 +207,36 @@ func ExtractValuesFromBytes(jsonBytes []byte, selectors []Selector) (valuesMap V
 		goto end
 	}
 
-	valuesMap, found, err = ExtractValuesFromReader(bytes.NewReader(jsonBytes), selectors)
+	o = newOptions(opts)
+	err = o.checkInputSize(int64(len(jsonBytes)))
+	if err != nil {
+		goto end
+	}
+
+	valuesMap, notFound, err = extractValuesFromBytes(context.Background(), jsonBytes, selectors, o)
 
 end:
-	return valuesMap, found, err
+	return valuesMap, notFound, err
 }
 
 // ExtractValueFromReader extracts a single value from JSON - convenience wrapper
 func ExtractValueFromReader(reader io.Reader, selector Selector) (value any, err error) {
+	return ExtractValueFromReaderOpts(reader, selector)
+}
+
+// ExtractValueFromReaderOpts is ExtractValueFromReader with options.
+func ExtractValueFromReaderOpts(reader io.Reader, selector Selector, opts ...Option) (value any, err error) {
 	var valuesMap ValuesMap
 	var notFound []Selector
 	var ok bool
 
-	valuesMap, notFound, err = ExtractValuesFromReader(reader, []Selector{selector})
+	valuesMap, notFound, err = ExtractValuesFromReaderOpts(reader, []Selector{selector}, opts...)
 	if err != nil {
 		err = WithErr(
 			ErrFailedToExtractValueFromJSON,
 			ErrExtractingFromJSONByReader,
 			"selector", selector,
-			err,
+			soleFailure(err),
 		)
 		goto end
 	}
@@ -139,19 +262,63 @@ end:
 	return value, err
 }
 
+// ExtractValueFromReaderN is ExtractValueFromReader also returning the number
+// of bytes of input consumed. Once the value is extracted that is the offset
+// just past it, where a following document in a concatenated stream begins.
+// The decoder reads ahead, so the reader itself may have been read beyond
+// that; a reader that is an io.Seeker can be sought back to it. On failure it
+// is the number of bytes read from the reader.
+func ExtractValueFromReaderN(reader io.Reader, selector Selector) (value any, bytesRead int64, err error) {
+	var counter *countingReader
+
+	if reader == nil {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+		)
+		goto end
+	}
+
+	counter = &countingReader{reader: reader}
+	value, bytesRead, err = decodeStreamedValue(context.Background(), counter, selector, options{})
+	if err != nil {
+		bytesRead = counter.count
+	}
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONByReader,
+			"selector", selector,
+			err,
+		)
+	}
+	return value, bytesRead, err
+}
+
 // ExtractValueFromBytes extracts a single value from JSON bytes - convenience wrapper
+//
+// A key present with a JSON null value returns a nil value and a nil error,
+// while a missing key returns ErrJSONPathSegmentNotFound, so callers must
+// check err rather than the value to tell the two apart. See IsAbsent.
 func ExtractValueFromBytes(jsonBytes []byte, selector Selector) (value any, err error) {
+	return ExtractValueFromBytesOpts(jsonBytes, selector)
+}
+
+// ExtractValueFromBytesOpts is ExtractValueFromBytes with options.
+func ExtractValueFromBytesOpts(jsonBytes []byte, selector Selector, opts ...Option) (value any, err error) {
 	var valuesMap ValuesMap
 	var notFound []Selector
 	var ok bool
 
-	valuesMap, notFound, err = ExtractValuesFromBytes(jsonBytes, []Selector{selector})
+	valuesMap, notFound, err = ExtractValuesFromBytesOpts(jsonBytes, []Selector{selector}, opts...)
 	if err != nil {
 		err = WithErr(
 			ErrFailedToExtractValueFromJSON,
 			ErrExtractingFromJSONBytes,
 			"selector", selector,
-			err,
+			soleFailure(err),
 		)
 		goto end
 	}
@@ -177,11 +344,279 @@ end:
 	return value, err
 }
 
+// ExtractValueWithPath is ExtractValueFromBytes that also returns the concrete
+// path the selector resolved to, written as ExtractMatches writes paths. For
+// a plain selector such as "user.name" the path is the selector itself, but
+// a filter or negative index resolves to the index of the element it matched,
+// an ordinal to the key of the member at that position, and a key matched
+// case-insensitively to the key as the document writes it.
+func ExtractValueWithPath(jsonBytes []byte, selector Selector) (value any, resolvedPath Selector, err error) {
+	return ExtractValueWithPathOpts(jsonBytes, selector)
+}
+
+// ExtractValueWithPathOpts is ExtractValueWithPath with options.
+func ExtractValueWithPathOpts(jsonBytes []byte, selector Selector, opts ...Option) (value any, resolvedPath Selector, err error) {
+	var state *extractState
+	var o options
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+			"selector", selector,
+		)
+		goto end
+	}
+
+	o = newOptions(opts)
+	err = o.checkInputSize(int64(len(jsonBytes)))
+	if err != nil {
+		goto end
+	}
+
+	if o.tolerant {
+		jsonBytes = stripJSONExtensions(jsonBytes)
+	}
+
+	err = o.checkUTF8(jsonBytes)
+	if err == nil {
+		err = o.checkRemainder(jsonBytes)
+	}
+	if err != nil {
+		goto end
+	}
+
+	state, err = newSelectorState(bytes.NewReader(jsonBytes), selector, jsonBytes, o)
+	if err != nil {
+		goto end
+	}
+
+	state.resolved = make([]segment, 0, len(state.segments))
+	err = state.navigate()
+	if err != nil {
+		goto end
+	}
+
+	value, err = state.decodeValue()
+	if err != nil {
+		goto end
+	}
+	resolvedPath = formatSelector(state.resolved)
+
+end:
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			"selector", selector,
+			err,
+		)
+	}
+	return value, resolvedPath, err
+}
+
+// ExtractValuesFromString is ExtractValuesFromBytes for JSON held in a string,
+// with the same results and errors.
+func ExtractValuesFromString(jsonStr string, selectors []Selector) (ValuesMap, []Selector, error) {
+	return ExtractValuesFromBytes(stringBytes(jsonStr), selectors)
+}
+
+// ExtractValueFromString is ExtractValueFromBytes for JSON held in a string,
+// with the same results and errors.
+func ExtractValueFromString(jsonStr string, selector Selector) (any, error) {
+	return ExtractValueFromBytes(stringBytes(jsonStr), selector)
+}
+
+// stringBytes returns the bytes of s without copying them. The bytes must not
+// be modified, which holds for the bytes extraction functions since they only
+// ever read their input and never retain it past returning.
+func stringBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+// ExtractValueOr extracts a single value from JSON bytes, returning def when
+// the selector's path is absent from the document, as IsAbsent reports,
+// including a path through a value of the wrong type. Malformed JSON, invalid
+// selectors and other failures are still returned as errors.
+func ExtractValueOr(jsonBytes []byte, selector Selector, def any) (value any, err error) {
+	value, err = ExtractValueFromBytes(jsonBytes, selector)
+	if isNotFound(err) {
+		value = def
+		err = nil
+	}
+	return value, err
+}
+
 // extractSingleValue handles extraction of a single selector from JSON
-func extractSingleValue(reader io.Reader, selector Selector, rawBytes []byte) (value any, err error) {
-	var decoder *jsontext.Decoder
+func extractSingleValue(reader io.Reader, selector Selector, rawBytes []byte, opts options) (value any, err error) {
 	var state *extractState
 
+	state, err = navigateSelector(reader, selector, rawBytes, opts)
+	if err != nil {
+		goto end
+	}
+
+	value, err = state.decodeValue()
+
+end:
+	return value, err
+}
+
+// streamSingleValue extracts a lone selector directly from reader, returning as
+// soon as its value is decoded and abandoning the rest of the stream.
+func streamSingleValue(ctx context.Context, reader io.Reader, selector Selector, opts options) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var value any
+
+	// Neither cancellation nor a failing reader is the selector's doing, so
+	// neither reports it as not found
+	value, _, err = decodeStreamedValue(ctx, reader, selector, opts)
+	if errors.Is(err, ErrJSONExtractionCanceled) || errors.Is(err, ErrJSONReadFailed) {
+		goto end
+	}
+
+	valuesMap = make(ValuesMap, 1)
+	if err != nil {
+		notFound, err = opts.failuresError(valuesMap, []Selector{selector}, []error{err})
+		goto end
+	}
+	notFound = make([]Selector, 0)
+	valuesMap[selector] = value
+
+end:
+	return valuesMap, notFound, err
+}
+
+// decodeStreamedValue decodes the value selector selects directly from
+// reader, also returning the offset within the input just past it. Only the
+// input consumed so far is retained, for error context.
+func decodeStreamedValue(ctx context.Context, reader io.Reader, selector Selector, opts options) (value any, offset int64, err error) {
+	var state *extractState
+
+	stream := &streamReader{ctx: ctx, reader: reader}
+	consumed := getBuffer()
+	defer putBuffer(consumed)
+
+	state, err = newSelectorState(io.TeeReader(stream, consumed), selector, nil, opts)
+	if err == nil {
+		state.consumed = consumed
+		err = state.navigate()
+	}
+	if err == nil {
+		value, err = state.decodeValue()
+	}
+
+	if ctx.Err() != nil {
+		err = NewErr(
+			ErrJSONExtractionCanceled,
+			"selectors", []Selector{selector},
+			ctx.Err(),
+		)
+		goto end
+	}
+
+	// A failing reader surfaces as malformed JSON, so report it as a read error
+	if err != nil && stream.err != nil {
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONReadFailed,
+			stream.err,
+		)
+		goto end
+	}
+	if err == nil {
+		offset = state.inputOffset()
+	}
+
+end:
+	return value, offset, err
+}
+
+// closeReader closes a reader WithCloseReader hands over. Everything needed
+// has been read by then, so a failure to close it is of no consequence.
+func closeReader(closer io.Closer) {
+	_ = closer.Close()
+}
+
+// streamValues resolves the trie's selectors in a single pass straight from
+// reader, returning as soon as every one is resolved and abandoning the rest
+// of the stream. Only the input consumed so far is retained, for error
+// context.
+func streamValues(ctx context.Context, reader io.Reader, trie *selectorTrie) (valuesMap ValuesMap, notFound []Selector, err error) {
+	var failed []Selector
+	var errs []error
+
+	stream := &streamReader{ctx: ctx, reader: reader}
+	consumed := getBuffer()
+	defer putBuffer(consumed)
+
+	trie.consumed = consumed
+	trie.extract(io.TeeReader(stream, consumed))
+
+	if ctx.Err() != nil {
+		err = NewErr(
+			ErrJSONExtractionCanceled,
+			"selectors", trie.selectors,
+			ctx.Err(),
+		)
+		goto end
+	}
+
+	valuesMap = make(ValuesMap, len(trie.selectors))
+	failed, errs = collectValues(valuesMap, trie.selectors, trie.values, trie.found, trie.errs)
+
+	// A failing reader surfaces as malformed JSON, so report it as a read error
+	if len(failed) > 0 && stream.err != nil {
+		valuesMap = nil
+		err = NewErr(
+			ErrJSONStreamingParseFailed,
+			ErrJSONReadFailed,
+			stream.err,
+		)
+		goto end
+	}
+
+	// Each selector that failed is reported along with why
+	notFound, err = trie.opts.failuresError(valuesMap, failed, errs)
+
+end:
+	return valuesMap, notFound, err
+}
+
+// collectValues records in valuesMap the value of each of selectors that was
+// found, and returns those that failed along with why, given results indexed
+// like selectors.
+func collectValues(valuesMap ValuesMap, selectors []Selector, values []any, found []bool, selectorErrs []error) (failed []Selector, errs []error) {
+	failed = make([]Selector, 0, len(selectors))
+	for i, selector := range selectors {
+		if !found[i] {
+			failed = append(failed, selector)
+			errs = append(errs, selectorErrs[i])
+			continue
+		}
+		valuesMap[selector] = values[i]
+	}
+	return failed, errs
+}
+
+// navigateSelector navigates a single selector, returning a state whose decoder
+// is positioned at the selected value.
+func navigateSelector(reader io.Reader, selector Selector, rawBytes []byte, opts options) (state *extractState, err error) {
+	state, err = newSelectorState(reader, selector, rawBytes, opts)
+	if err != nil {
+		goto end
+	}
+
+	err = state.navigate()
+
+end:
+	return state, err
+}
+
+// newSelectorState parses selector and returns a state ready to navigate it
+// over the JSON read from reader.
+func newSelectorState(reader io.Reader, selector Selector, rawBytes []byte, opts options) (state *extractState, err error) {
+	var segments []segment
+
 	if len(selector) == 0 {
 		err = NewErr(
 			ErrJSONPathTraversalFailed,
@@ -190,44 +625,208 @@ func extractSingleValue(reader io.Reader, selector Selector, rawBytes []byte) (v
 		goto end
 	}
 
-	decoder = jsontext.NewDecoder(reader)
-	state = newExtractState(decoder, string(selector), rawBytes)
+	segments, err = opts.parseSelector(selector)
+	if err != nil {
+		goto end
+	}
+
+	state = newExtractState(opts.newNavigationDecoder(reader), string(selector), segments, rawBytes)
+	state.opts = opts
 
-	// Navigate through each path segment
-	for i, segment := range state.segments {
-		state.position = i
-		if segment == "" {
-			err = state.enrichError(
+	err = state.rejectWildcards()
+
+end:
+	return state, err
+}
+
+// navigate positions the decoder at the value selected by every segment.
+func (s *extractState) navigate() (err error) {
+	for i, seg := range s.segments {
+		s.position = i
+		if seg.isEmpty() {
+			err = s.enrichError(
 				ErrJSONPathTraversalFailed,
 				ErrJSONPathContainsEmptySegment,
 			)
 			goto end
 		}
 
-		err = state.navigateToSegment(segment)
+		err = s.navigateToSegment(seg)
+		if err == nil {
+			err = s.checkDepth()
+		}
 		if err != nil {
 			goto end
 		}
-		state.pathProgress = append(state.pathProgress, segment)
+		s.pathProgress = append(s.pathProgress, seg.text)
+		if s.resolved != nil {
+			s.resolved = append(s.resolved, s.resolvedSegment(seg))
+		}
 	}
 
-	// Extract the final value
-	err = jsonv2.UnmarshalDecode(decoder, &value)
+end:
+	return err
+}
+
+// decodeValue unmarshals the value the decoder is positioned at.
+func (s *extractState) decodeValue() (value any, err error) {
+	var raw jsontext.Value
+
+	if s.opts.maxDepth <= 0 {
+		err = s.decoder.UnmarshalInto(&value, s.opts.unmarshalOptions())
+		goto decoded
+	}
+
+	// The value's depth is checked before anything is built from it
+	raw, err = s.decoder.ReadValue()
+	if err == nil {
+		err = s.checkValueDepth(raw)
+		if err != nil {
+			goto end
+		}
+		err = jsonv2.Unmarshal(raw, &value, s.opts.unmarshalOptions())
+	}
+
+decoded:
 	if err != nil {
-		err = state.enrichError(
+		err = s.enrichError(
 			ErrJSONStreamingParseFailed,
 			ErrJSONUnmarshalFailed,
 			err,
 		)
 	}
-
 end:
 	return value, err
 }
 
-// readAllBytes reads all bytes from a reader
-func readAllBytes(reader io.Reader) ([]byte, error) {
-	var buffer bytes.Buffer
+// readAllBytes reads all bytes from a reader into a buffer from bufferPool,
+// which the caller must return with putBuffer once done with its contents.
+func readAllBytes(reader io.Reader) (*bytes.Buffer, error) {
+	buffer := getBuffer()
 	_, err := buffer.ReadFrom(reader)
-	return buffer.Bytes(), err
+	return buffer, err
+}
+
+// readAllBytesContext is readAllBytes, returning early with a nil buffer and
+// ctx.Err() if ctx is canceled first. A read that is blocked when ctx is
+// canceled is abandoned and left to finish in the background, and its buffer
+// is left to the garbage collector rather than returned to the pool.
+func readAllBytesContext(ctx context.Context, reader io.Reader) (buffer *bytes.Buffer, err error) {
+	type result struct {
+		buffer *bytes.Buffer
+		err    error
+	}
+	var done chan result
+
+	if ctx.Done() == nil {
+		// The context can never be canceled
+		buffer, err = readAllBytes(reader)
+		goto end
+	}
+
+	done = make(chan result, 1)
+	go func() {
+		buffer, err := readAllBytes(reader)
+		done <- result{buffer: buffer, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		buffer, err = r.buffer, r.err
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+end:
+	return buffer, err
+}
+
+// contextReader reads from in-memory JSON while failing reads once its context
+// is canceled, so a decoder walking it stops at its next buffer refill.
+type contextReader struct {
+	ctx    context.Context
+	reader bytes.Reader
+}
+
+func newContextReader(ctx context.Context, data []byte) *contextReader {
+	r := &contextReader{ctx: ctx}
+	r.reader.Reset(data)
+	return r
+}
+
+func (r *contextReader) Read(p []byte) (n int, err error) {
+	err = r.ctx.Err()
+	if err != nil {
+		goto end
+	}
+	n, err = r.reader.Read(p)
+end:
+	return n, err
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// streamReader reads a caller's stream on behalf of a streaming decode. A read
+// that is blocked when ctx is canceled is abandoned and left to finish in the
+// background, and the first read failure is remembered so it can be reported
+// as such rather than as malformed JSON.
+type streamReader struct {
+	ctx    context.Context
+	reader io.Reader
+	buffer []byte
+	err    error
+}
+
+func (r *streamReader) Read(p []byte) (n int, err error) {
+	type result struct {
+		n   int
+		err error
+	}
+	var done chan result
+
+	if r.ctx.Done() == nil {
+		// The context can never be canceled
+		n, err = r.reader.Read(p)
+		goto end
+	}
+
+	err = r.ctx.Err()
+	if err != nil {
+		goto end
+	}
+
+	// Read into a buffer of our own since an abandoned read must not write
+	// into p after Read has returned
+	if len(r.buffer) < len(p) {
+		r.buffer = make([]byte, len(p))
+	}
+	done = make(chan result, 1)
+	go func(buffer []byte) {
+		n, err := r.reader.Read(buffer)
+		done <- result{n: n, err: err}
+	}(r.buffer[:len(p)])
+
+	select {
+	case res := <-done:
+		n = copy(p, r.buffer[:res.n])
+		err = res.err
+	case <-r.ctx.Done():
+		err = r.ctx.Err()
+	}
+
+end:
+	if err != nil && !errors.Is(err, io.EOF) && r.err == nil && r.ctx.Err() == nil {
+		r.err = err
+	}
+	return n, err
 }
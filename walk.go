@@ -0,0 +1,162 @@
+package jsonxtractr
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"errors"
+	"strconv"
+)
+
+// SkipSubtree is returned by a Walk callback to skip the members of the object
+// or array it was just called for. Returned for a scalar it is ignored. Walk
+// itself never returns SkipSubtree.
+var SkipSubtree = errors.New("skip this subtree")
+
+// Walk streams through JSON bytes in document order, invoking fn for the root
+// and then for every object member and array element beneath it, each before
+// its own members. Scalars are passed decoded; objects and arrays are passed
+// with a nil value since their members follow.
+//
+// If fn returns SkipSubtree for an object or array, its members are skipped
+// without being decoded. Any other error stops the walk and Walk returns that
+// error unchanged. Paths are reported as selectors, as in Flatten.
+func Walk(jsonBytes []byte, fn func(path Selector, kind Kind, value any) error) (err error) {
+	var state *extractState
+	var walk *nodeWalk
+
+	if len(jsonBytes) == 0 {
+		err = NewErr(
+			ErrJSONPathTraversalFailed,
+			ErrJSONBodyCannotBeEmpty,
+		)
+		goto end
+	}
+
+	state = newExtractState(options{}.newNavigationDecoder(bytes.NewReader(jsonBytes)), string(RootSelector), nil, jsonBytes)
+	walk = &nodeWalk{fn: fn}
+	err = state.walkNode(walk, nil)
+	if walk.stopErr != nil {
+		err = walk.stopErr
+		goto end
+	}
+	if err != nil {
+		err = WithErr(
+			ErrFailedToExtractValueFromJSON,
+			ErrExtractingFromJSONBytes,
+			err,
+		)
+	}
+
+end:
+	return err
+}
+
+// nodeWalk carries the callback shared by every level of a Walk and remembers
+// when the callback itself asked the walk to stop.
+type nodeWalk struct {
+	fn      func(path Selector, kind Kind, value any) error
+	stopErr error
+}
+
+// walkNode invokes the walk's callback for the value the decoder is positioned
+// at, which path addresses, and then descends into its members unless told to
+// skip them.
+func (s *extractState) walkNode(walk *nodeWalk, path []segment) (err error) {
+	var keyToken jsontext.Token
+	var value any
+	var closing jsontext.Kind
+	var idx int
+
+	kind := s.decoder.PeekKind()
+	switch kind {
+	case '{':
+		closing = '}'
+	case '[':
+		closing = ']'
+	default:
+		err = s.decoder.UnmarshalInto(&value, s.opts.unmarshalOptions())
+		if err != nil {
+			err = s.enrichError(
+				ErrJSONStreamingParseFailed,
+				ErrJSONUnmarshalFailed,
+				"node", formatSelector(path),
+				err,
+			)
+			goto end
+		}
+		err = walk.fn(formatSelector(path), kindOf(kind), value)
+		if err != nil && !errors.Is(err, SkipSubtree) {
+			walk.stopErr = err
+			goto end
+		}
+		err = nil
+		goto end
+	}
+
+	err = walk.fn(formatSelector(path), kindOf(kind), nil)
+	if errors.Is(err, SkipSubtree) {
+		err = s.decoder.SkipValue()
+		if err != nil {
+			err = s.enrichError(
+				ErrJSONPathTraversalFailed,
+				ErrJSONTokenReadFailed,
+				"skipping", formatSelector(path),
+				err,
+			)
+		}
+		goto end
+	}
+	if err != nil {
+		walk.stopErr = err
+		goto end
+	}
+
+	// Read container start token
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "container_start",
+			err,
+		)
+		goto end
+	}
+
+	for s.decoder.PeekKind() != closing {
+		member := segment{kind: nameSegment, text: strconv.Itoa(idx)}
+		if kind == '{' {
+			keyToken, err = s.decoder.ReadToken()
+			if err != nil {
+				err = s.enrichError(
+					ErrJSONPathTraversalFailed,
+					ErrJSONTokenReadFailed,
+					"reading", "object_key",
+					err,
+				)
+				goto end
+			}
+			member = segment{kind: keySegment, text: keyToken.String()}
+		}
+
+		err = s.walkNode(walk, append(path[:len(path):len(path)], member))
+		if err != nil || walk.stopErr != nil {
+			goto end
+		}
+		idx++
+	}
+
+	// Read container end token
+	_, err = s.decoder.ReadToken()
+	if err != nil {
+		err = s.enrichError(
+			ErrJSONPathTraversalFailed,
+			ErrJSONTokenReadFailed,
+			"expected_token", "container_end",
+			err,
+		)
+	}
+
+end:
+	return err
+}